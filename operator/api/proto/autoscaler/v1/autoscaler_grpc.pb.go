@@ -0,0 +1,114 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.5.1-go
+// source: autoscaler/v1/autoscaler.proto
+
+// The gRPC version of the autoscaler webhook protocol. It carries the same data as the
+// version 2 JSON request and response, so a policy can switch transports without changes.
+
+package autoscalerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Autoscaler_Scale_FullMethodName = "/autoscaler.v1.Autoscaler/Scale"
+)
+
+// AutoscalerClient is the client API for Autoscaler service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AutoscalerClient interface {
+	// Scale is called on every reconcile of the autoscaler and returns the replicas the target should have
+	Scale(ctx context.Context, in *AutoscaleRequest, opts ...grpc.CallOption) (*AutoscaleResponse, error)
+}
+
+type autoscalerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAutoscalerClient(cc grpc.ClientConnInterface) AutoscalerClient {
+	return &autoscalerClient{cc}
+}
+
+func (c *autoscalerClient) Scale(ctx context.Context, in *AutoscaleRequest, opts ...grpc.CallOption) (*AutoscaleResponse, error) {
+	out := new(AutoscaleResponse)
+	err := c.cc.Invoke(ctx, Autoscaler_Scale_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AutoscalerServer is the server API for Autoscaler service.
+// All implementations must embed UnimplementedAutoscalerServer
+// for forward compatibility
+type AutoscalerServer interface {
+	// Scale is called on every reconcile of the autoscaler and returns the replicas the target should have
+	Scale(context.Context, *AutoscaleRequest) (*AutoscaleResponse, error)
+	mustEmbedUnimplementedAutoscalerServer()
+}
+
+// UnimplementedAutoscalerServer must be embedded to have forward compatible implementations.
+type UnimplementedAutoscalerServer struct {
+}
+
+func (UnimplementedAutoscalerServer) Scale(context.Context, *AutoscaleRequest) (*AutoscaleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Scale not implemented")
+}
+func (UnimplementedAutoscalerServer) mustEmbedUnimplementedAutoscalerServer() {}
+
+// UnsafeAutoscalerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AutoscalerServer will
+// result in compilation errors.
+type UnsafeAutoscalerServer interface {
+	mustEmbedUnimplementedAutoscalerServer()
+}
+
+func RegisterAutoscalerServer(s grpc.ServiceRegistrar, srv AutoscalerServer) {
+	s.RegisterService(&Autoscaler_ServiceDesc, srv)
+}
+
+func _Autoscaler_Scale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AutoscaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutoscalerServer).Scale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Autoscaler_Scale_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutoscalerServer).Scale(ctx, req.(*AutoscaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Autoscaler_ServiceDesc is the grpc.ServiceDesc for Autoscaler service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Autoscaler_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "autoscaler.v1.Autoscaler",
+	HandlerType: (*AutoscalerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Scale",
+			Handler:    _Autoscaler_Scale_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "autoscaler/v1/autoscaler.proto",
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CapacityPoolSpec defines the desired state of CapacityPool
+// +kubebuilder:validation:XValidation:rule="has(self.maxServers) || has(self.maxResources)",message="maxServers or maxResources is required"
+type CapacityPoolSpec struct {
+	// Selects the GameTypes sharing the pool, from every namespace
+	GameTypeSelector metav1.LabelSelector `json:"gameTypeSelector"`
+	// The maximum number of servers of the GameTypes
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxServers *int32 `json:"maxServers,omitempty"`
+	// The maximum cpu and memory requested by the servers of the GameTypes
+	// +kubebuilder:validation:Optional
+	MaxResources corev1.ResourceList `json:"maxResources,omitempty"`
+}
+
+// GameTypeCapacity defines how a GameType shares the capacity pools selecting it
+type GameTypeCapacity struct {
+	// GameTypes with a higher priority get the free capacity of the pool first
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=0
+	Priority int32 `json:"priority,omitempty"`
+	// The replicas held back for the GameType, even when GameTypes with a higher priority want the capacity
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	GuaranteedReplicas int32 `json:"guaranteedReplicas,omitempty"`
+}
+
+// CapacityAllocation is the share of the pool granted to a GameType
+type CapacityAllocation struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// The priority of the GameType in the pool
+	Priority int32 `json:"priority,omitempty"`
+	// The replicas held back for the GameType
+	GuaranteedReplicas int32 `json:"guaranteedReplicas,omitempty"`
+	// The servers the GameType has, including the servers of replaced fleets
+	Servers int32 `json:"servers"`
+	// The servers the GameType wants to have
+	DesiredReplicas int32 `json:"desiredReplicas"`
+	// The servers the GameType is allowed to have
+	GrantedReplicas int32 `json:"grantedReplicas"`
+}
+
+// CapacityPoolStatus defines the observed state of CapacityPool
+type CapacityPoolStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// The servers of the GameTypes in the pool
+	Servers int32 `json:"servers,omitempty"`
+	// The cpu and memory requested by the servers of the GameTypes
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+	// The share of the pool granted to every GameType
+	GameTypes []CapacityAllocation `json:"gameTypes,omitempty"`
+	// The last time the pool was arbitrated
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+const (
+	// CapacityPoolLimited is true when a GameType was granted fewer replicas than it wants
+	CapacityPoolLimited = "Limited"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Max Servers",type=integer,JSONPath=`.spec.maxServers`
+// +kubebuilder:printcolumn:name="Servers",type=integer,JSONPath=`.status.servers`
+// +kubebuilder:printcolumn:name="Limited",type=string,JSONPath=`.status.conditions[?(@.type=="Limited")].status`
+
+// CapacityPool is the Schema for the capacitypools API
+type CapacityPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CapacityPoolSpec   `json:"spec,omitempty"`
+	Status CapacityPoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CapacityPoolList contains a list of CapacityPool
+type CapacityPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CapacityPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CapacityPool{}, &CapacityPoolList{})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var capacitypoollog = logf.Log.WithName("capacitypool-resource")
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks
+func (r *CapacityPool) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
+// Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
+// +kubebuilder:webhook:path=/validate-network-unfamousthomas-me-v1alpha1-capacitypool,mutating=false,failurePolicy=fail,sideEffects=None,groups=network.unfamousthomas.me,resources=capacitypools,verbs=create;update,versions=v1alpha1,name=vcapacitypool.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &CapacityPool{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *CapacityPool) ValidateCreate() (admission.Warnings, error) {
+	return nil, r.validatePool()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *CapacityPool) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	return nil, r.validatePool()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *CapacityPool) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validatePool checks that the pool has a valid selector and limits only the cpu and memory
+func (r *CapacityPool) validatePool() error {
+	if _, err := metav1.LabelSelectorAsSelector(&r.Spec.GameTypeSelector); err != nil {
+		return fmt.Errorf("invalid gameTypeSelector: %w", err)
+	}
+	if r.Spec.MaxServers == nil && len(r.Spec.MaxResources) == 0 {
+		return errors.New("maxServers or maxResources is required")
+	}
+	if r.Spec.MaxServers != nil && *r.Spec.MaxServers < 0 {
+		return errors.New("maxServers cannot be negative")
+	}
+	for resource, quantity := range r.Spec.MaxResources {
+		if resource != corev1.ResourceCPU && resource != corev1.ResourceMemory {
+			return fmt.Errorf("maxResources can only limit cpu and memory, not %s", resource)
+		}
+		if quantity.Sign() < 0 {
+			return fmt.Errorf("maxResources %s cannot be negative", resource)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("CapacityPool Webhook", func() {
+
+	Context("When creating CapacityPool under Validating Webhook", func() {
+		It("Should validate the limits and the selector", func() {
+			maxServers := int32(10)
+			pool := CapacityPool{
+				Spec: CapacityPoolSpec{
+					GameTypeSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pool": "shared"}},
+				},
+			}
+			By("Requiring a limit")
+			_, err := pool.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Accepting the server and resource limits")
+			pool.Spec.MaxServers = &maxServers
+			pool.Spec.MaxResources = corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("40"),
+				corev1.ResourceMemory: resource.MustParse("80Gi"),
+			}
+			_, err = pool.ValidateCreate()
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Rejecting other resources")
+			pool.Spec.MaxResources[corev1.ResourceEphemeralStorage] = resource.MustParse("10Gi")
+			_, err = pool.ValidateUpdate(&pool)
+			Expect(err).To(HaveOccurred())
+			delete(pool.Spec.MaxResources, corev1.ResourceEphemeralStorage)
+
+			By("Rejecting an invalid selector")
+			pool.Spec.GameTypeSelector.MatchExpressions = []metav1.LabelSelectorRequirement{
+				{Key: "pool", Operator: "Unknown"},
+			}
+			_, err = pool.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
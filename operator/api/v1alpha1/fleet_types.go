@@ -61,6 +61,11 @@ type FleetStatus struct {
 	CurrentReplicas int32              `json:"current_replicas,omitempty"`
 }
 
+const (
+	// FleetCapacityLimited is true when a capacity pool keeps the fleet from creating all of its servers
+	FleetCapacityLimited = "CapacityLimited"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Desired Replicas",type=integer,JSONPath=`.spec.scaling.replicas`
@@ -88,6 +93,16 @@ func init() {
 	SchemeBuilder.Register(&Fleet{}, &FleetList{})
 }
 
+// GetReplicas returns the replica count of the fleet
+func (r *Fleet) GetReplicas() int32 {
+	return r.Spec.Scaling.Replicas
+}
+
+// SetReplicas sets the replica count of the fleet
+func (r *Fleet) SetReplicas(replicas int32) {
+	r.Spec.Scaling.Replicas = replicas
+}
+
 func AreFleetsPodsEqual(fleet1, fleet2 *FleetSpec) bool {
 	return reflect.DeepEqual(fleet1.ServerSpec.Pod, fleet2.ServerSpec.Pod)
 }
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -73,7 +74,7 @@ func (r *Fleet) ValidateCreate() (admission.Warnings, error) {
 		return nil, errors.New("timeout is required for every server")
 	}
 
-	return nil, nil
+	return shutdownDeliveryWarnings(r.Spec.ServerSpec), nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
@@ -87,13 +88,19 @@ func (r *Fleet) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	if !ok {
 		return nil, fmt.Errorf("expected old object to be *Fleet, got %T", old)
 	}
-	warnings := admission.Warnings{}
+	warnings := shutdownDeliveryWarnings(r.Spec.ServerSpec)
 	if oldFleet.Spec.ServerSpec.TimeOut != r.Spec.ServerSpec.TimeOut {
 		warnings = append(warnings, "New timeout will not affect previously created servers")
 	}
 	if oldFleet.Spec.ServerSpec.AllowForceDelete != r.Spec.ServerSpec.AllowForceDelete {
 		warnings = append(warnings, "New allowForceDelete will not affect previously created servers")
 	}
+	if !reflect.DeepEqual(oldFleet.Spec.ServerSpec.ShutdownDelivery, r.Spec.ServerSpec.ShutdownDelivery) {
+		warnings = append(warnings, "New shutdownDelivery will not affect previously created servers")
+	}
+	if oldFleet.Spec.ServerSpec.Lifecycle != r.Spec.ServerSpec.Lifecycle {
+		warnings = append(warnings, "New lifecycle will not affect previously created servers")
+	}
 	if !arePodSpecsEqual(oldFleet.Spec.ServerSpec.Pod, r.Spec.ServerSpec.Pod) {
 		return nil, fmt.Errorf("pod template cannot be updated")
 	}
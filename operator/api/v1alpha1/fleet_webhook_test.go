@@ -181,6 +181,12 @@ var _ = Describe("Fleet Webhook", func() {
 			Expect(warn).To(Not(BeNil()))
 			Expect(len(warn)).To(Equal(2))
 
+			By("Warns when the shutdown delivery differs")
+			newFleet.Spec.ServerSpec.ShutdownDelivery = &ShutdownDelivery{AllowDeleteOnExit: true, Process: "game"}
+			warn, err = newFleet.ValidateUpdate(initialFleet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warn).To(ContainElement("New shutdownDelivery will not affect previously created servers"))
+
 			By("Fails when invalid priority")
 			newFleet.Spec.Scaling.AgePriority = "randompriority"
 			_, err = newFleet.ValidateUpdate(initialFleet)
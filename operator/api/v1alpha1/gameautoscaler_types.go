@@ -17,6 +17,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -24,64 +28,536 @@ type PolicyStrategy string
 type SyncStrategy string
 
 var validPolicyStrategies = map[PolicyStrategy]struct{}{
-	Webhook: {},
+	Webhook:    {},
+	Metrics:    {},
+	Schedule:   {},
+	Buffer:     {},
+	Composite:  {},
+	Predictive: {},
+	Push:       {},
 	// Add new strategies here as needed
 }
 var validSyncStrategy = map[SyncStrategy]struct{}{
 	FixedInterval: {},
+	Event:         {},
 	// Add new strategies here as needed
 }
 
 var (
-	Webhook PolicyStrategy = "webhook"
+	Webhook    PolicyStrategy = "webhook"
+	Metrics    PolicyStrategy = "metrics"
+	Schedule   PolicyStrategy = "schedule"
+	Buffer     PolicyStrategy = "buffer"
+	Composite  PolicyStrategy = "composite"
+	Predictive PolicyStrategy = "predictive"
+	Push       PolicyStrategy = "push"
 
 	FixedInterval SyncStrategy = "fixedinterval"
+	Event         SyncStrategy = "event"
 )
 
 // GameAutoscalerSpec defines the desired state of GameAutoscaler
 type GameAutoscalerSpec struct {
-	GameName        string          `json:"gameName"`
+	// Deprecated: use targetRef instead. Name of the GameType to scale, converted to a targetRef by the webhook.
+	// +kubebuilder:validation:Optional
+	GameName string `json:"gameName,omitempty"`
+	// The GameType or Fleet to scale, in the namespace of the autoscaler
+	// +kubebuilder:validation:Optional
+	TargetRef       *TargetRef      `json:"targetRef,omitempty"`
 	AutoscalePolicy AutoscalePolicy `json:"policy"`
 	Sync            Sync            `json:"sync"`
+	// Lower limit for the replica count the policy can scale to
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// Upper limit for the replica count the policy can scale to
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// Limits how fast the replica count can change, so a flapping policy does not cause the game to flap
+	// +kubebuilder:validation:Optional
+	Behavior *ScalingBehavior `json:"behavior,omitempty"`
+	// What to do when the webhook or metrics query keeps failing. Without it, the replicas are held and retried every minute.
+	// +kubebuilder:validation:Optional
+	FailurePolicy *FailurePolicy `json:"failurePolicy,omitempty"`
+	// Evaluate the policy and record the replicas it would scale to, without updating the GameType
+	// +kubebuilder:validation:Optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+type TargetKind string
+
+var (
+	GameTypeTarget TargetKind = "GameType"
+	FleetTarget    TargetKind = "Fleet"
+)
+
+// TargetRef points to the resource scaled by the autoscaler
+type TargetRef struct {
+	// +kubebuilder:validation:Enum=GameType;Fleet
+	// +kubebuilder:default=GameType
+	Kind TargetKind `json:"kind"`
+	Name string     `json:"name"`
+}
+
+// Target returns the target of the autoscaler, falling back to the GameType in gameName for autoscalers that have not been converted
+func (s GameAutoscalerSpec) Target() TargetRef {
+	if s.TargetRef != nil {
+		return *s.TargetRef
+	}
+	return TargetRef{Kind: GameTypeTarget, Name: s.GameName}
+}
+
+type FailurePolicyType string
+
+var (
+	HoldFailurePolicy     FailurePolicyType = "hold"
+	FallbackFailurePolicy FailurePolicyType = "fallback"
+)
+
+// FailurePolicy configures retrying failed evaluations, and optionally falling back to known replicas
+type FailurePolicy struct {
+	// Hold keeps the current replicas, fallback scales to the fallback replicas once the threshold is reached
+	// +kubebuilder:validation:Enum=hold;fallback
+	// +kubebuilder:default=hold
+	Type FailurePolicyType `json:"type,omitempty"`
+	// Failures in a row before the autoscaler is degraded
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+	// Replicas to fall back to
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	FallbackReplicas *int32 `json:"fallbackReplicas,omitempty"`
+	// Schedule to fall back to, takes priority over the fallback replicas.
+	// Outside the windows the fallback replicas are used if the schedule has no default replicas.
+	// +kubebuilder:validation:Optional
+	FallbackSchedule *ScheduleAutoscalerSpec `json:"fallbackSchedule,omitempty"`
+	// Delay before the first retry, doubled on every failure. Defaults to 5s.
+	// +kubebuilder:validation:Optional
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+	// Longest delay between retries. Defaults to 5m.
+	// +kubebuilder:validation:Optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+}
+
+// ScalingBehavior configures scaling up and down separately, similar to the HorizontalPodAutoscaler
+type ScalingBehavior struct {
+	// +kubebuilder:validation:Optional
+	ScaleUp *ScalingRules `json:"scaleUp,omitempty"`
+	// +kubebuilder:validation:Optional
+	ScaleDown *ScalingRules `json:"scaleDown,omitempty"`
+}
+
+type ScalingPolicySelect string
+type ScalingPolicyType string
+
+var (
+	MaxChangePolicySelect ScalingPolicySelect = "Max"
+	MinChangePolicySelect ScalingPolicySelect = "Min"
+	DisabledPolicySelect  ScalingPolicySelect = "Disabled"
+
+	ReplicasScalingPolicy ScalingPolicyType = "Replicas"
+	PercentScalingPolicy  ScalingPolicyType = "Percent"
+)
+
+type ScalingRules struct {
+	// The recommendations within the window are considered. Scaling up uses the lowest of them and scaling down the highest.
+	// +kubebuilder:validation:Optional
+	StabilizationWindow *metav1.Duration `json:"stabilizationWindow,omitempty"`
+	// Which policy to use when there are multiple. Max allows the largest change, Min the smallest and Disabled turns off scaling in this direction.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Max;Min;Disabled
+	// +kubebuilder:default=Max
+	SelectPolicy ScalingPolicySelect `json:"selectPolicy,omitempty"`
+	// Limits for how much the replica count can change within a period
+	// +kubebuilder:validation:Optional
+	Policies []ScalingPolicy `json:"policies,omitempty"`
+	// Time after the last scale in this direction before scaling in this direction again
+	// +kubebuilder:validation:Optional
+	Cooldown *metav1.Duration `json:"cooldown,omitempty"`
+}
+
+type ScalingPolicy struct {
+	// +kubebuilder:validation:Enum=Replicas;Percent
+	Type ScalingPolicyType `json:"type"`
+	// Replicas or percent of the replicas at the start of the period
+	// +kubebuilder:validation:Minimum=1
+	Value  int32           `json:"value"`
+	Period metav1.Duration `json:"period"`
 }
 
 //The following structs handle the policy of how to sync
 
+// +kubebuilder:validation:XValidation:rule="self.type != 'webhook' || (has(self.webhook) && (has(self.webhook.path) || (has(self.webhook.transport) && self.webhook.transport == 'grpc')))",message="webhook policy requires a path"
 type AutoscalePolicy struct {
-	// +kubebuilder:validation:Enum=webhook
-	Type                  PolicyStrategy        `json:"type"`
+	// +kubebuilder:validation:Enum=webhook;metrics;schedule;buffer;composite;predictive;push
+	Type PolicyStrategy `json:"type"`
+	// +kubebuilder:validation:Optional
 	WebhookAutoscalerSpec WebhookAutoscalerSpec `json:"webhook"`
+	// +kubebuilder:validation:Optional
+	MetricsAutoscalerSpec *MetricsAutoscalerSpec `json:"metrics,omitempty"`
+	// With the schedule type, the schedule alone decides the replica count.
+	// With any other type, the active windows act as a floor for the result of that policy.
+	// +kubebuilder:validation:Optional
+	ScheduleAutoscalerSpec *ScheduleAutoscalerSpec `json:"schedule,omitempty"`
+	// +kubebuilder:validation:Optional
+	BufferAutoscalerSpec *BufferAutoscalerSpec `json:"buffer,omitempty"`
+	// +kubebuilder:validation:Optional
+	CompositeAutoscalerSpec *CompositeAutoscalerSpec `json:"composite,omitempty"`
+	// +kubebuilder:validation:Optional
+	PredictiveAutoscalerSpec *PredictiveAutoscalerSpec `json:"predictive,omitempty"`
+	// +kubebuilder:validation:Optional
+	PushAutoscalerSpec *PushAutoscalerSpec `json:"push,omitempty"`
+}
+
+// PushAutoscalerSpec scales to the replica count pushed by external systems in the PushAnnotation,
+// and evaluates the base policy while nothing is pushed
+type PushAutoscalerSpec struct {
+	// Policy used while no push is active. Without it, the replicas are kept as they are.
+	// +kubebuilder:validation:Optional
+	Base *CompositePolicy `json:"base,omitempty"`
+	// Longest a push is applied for, a longer TTL is cut off
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="1h"
+	MaxTTL *metav1.Duration `json:"maxTTL,omitempty"`
+}
+
+// PushAnnotation holds the PushedReplicas as JSON, it is written by the service API
+const PushAnnotation = "gameautoscalers.unfamousthomas.me/push"
+
+// WebhookCredentialsLabel has to be set to "true" on a Secret before it can be used in a WebhookSecretRef.
+// Otherwise, anyone able to create a GameAutoscaler could have the operator send any Secret of the namespace to a webhook of their choice.
+const WebhookCredentialsLabel = "gameautoscalers.unfamousthomas.me/webhook-credentials"
+
+// PushedReplicas is a replica count pushed by an external system
+type PushedReplicas struct {
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+	// Why the replicas were pushed, shown in the events and status
+	// +kubebuilder:validation:Optional
+	Reason string `json:"reason,omitempty"`
+	// When the replicas were pushed
+	Time metav1.Time `json:"time"`
+	// When the push stops being applied
+	ExpirationTime metav1.Time `json:"expirationTime"`
+}
+
+// PushedReplicas returns the replicas pushed in the PushAnnotation, or nil if none were pushed
+func (r *GameAutoscaler) PushedReplicas() (*PushedReplicas, error) {
+	value, exists := r.Annotations[PushAnnotation]
+	if !exists || value == "" {
+		return nil, nil
+	}
+	pushed := &PushedReplicas{}
+	if err := json.Unmarshal([]byte(value), pushed); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", PushAnnotation, err)
+	}
+	return pushed, nil
+}
+
+type CompositeCombinator string
+
+var (
+	MaxCompositeCombinator             CompositeCombinator = "max"
+	MinCompositeCombinator             CompositeCombinator = "min"
+	FirstSuccessfulCompositeCombinator CompositeCombinator = "firstsuccessful"
+)
+
+// CompositeAutoscalerSpec evaluates several policies and combines their recommendations
+type CompositeAutoscalerSpec struct {
+	// Max and min use the highest or lowest recommendation, and fail if any of the policies fails.
+	// Firstsuccessful evaluates the policies in order and uses the first one that does not fail.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=max;min;firstsuccessful
+	// +kubebuilder:default=max
+	Combinator CompositeCombinator `json:"combinator,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Policies []CompositePolicy `json:"policies"`
+}
+
+// CompositePolicy is a single policy of a composite policy, it cannot be a composite policy itself
+// +kubebuilder:validation:XValidation:rule="self.type != 'webhook' || (has(self.webhook) && (has(self.webhook.path) || (has(self.webhook.transport) && self.webhook.transport == 'grpc')))",message="webhook policy requires a path"
+type CompositePolicy struct {
+	// Name of the policy, used in the status
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum=webhook;metrics;schedule;buffer;predictive
+	Type PolicyStrategy `json:"type"`
+	// +kubebuilder:validation:Optional
+	WebhookAutoscalerSpec *WebhookAutoscalerSpec `json:"webhook,omitempty"`
+	// +kubebuilder:validation:Optional
+	MetricsAutoscalerSpec *MetricsAutoscalerSpec `json:"metrics,omitempty"`
+	// +kubebuilder:validation:Optional
+	ScheduleAutoscalerSpec *ScheduleAutoscalerSpec `json:"schedule,omitempty"`
+	// +kubebuilder:validation:Optional
+	BufferAutoscalerSpec *BufferAutoscalerSpec `json:"buffer,omitempty"`
+	// +kubebuilder:validation:Optional
+	PredictiveAutoscalerSpec *PredictiveAutoscalerSpec `json:"predictive,omitempty"`
+}
+
+// AutoscalePolicy returns the composite policy as a standalone policy
+func (p CompositePolicy) AutoscalePolicy() AutoscalePolicy {
+	policy := AutoscalePolicy{
+		Type:                     p.Type,
+		MetricsAutoscalerSpec:    p.MetricsAutoscalerSpec,
+		ScheduleAutoscalerSpec:   p.ScheduleAutoscalerSpec,
+		BufferAutoscalerSpec:     p.BufferAutoscalerSpec,
+		PredictiveAutoscalerSpec: p.PredictiveAutoscalerSpec,
+	}
+	if p.WebhookAutoscalerSpec != nil {
+		policy.WebhookAutoscalerSpec = *p.WebhookAutoscalerSpec
+	}
+	return policy
+}
+
+// BufferAutoscalerSpec keeps a number of unallocated servers on top of the allocated ones
+type BufferAutoscalerSpec struct {
+	// +kubebuilder:validation:Minimum=0
+	Size int32 `json:"size"`
+}
+
+// PredictiveAutoscalerSpec records the allocated servers over time, forecasts them with seasonal
+// Holt-Winters smoothing and scales ahead of the demand
+type PredictiveAutoscalerSpec struct {
+	// Time between two samples of the allocated servers
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="5m"
+	SampleInterval *metav1.Duration `json:"sampleInterval,omitempty"`
+	// Most samples kept in the history, the oldest are dropped first. The default covers two weeks of 5 minute samples.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=4032
+	// +kubebuilder:default=4032
+	HistorySize int32 `json:"historySize,omitempty"`
+	// Periods the demand repeats over, defaults to a day and a week.
+	// A period is only used once the history covers it.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=4
+	Seasons []metav1.Duration `json:"seasons,omitempty"`
+	// How far ahead to scale, should cover the time it takes a server to become ready
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="10m"
+	LeadTime *metav1.Duration `json:"leadTime,omitempty"`
+	// Percentage of the forecast added on top of it
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=10
+	SafetyMarginPercent int32 `json:"safetyMarginPercent,omitempty"`
 }
 
 type WebhookAutoscalerSpec struct {
 	// +kubebuilder:validation:Optional
-	Url  *string `json:"url,omitempty"`
-	Path *string `json:"path"`
+	Url *string `json:"url,omitempty"`
+	// +kubebuilder:validation:Optional
+	Path *string `json:"path,omitempty"`
+	// Version of the request payload to send. Version 2 keeps the fields of version 1, so it is the default.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=1;2
+	ProtocolVersion *int32 `json:"protocolVersion,omitempty"`
 	// +kubebuilder:validation:Optional
 	Service *Service `json:"service"`
+	// PEM encoded CA certificates used to verify the certificate of the webhook
+	// +kubebuilder:validation:Optional
+	CABundle []byte `json:"caBundle,omitempty"`
+	// Secret holding the credentials sent with the request
+	// +kubebuilder:validation:Optional
+	SecretRef *WebhookSecretRef `json:"secretRef,omitempty"`
+	// Transport used to call the webhook. grpc calls the Autoscaler service of api/proto/autoscaler/v1 instead of
+	// posting JSON to the path.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=http;grpc
+	// +kubebuilder:default=http
+	Transport WebhookTransport `json:"transport,omitempty"`
+	// Deadline of a single request, defaults to 10s
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
+type WebhookTransport string
+
+const (
+	HttpTransport WebhookTransport = "http"
+	GrpcTransport WebhookTransport = "grpc"
+)
+
 type Service struct {
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
 	Port      int    `json:"port"`
+	// Scheme used to call the service, https requires the caBundle unless the certificate is publicly trusted
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=http;https
+	// +kubebuilder:default=http
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// WebhookSecretRef points to keys in a secret in the namespace of the autoscaler
+// WebhookSecretRef points to the Secret holding the credentials of the webhook.
+// The Secret has to be labelled with WebhookCredentialsLabel.
+type WebhookSecretRef struct {
+	Name string `json:"name"`
+	// Key holding the token sent as "Authorization: Bearer <token>"
+	// +kubebuilder:validation:Optional
+	TokenKey string `json:"tokenKey,omitempty"`
+	// Headers to send, from the header name to the key holding its value
+	// +kubebuilder:validation:Optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// Key holding the HMAC key used to sign the request body
+	// +kubebuilder:validation:Optional
+	SigningKey string `json:"signingKey,omitempty"`
+}
+
+// MetricsAutoscalerSpec defines a query against a Prometheus compatible HTTP API.
+// The desired replica count is the queried value divided by the target value, rounded up.
+type MetricsAutoscalerSpec struct {
+	// Base address of the API, for example http://prometheus.monitoring.svc:9090
+	Url string `json:"url"`
+	// PromQL query, which has to return a single series or a scalar
+	Query string `json:"query"`
+	// How much of the queried value a single server should handle
+	TargetValue resource.Quantity `json:"targetValue"`
+}
+
+// ScheduleAutoscalerSpec maps recurring time windows to replica counts
+type ScheduleAutoscalerSpec struct {
+	// Timezone used by windows that do not define their own, for example Europe/Tallinn. Defaults to UTC.
+	// +kubebuilder:validation:Optional
+	Timezone string `json:"timezone,omitempty"`
+	// Replica count used by the schedule type while no window is active.
+	// If not set, the replica count is left as is outside the windows.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	DefaultReplicas *int32 `json:"defaultReplicas,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Windows []ScheduleWindow `json:"windows"`
+}
+
+type ScheduleWindow struct {
+	Name string `json:"name"`
+	// Standard five field cron expression for when the window starts, for example "0 18 * * 5"
+	Start string `json:"start"`
+	// How long the window stays active after it starts
+	Duration metav1.Duration `json:"duration"`
+	// Overrides the timezone of the schedule for this window
+	// +kubebuilder:validation:Optional
+	Timezone string `json:"timezone,omitempty"`
+	// While the window is active, the game is never scaled below this
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas int32 `json:"minReplicas"`
+	// Replica count used by the schedule type while the window is active. Defaults to minReplicas.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	DesiredReplicas *int32 `json:"desiredReplicas,omitempty"`
 }
 
 // The following sync structs handle when to sync
 type Sync struct {
-	// +kubebuilder:validation:Enum=fixedinterval
+	// With the event type, changes to the fleets, servers and pods of the game also trigger a sync.
+	// The interval is then used as the backstop.
+	// +kubebuilder:validation:Enum=fixedinterval;event
 	Type SyncStrategy     `json:"type"`
 	Time *metav1.Duration `json:"interval"`
+	// How long to collect events before syncing. Only used with the event type, defaults to 2s.
+	// +kubebuilder:validation:Optional
+	Debounce *metav1.Duration `json:"debounce,omitempty"`
+	// Minimum time between two syncs triggered by events. Only used with the event type, defaults to 10s.
+	// +kubebuilder:validation:Optional
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
 }
 
+// Condition types used in the GameAutoscaler status
+const (
+	// GameAutoscalerActive is true when the policy was evaluated successfully
+	GameAutoscalerActive = "Active"
+	// GameAutoscalerAbleToScale is true when the GameType can be found and updated
+	GameAutoscalerAbleToScale = "AbleToScale"
+	// GameAutoscalerScalingLimited is true when the replica count was capped by the min or max replicas or a capacity pool
+	GameAutoscalerScalingLimited = "ScalingLimited"
+	// GameAutoscalerDegraded is true when the failure threshold of the failure policy has been reached
+	GameAutoscalerDegraded = "Degraded"
+)
+
 // GameAutoscalerStatus defines the observed state of GameAutoscaler
 type GameAutoscalerStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// When the policy was last evaluated, whether it succeeded or not
+	LastEvaluationTime *metav1.Time `json:"lastEvaluationTime,omitempty"`
+	// When the webhook last responded successfully
+	LastWebhookCallTime *metav1.Time `json:"lastWebhookCallTime,omitempty"`
+	// Protocol version the webhook declared in its last response
+	WebhookProtocolVersion int32 `json:"webhookProtocolVersion,omitempty"`
+	// Replica count decided on the last successful evaluation, not applied in dry run mode
+	LastDesiredReplicas *int32 `json:"lastDesiredReplicas,omitempty"`
+	// When the GameType was last scaled
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+	// When the replica count was last increased, used by the scale up cooldown
+	LastScaleUpTime *metav1.Time `json:"lastScaleUpTime,omitempty"`
+	// When the replica count was last decreased, used by the scale down cooldown
+	LastScaleDownTime *metav1.Time `json:"lastScaleDownTime,omitempty"`
+	// How many evaluations in a row have failed
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Message of the last failure, cleared on success
+	LastError string `json:"lastError,omitempty"`
+	// Recommendations of the policy within the stabilization windows
+	Recommendations []ScaleRecommendation `json:"recommendations,omitempty"`
+	// Scales done within the longest policy period
+	ScaleEvents []ScaleEvent `json:"scaleEvents,omitempty"`
+	// Recommendation of every policy of a composite policy on the last evaluation
+	PolicyRecommendations []PolicyRecommendation `json:"policyRecommendations,omitempty"`
+	// Allocated servers recorded by the predictive policy
+	DemandHistory *DemandHistory `json:"demandHistory,omitempty"`
+	// Push applied on the last evaluation of the push policy
+	ActivePush *PushedReplicas `json:"activePush,omitempty"`
+}
+
+// DemandHistory is the bounded history of the allocated servers, one sample per interval
+type DemandHistory struct {
+	// Interval the samples were taken with, the history is reset when it changes
+	Interval metav1.Duration `json:"interval"`
+	// Time of the newest sample
+	LastSampleTime metav1.Time `json:"lastSampleTime"`
+	// Highest allocated server count within each interval, oldest first
+	Samples []int32 `json:"samples,omitempty"`
+	// Highest allocated server count forecast within the lead time on the last evaluation
+	Forecast *int32 `json:"forecast,omitempty"`
+}
+
+type PolicyRecommendation struct {
+	Name string         `json:"name"`
+	Type PolicyStrategy `json:"type"`
+	// Replica count recommended by the policy, not set if it failed
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Why the policy failed
+	Error string `json:"error,omitempty"`
+	// Whether the recommendation was used
+	Selected bool `json:"selected,omitempty"`
+}
+
+type ScaleRecommendation struct {
+	Time     metav1.Time `json:"time"`
+	Replicas int32       `json:"replicas"`
+}
+
+type ScaleEvent struct {
+	Time metav1.Time `json:"time"`
+	// Replicas added, negative when removed
+	Change int32 `json:"change"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.targetRef.name`
+// +kubebuilder:printcolumn:name="Game",type=string,JSONPath=`.spec.gameName`
+// +kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.targetRef.kind`,priority=1
+// +kubebuilder:printcolumn:name="Policy",type=string,JSONPath=`.spec.policy.type`
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.lastDesiredReplicas`
+// +kubebuilder:printcolumn:name="Active",type=string,JSONPath=`.status.conditions[?(@.type=="Active")].status`
+// +kubebuilder:printcolumn:name="Limited",type=string,JSONPath=`.status.conditions[?(@.type=="ScalingLimited")].status`
+// +kubebuilder:printcolumn:name="Failures",type=integer,JSONPath=`.status.consecutiveFailures`
+// +kubebuilder:printcolumn:name="Last Scale",type=date,JSONPath=`.status.lastScaleTime`
+// +kubebuilder:printcolumn:name="Dry Run",type=boolean,JSONPath=`.spec.dryRun`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // GameAutoscaler is the Schema for the gameautoscalers API
 type GameAutoscaler struct {
@@ -17,21 +17,31 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
+	"crypto/x509"
 	"fmt"
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"strings"
+	"time"
 )
 
 // log is for logging in this package.
 var gameautoscalerlog = logf.Log.WithName("gameautoscaler-resource")
 
-// SetupWebhookWithManager will setup the manager to manage the webhooks
+// SetupWebhookWithManager will setup the manager to manage the webhooks.
+// Validation goes through gameAutoscalerValidator, so the target can be checked with the API reader of the manager.
 func (r *GameAutoscaler) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithValidator(&gameAutoscalerValidator{reader: mgr.GetAPIReader()}).
 		Complete()
 }
 
@@ -39,9 +49,18 @@ func (r *GameAutoscaler) SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 var _ webhook.Defaulter = &GameAutoscaler{}
 
-// Default implements webhook.Defaulter so a webhook will be registered for the type
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+// Autoscalers still using gameName are converted to a targetRef.
 func (r *GameAutoscaler) Default() {
-	//I don't think defaulting currently needed
+	if r.Spec.TargetRef == nil && r.Spec.GameName != "" {
+		r.Spec.TargetRef = &TargetRef{
+			Kind: GameTypeTarget,
+			Name: r.Spec.GameName,
+		}
+	}
+	if r.Spec.TargetRef != nil && r.Spec.TargetRef.Kind == "" {
+		r.Spec.TargetRef.Kind = GameTypeTarget
+	}
 }
 
 // NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
@@ -52,25 +71,47 @@ var _ webhook.Validator = &GameAutoscaler{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *GameAutoscaler) ValidateCreate() (admission.Warnings, error) {
-	if r.Spec.GameName == "" {
-		return admission.Warnings{}, fmt.Errorf("GameAutoscaler must specify GameName")
+	if err := validateTarget(r.Spec); err != nil {
+		return nil, err
+	}
+	if r.Spec.Target().Name == "" {
+		return admission.Warnings{}, fmt.Errorf("GameAutoscaler must specify a targetRef or gameName")
 	}
 	if (r.Spec.Sync == Sync{}) {
 		return nil, fmt.Errorf("cannot create GameAutoscaler without Sync")
 	}
-	if (r.Spec.AutoscalePolicy == AutoscalePolicy{}) {
+	if r.Spec.AutoscalePolicy.Type == "" {
 		return nil, fmt.Errorf("cannot create GameAutoscaler without AutoscalePolicy")
 	}
 
-	autoscalePolicy := r.Spec.AutoscalePolicy
-	webhookautoscaler := autoscalePolicy.WebhookAutoscalerSpec
-	if autoscalePolicy.Type == Webhook && webhookautoscaler.Service == nil && webhookautoscaler.Url == nil {
-		return nil, fmt.Errorf("cannot create GameAutoscaler without url or service specified")
+	if err := validatePolicy(r.Spec.AutoscalePolicy); err != nil {
+		return nil, err
 	}
 
 	if r.Spec.Sync.Time == nil || r.Spec.Sync.Time.Milliseconds() <= 0 {
 		return nil, fmt.Errorf("cannot create GameAutoscaler without proper time")
 	}
+
+	if err := validateSync(r.Spec.Sync); err != nil {
+		return nil, err
+	}
+
+	if r.Spec.MinReplicas != nil && r.Spec.MaxReplicas != nil && *r.Spec.MinReplicas > *r.Spec.MaxReplicas {
+		return nil, fmt.Errorf("minReplicas cannot be larger than maxReplicas")
+	}
+
+	if err := validateFailurePolicy(r.Spec.FailurePolicy); err != nil {
+		return nil, err
+	}
+
+	if r.Spec.Behavior != nil {
+		if err := validateScalingRules(r.Spec.Behavior.ScaleUp); err != nil {
+			return nil, fmt.Errorf("invalid scaleUp behavior: %w", err)
+		}
+		if err := validateScalingRules(r.Spec.Behavior.ScaleDown); err != nil {
+			return nil, fmt.Errorf("invalid scaleDown behavior: %w", err)
+		}
+	}
 	return nil, nil
 }
 
@@ -85,3 +126,372 @@ func (r *GameAutoscaler) ValidateDelete() (admission.Warnings, error) {
 	//No limitations
 	return nil, nil
 }
+
+// validateTarget makes sure the target kind is known and gameName does not point somewhere else
+func validateTarget(spec GameAutoscalerSpec) error {
+	if spec.TargetRef == nil {
+		return nil
+	}
+	switch spec.TargetRef.Kind {
+	case "", GameTypeTarget, FleetTarget:
+	default:
+		return fmt.Errorf("unknown target kind %s", spec.TargetRef.Kind)
+	}
+	if spec.GameName != "" && (spec.TargetRef.Kind == FleetTarget || spec.TargetRef.Name != spec.GameName) {
+		return fmt.Errorf("gameName %s does not match the targetRef, remove gameName", spec.GameName)
+	}
+	return nil
+}
+
+// gameAutoscalerValidator runs the validation of the GameAutoscaler and also makes sure its target exists
+type gameAutoscalerValidator struct {
+	reader client.Reader
+}
+
+var _ webhook.CustomValidator = &gameAutoscalerValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *gameAutoscalerValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	autoscaler, ok := obj.(*GameAutoscaler)
+	if !ok {
+		return nil, fmt.Errorf("expected a GameAutoscaler but got %T", obj)
+	}
+	warnings, err := autoscaler.ValidateCreate()
+	if err != nil {
+		return warnings, err
+	}
+	return warnings, v.validateTargetExists(ctx, autoscaler)
+}
+
+// ValidateUpdate implements webhook.CustomValidator.
+// The target is only checked again when it changes, so an autoscaler whose target was removed can still be updated or have its finalizers removed.
+func (v *gameAutoscalerValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	autoscaler, ok := newObj.(*GameAutoscaler)
+	if !ok {
+		return nil, fmt.Errorf("expected a GameAutoscaler but got %T", newObj)
+	}
+	warnings, err := autoscaler.ValidateUpdate(oldObj)
+	if err != nil {
+		return warnings, err
+	}
+	if old, ok := oldObj.(*GameAutoscaler); ok && old.Spec.Target() == autoscaler.Spec.Target() {
+		return warnings, nil
+	}
+	return warnings, v.validateTargetExists(ctx, autoscaler)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *gameAutoscalerValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	autoscaler, ok := obj.(*GameAutoscaler)
+	if !ok {
+		return nil, fmt.Errorf("expected a GameAutoscaler but got %T", obj)
+	}
+	return autoscaler.ValidateDelete()
+}
+
+// validateTargetExists makes sure the target exists.
+// Fleets created by a GameType cannot be targeted, as the GameType would overwrite their replicas.
+func (v *gameAutoscalerValidator) validateTargetExists(ctx context.Context, autoscaler *GameAutoscaler) error {
+	target := autoscaler.Spec.Target()
+	key := types.NamespacedName{Name: target.Name, Namespace: autoscaler.Namespace}
+	switch target.Kind {
+	case FleetTarget:
+		fleet := &Fleet{}
+		if err := v.reader.Get(ctx, key, fleet); err != nil {
+			return fmt.Errorf("failed to get the target fleet %s: %w", target.Name, err)
+		}
+		if owner := metav1.GetControllerOf(fleet); owner != nil && owner.Kind == "GameType" {
+			return fmt.Errorf("fleet %s is managed by the gametype %s, target the gametype instead", fleet.Name, owner.Name)
+		}
+	default:
+		if err := v.reader.Get(ctx, key, &GameType{}); err != nil {
+			return fmt.Errorf("failed to get the target gametype %s: %w", target.Name, err)
+		}
+	}
+	return nil
+}
+
+// validatePolicy makes sure the policy type is known and the fields the type needs are set
+func validatePolicy(policy AutoscalePolicy) error {
+	if _, exists := validPolicyStrategies[policy.Type]; !exists {
+		return fmt.Errorf("unknown policy type %s", policy.Type)
+	}
+
+	switch policy.Type {
+	case Webhook:
+		webhookautoscaler := policy.WebhookAutoscalerSpec
+		if webhookautoscaler.Service == nil && webhookautoscaler.Url == nil {
+			return fmt.Errorf("cannot create GameAutoscaler without url or service specified")
+		}
+		if err := validateWebhookSecurity(webhookautoscaler); err != nil {
+			return err
+		}
+		if err := validateWebhookTransport(webhookautoscaler); err != nil {
+			return err
+		}
+	case Metrics:
+		metrics := policy.MetricsAutoscalerSpec
+		if metrics == nil {
+			return fmt.Errorf("metrics policy requires the metrics field")
+		}
+		if metrics.Url == "" || metrics.Query == "" {
+			return fmt.Errorf("metrics policy requires both url and query")
+		}
+		if metrics.TargetValue.Sign() <= 0 {
+			return fmt.Errorf("metrics policy target value has to be positive")
+		}
+	case Schedule:
+		if policy.ScheduleAutoscalerSpec == nil {
+			return fmt.Errorf("schedule policy requires the schedule field")
+		}
+	case Buffer:
+		if policy.BufferAutoscalerSpec == nil {
+			return fmt.Errorf("buffer policy requires the buffer field")
+		}
+	case Composite:
+		if err := validateComposite(policy.CompositeAutoscalerSpec); err != nil {
+			return err
+		}
+	case Predictive:
+		if err := validatePredictive(policy.PredictiveAutoscalerSpec); err != nil {
+			return err
+		}
+	case Push:
+		if err := validatePush(policy.PushAutoscalerSpec); err != nil {
+			return err
+		}
+	}
+
+	if policy.ScheduleAutoscalerSpec != nil {
+		if err := validateSchedule(policy.ScheduleAutoscalerSpec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateComposite makes sure the policies of the composite policy are named and valid on their own
+func validateComposite(composite *CompositeAutoscalerSpec) error {
+	if composite == nil || len(composite.Policies) == 0 {
+		return fmt.Errorf("composite policy requires at least one policy")
+	}
+	switch composite.Combinator {
+	case "", MaxCompositeCombinator, MinCompositeCombinator, FirstSuccessfulCompositeCombinator:
+	default:
+		return fmt.Errorf("unknown composite combinator %s", composite.Combinator)
+	}
+
+	names := make(map[string]struct{}, len(composite.Policies))
+	predictive := false
+	for _, policy := range composite.Policies {
+		if policy.Name == "" {
+			return fmt.Errorf("composite policies require a name")
+		}
+		if _, exists := names[policy.Name]; exists {
+			return fmt.Errorf("composite policy name %s is used more than once", policy.Name)
+		}
+		names[policy.Name] = struct{}{}
+		if policy.Type == Composite || policy.Type == Push {
+			return fmt.Errorf("composite policy %s cannot be a %s policy", policy.Name, policy.Type)
+		}
+		//The demand history in the status is shared, so there can only be one predictive policy
+		if policy.Type == Predictive {
+			if predictive {
+				return fmt.Errorf("composite policy can only contain one predictive policy")
+			}
+			predictive = true
+		}
+		if err := validatePolicy(policy.AutoscalePolicy()); err != nil {
+			return fmt.Errorf("invalid composite policy %s: %w", policy.Name, err)
+		}
+	}
+	return nil
+}
+
+// validatePush makes sure the base policy is valid on its own and the max TTL is positive
+func validatePush(push *PushAutoscalerSpec) error {
+	if push == nil {
+		return fmt.Errorf("push policy requires the push field")
+	}
+	if push.MaxTTL != nil && push.MaxTTL.Duration <= 0 {
+		return fmt.Errorf("push max TTL has to be positive")
+	}
+	if push.Base == nil {
+		return nil
+	}
+	if push.Base.Type == Composite || push.Base.Type == Push {
+		return fmt.Errorf("push base policy cannot be a %s policy", push.Base.Type)
+	}
+	if err := validatePolicy(push.Base.AutoscalePolicy()); err != nil {
+		return fmt.Errorf("invalid push base policy: %w", err)
+	}
+	return nil
+}
+
+// validatePredictive makes sure the durations of the predictive policy fit the sample interval
+func validatePredictive(predictive *PredictiveAutoscalerSpec) error {
+	if predictive == nil {
+		return fmt.Errorf("predictive policy requires the predictive field")
+	}
+	if predictive.LeadTime != nil && predictive.LeadTime.Duration < 0 {
+		return fmt.Errorf("predictive lead time cannot be negative")
+	}
+	interval := 5 * time.Minute
+	if predictive.SampleInterval != nil {
+		if predictive.SampleInterval.Duration <= 0 {
+			return fmt.Errorf("predictive sample interval has to be positive")
+		}
+		interval = predictive.SampleInterval.Duration
+	}
+	for _, season := range predictive.Seasons {
+		if season.Duration < 2*interval {
+			return fmt.Errorf("predictive season %s has to be at least two sample intervals", season.Duration)
+		}
+	}
+	return nil
+}
+
+// validateWebhookSecurity makes sure the CA bundle can be parsed and the secret reference is complete
+func validateWebhookSecurity(spec WebhookAutoscalerSpec) error {
+	if len(spec.CABundle) > 0 && !x509.NewCertPool().AppendCertsFromPEM(spec.CABundle) {
+		return fmt.Errorf("webhook caBundle does not contain any PEM encoded certificates")
+	}
+	if spec.SecretRef != nil {
+		if spec.SecretRef.Name == "" {
+			return fmt.Errorf("webhook secretRef requires a name")
+		}
+		if spec.SecretRef.TokenKey == "" && spec.SecretRef.SigningKey == "" && len(spec.SecretRef.Headers) == 0 {
+			return fmt.Errorf("webhook secretRef has to set a token key, signing key or headers")
+		}
+		// The signing key itself is never sent, but tokens and headers would be readable by anyone on the way
+		if (spec.SecretRef.TokenKey != "" || len(spec.SecretRef.Headers) > 0) && !usesTLS(spec) {
+			return fmt.Errorf("webhook secretRef token and headers require https")
+		}
+	}
+	return nil
+}
+
+// usesTLS returns whether the webhook is called over TLS, in the same way as the operator decides it when calling it.
+// HTTP uses TLS for https urls and services, gRPC also whenever a CA bundle is set.
+func usesTLS(spec WebhookAutoscalerSpec) bool {
+	https := false
+	if spec.Url != nil {
+		https = strings.HasPrefix(strings.ToLower(*spec.Url), "https://")
+	} else if spec.Service != nil {
+		https = spec.Service.Scheme == "https"
+	}
+	if spec.Transport == GrpcTransport {
+		return https || len(spec.CABundle) > 0
+	}
+	return https
+}
+
+// validateWebhookTransport makes sure the transport is known and supports the configured credentials
+func validateWebhookTransport(spec WebhookAutoscalerSpec) error {
+	switch spec.Transport {
+	case "", HttpTransport:
+	case GrpcTransport:
+		// The signature covers the JSON body, gRPC requests should rely on TLS and tokens instead
+		if spec.SecretRef != nil && spec.SecretRef.SigningKey != "" {
+			return fmt.Errorf("webhook signingKey is not supported by the grpc transport")
+		}
+	default:
+		return fmt.Errorf("unknown webhook transport %s", spec.Transport)
+	}
+	if spec.Timeout != nil && spec.Timeout.Duration <= 0 {
+		return fmt.Errorf("webhook timeout has to be positive")
+	}
+	return nil
+}
+
+// validateFailurePolicy makes sure a fallback has something to fall back to
+func validateFailurePolicy(policy *FailurePolicy) error {
+	if policy == nil {
+		return nil
+	}
+	switch policy.Type {
+	case "", HoldFailurePolicy:
+	case FallbackFailurePolicy:
+		if policy.FallbackReplicas == nil && policy.FallbackSchedule == nil {
+			return fmt.Errorf("fallback failure policy requires fallbackReplicas or fallbackSchedule")
+		}
+	default:
+		return fmt.Errorf("unknown failure policy type %s", policy.Type)
+	}
+	if policy.FallbackSchedule != nil {
+		if err := validateSchedule(policy.FallbackSchedule); err != nil {
+			return fmt.Errorf("invalid fallback schedule: %w", err)
+		}
+	}
+	if policy.InitialBackoff != nil && policy.InitialBackoff.Duration <= 0 {
+		return fmt.Errorf("initial backoff has to be positive")
+	}
+	if policy.MaxBackoff != nil && policy.MaxBackoff.Duration <= 0 {
+		return fmt.Errorf("max backoff has to be positive")
+	}
+	return nil
+}
+
+// validateScalingRules makes sure the durations are in range and every policy limits something
+func validateScalingRules(rules *ScalingRules) error {
+	if rules == nil {
+		return nil
+	}
+	if rules.StabilizationWindow != nil && (rules.StabilizationWindow.Duration < 0 || rules.StabilizationWindow.Duration > time.Hour) {
+		return fmt.Errorf("stabilization window has to be between 0 and 1 hour")
+	}
+	if rules.Cooldown != nil && rules.Cooldown.Duration < 0 {
+		return fmt.Errorf("cooldown cannot be negative")
+	}
+	for _, policy := range rules.Policies {
+		if policy.Type != ReplicasScalingPolicy && policy.Type != PercentScalingPolicy {
+			return fmt.Errorf("unknown scaling policy type %s", policy.Type)
+		}
+		if policy.Value <= 0 {
+			return fmt.Errorf("scaling policy value has to be positive")
+		}
+		if policy.Period.Duration <= 0 || policy.Period.Duration > time.Hour {
+			return fmt.Errorf("scaling policy period has to be between 0 and 1 hour")
+		}
+	}
+	return nil
+}
+
+// validateSync makes sure the sync type is known and the event timings are not negative
+func validateSync(sync Sync) error {
+	if _, exists := validSyncStrategy[sync.Type]; !exists {
+		return fmt.Errorf("unknown sync type %s", sync.Type)
+	}
+	if sync.Debounce != nil && sync.Debounce.Duration < 0 {
+		return fmt.Errorf("sync debounce cannot be negative")
+	}
+	if sync.MinInterval != nil && sync.MinInterval.Duration < 0 {
+		return fmt.Errorf("sync minimum interval cannot be negative")
+	}
+	return nil
+}
+
+// validateSchedule checks that every window has a valid cron expression, timezone and duration
+func validateSchedule(schedule *ScheduleAutoscalerSpec) error {
+	if len(schedule.Windows) == 0 {
+		return fmt.Errorf("schedule requires at least one window")
+	}
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return fmt.Errorf("invalid schedule timezone %s: %w", schedule.Timezone, err)
+	}
+	for _, window := range schedule.Windows {
+		if _, err := cron.ParseStandard(window.Start); err != nil {
+			return fmt.Errorf("invalid start for window %s: %w", window.Name, err)
+		}
+		if _, err := time.LoadLocation(window.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone for window %s: %w", window.Name, err)
+		}
+		if window.Duration.Duration <= 0 {
+			return fmt.Errorf("window %s requires a positive duration", window.Name)
+		}
+		if window.DesiredReplicas != nil && *window.DesiredReplicas < window.MinReplicas {
+			return fmt.Errorf("window %s desired replicas cannot be lower than its min replicas", window.Name)
+		}
+	}
+	return nil
+}
@@ -19,7 +19,10 @@ package v1alpha1
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"time"
 )
 
@@ -144,6 +147,523 @@ var _ = Describe("GameAutoscaler Webhook", func() {
 			Expect(err).To(Not(HaveOccurred()))
 		})
 
+		It("Should validate the metrics policy", func() {
+			gameautoscaler := &GameAutoscaler{
+				Spec: GameAutoscalerSpec{
+					GameName: "random",
+					AutoscalePolicy: AutoscalePolicy{
+						Type: Metrics,
+					},
+					Sync: Sync{
+						Type: FixedInterval,
+						Time: &metav1.Duration{Duration: 5 * time.Second},
+					},
+				},
+			}
+			By("Fails without the metrics field")
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails without a query")
+			gameautoscaler.Spec.AutoscalePolicy.MetricsAutoscalerSpec = &MetricsAutoscalerSpec{
+				Url:         "http://prometheus:9090",
+				TargetValue: resource.MustParse("10"),
+			}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with a zero target")
+			gameautoscaler.Spec.AutoscalePolicy.MetricsAutoscalerSpec.Query = "sum(players)"
+			gameautoscaler.Spec.AutoscalePolicy.MetricsAutoscalerSpec.TargetValue = resource.MustParse("0")
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds if no issues")
+			gameautoscaler.Spec.AutoscalePolicy.MetricsAutoscalerSpec.TargetValue = resource.MustParse("2.5")
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+
+			By("Fails with an unknown policy type")
+			gameautoscaler.Spec.AutoscalePolicy.Type = "random"
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should validate the schedule policy", func() {
+			gameautoscaler := &GameAutoscaler{
+				Spec: GameAutoscalerSpec{
+					GameName: "random",
+					AutoscalePolicy: AutoscalePolicy{
+						Type: Schedule,
+					},
+					Sync: Sync{
+						Type: FixedInterval,
+						Time: &metav1.Duration{Duration: 5 * time.Second},
+					},
+				},
+			}
+			By("Fails without the schedule field")
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with an invalid cron expression")
+			gameautoscaler.Spec.AutoscalePolicy.ScheduleAutoscalerSpec = &ScheduleAutoscalerSpec{
+				Windows: []ScheduleWindow{
+					{
+						Name:        "evening",
+						Start:       "0 18 * *",
+						Duration:    metav1.Duration{Duration: 4 * time.Hour},
+						MinReplicas: 5,
+					},
+				},
+			}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with an invalid timezone")
+			gameautoscaler.Spec.AutoscalePolicy.ScheduleAutoscalerSpec.Windows[0].Start = "0 18 * * *"
+			gameautoscaler.Spec.AutoscalePolicy.ScheduleAutoscalerSpec.Timezone = "Europe/Nowhere"
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with desired replicas below the minimum")
+			gameautoscaler.Spec.AutoscalePolicy.ScheduleAutoscalerSpec.Timezone = "Europe/Tallinn"
+			desired := int32(2)
+			gameautoscaler.Spec.AutoscalePolicy.ScheduleAutoscalerSpec.Windows[0].DesiredReplicas = &desired
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds if no issues")
+			desired = 8
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		It("Should validate the sync", func() {
+			service := Service{
+				Name:      "a",
+				Namespace: "default",
+				Port:      33,
+			}
+			gameautoscaler := &GameAutoscaler{
+				Spec: GameAutoscalerSpec{
+					GameName: "random",
+					AutoscalePolicy: AutoscalePolicy{
+						Type:                  Webhook,
+						WebhookAutoscalerSpec: WebhookAutoscalerSpec{Service: &service},
+					},
+					Sync: Sync{
+						Type: "random",
+						Time: &metav1.Duration{Duration: time.Minute},
+					},
+				},
+			}
+			By("Fails with an unknown sync type")
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with a negative debounce")
+			gameautoscaler.Spec.Sync.Type = Event
+			gameautoscaler.Spec.Sync.Debounce = &metav1.Duration{Duration: -time.Second}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds if no issues")
+			gameautoscaler.Spec.Sync.Debounce = &metav1.Duration{Duration: time.Second}
+			gameautoscaler.Spec.Sync.MinInterval = &metav1.Duration{Duration: 10 * time.Second}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		It("Should validate the replica limits", func() {
+			service := Service{
+				Name:      "a",
+				Namespace: "default",
+				Port:      33,
+			}
+			minReplicas := int32(5)
+			maxReplicas := int32(2)
+			gameautoscaler := &GameAutoscaler{
+				Spec: GameAutoscalerSpec{
+					GameName: "random",
+					AutoscalePolicy: AutoscalePolicy{
+						Type:                  Webhook,
+						WebhookAutoscalerSpec: WebhookAutoscalerSpec{Service: &service},
+					},
+					Sync: Sync{
+						Type: FixedInterval,
+						Time: &metav1.Duration{Duration: time.Minute},
+					},
+					MinReplicas: &minReplicas,
+					MaxReplicas: &maxReplicas,
+				},
+			}
+			By("Fails if the minimum is above the maximum")
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			maxReplicas = 10
+
+			By("Fails with an invalid behavior policy")
+			gameautoscaler.Spec.Behavior = &ScalingBehavior{
+				ScaleDown: &ScalingRules{
+					StabilizationWindow: &metav1.Duration{Duration: 5 * time.Minute},
+					Policies: []ScalingPolicy{
+						{Type: PercentScalingPolicy, Value: 50, Period: metav1.Duration{}},
+					},
+				},
+			}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			gameautoscaler.Spec.Behavior.ScaleDown.Policies[0].Period = metav1.Duration{Duration: time.Minute}
+
+			By("Succeeds if no issues")
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		It("Should validate the webhook security", func() {
+			service := Service{
+				Name:      "a",
+				Namespace: "default",
+				Port:      33,
+				Scheme:    "https",
+			}
+			gameautoscaler := &GameAutoscaler{
+				Spec: GameAutoscalerSpec{
+					GameName: "random",
+					AutoscalePolicy: AutoscalePolicy{
+						Type: Webhook,
+						WebhookAutoscalerSpec: WebhookAutoscalerSpec{
+							Service:  &service,
+							CABundle: []byte("not a certificate"),
+						},
+					},
+					Sync: Sync{
+						Type: FixedInterval,
+						Time: &metav1.Duration{Duration: time.Minute},
+					},
+				},
+			}
+			By("Fails with an invalid CA bundle")
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with an empty secret reference")
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.CABundle = nil
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.SecretRef = &WebhookSecretRef{Name: "credentials"}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds if no issues")
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.SecretRef.SigningKey = "signing"
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+
+			By("Fails with headers sent to a plain http service")
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.SecretRef.Headers = map[string]string{"X-Tenant": "tenant"}
+			service.Scheme = "http"
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds with headers sent to an https service")
+			service.Scheme = "https"
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		It("Should validate the webhook transport", func() {
+			url := "localhost:9000"
+			gameautoscaler := &GameAutoscaler{
+				Spec: GameAutoscalerSpec{
+					GameName: "random",
+					AutoscalePolicy: AutoscalePolicy{
+						Type: Webhook,
+						WebhookAutoscalerSpec: WebhookAutoscalerSpec{
+							Url:       &url,
+							Transport: "carrier-pigeon",
+						},
+					},
+					Sync: Sync{
+						Type: FixedInterval,
+						Time: &metav1.Duration{Duration: time.Minute},
+					},
+				},
+			}
+			By("Fails with an unknown transport")
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with a signing key over grpc")
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.Transport = GrpcTransport
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.SecretRef = &WebhookSecretRef{Name: "credentials", SigningKey: "signing"}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with a token sent in cleartext")
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.SecretRef = &WebhookSecretRef{Name: "credentials", TokenKey: "token"}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with a zero timeout")
+			url = "https://localhost:9000"
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.Timeout = &metav1.Duration{}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds if no issues")
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.Timeout = &metav1.Duration{Duration: 3 * time.Second}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		It("Should validate the predictive policy", func() {
+			gameautoscaler := &GameAutoscaler{
+				Spec: GameAutoscalerSpec{
+					GameName: "random",
+					AutoscalePolicy: AutoscalePolicy{
+						Type: Predictive,
+					},
+					Sync: Sync{
+						Type: FixedInterval,
+						Time: &metav1.Duration{Duration: time.Minute},
+					},
+				},
+			}
+			By("Fails without the predictive field")
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with a season shorter than two samples")
+			gameautoscaler.Spec.AutoscalePolicy.PredictiveAutoscalerSpec = &PredictiveAutoscalerSpec{
+				SampleInterval: &metav1.Duration{Duration: time.Hour},
+				Seasons:        []metav1.Duration{{Duration: time.Hour}},
+			}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with two predictive policies in a composite policy")
+			predictive := &PredictiveAutoscalerSpec{}
+			gameautoscaler.Spec.AutoscalePolicy = AutoscalePolicy{
+				Type: Composite,
+				CompositeAutoscalerSpec: &CompositeAutoscalerSpec{
+					Policies: []CompositePolicy{
+						{Name: "first", Type: Predictive, PredictiveAutoscalerSpec: predictive},
+						{Name: "second", Type: Predictive, PredictiveAutoscalerSpec: predictive},
+					},
+				},
+			}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds if no issues")
+			gameautoscaler.Spec.AutoscalePolicy.CompositeAutoscalerSpec.Policies[1] = CompositePolicy{
+				Name: "buffer", Type: Buffer, BufferAutoscalerSpec: &BufferAutoscalerSpec{Size: 2},
+			}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		It("Should validate the push policy", func() {
+			gameautoscaler := &GameAutoscaler{
+				Spec: GameAutoscalerSpec{
+					GameName: "random",
+					AutoscalePolicy: AutoscalePolicy{
+						Type: Push,
+					},
+					Sync: Sync{
+						Type: FixedInterval,
+						Time: &metav1.Duration{Duration: time.Minute},
+					},
+				},
+			}
+			By("Fails without the push field")
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with an invalid base policy")
+			gameautoscaler.Spec.AutoscalePolicy.PushAutoscalerSpec = &PushAutoscalerSpec{
+				Base: &CompositePolicy{Name: "base", Type: Buffer},
+			}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with a zero max TTL")
+			gameautoscaler.Spec.AutoscalePolicy.PushAutoscalerSpec.Base.BufferAutoscalerSpec = &BufferAutoscalerSpec{Size: 2}
+			gameautoscaler.Spec.AutoscalePolicy.PushAutoscalerSpec.MaxTTL = &metav1.Duration{}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds if no issues")
+			gameautoscaler.Spec.AutoscalePolicy.PushAutoscalerSpec.MaxTTL = &metav1.Duration{Duration: time.Hour}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		It("Should validate the failure policy", func() {
+			url := "http://localhost"
+			gameautoscaler := &GameAutoscaler{
+				Spec: GameAutoscalerSpec{
+					GameName: "random",
+					AutoscalePolicy: AutoscalePolicy{
+						Type: Webhook,
+						WebhookAutoscalerSpec: WebhookAutoscalerSpec{
+							Url: &url,
+						},
+					},
+					Sync: Sync{
+						Type: FixedInterval,
+						Time: &metav1.Duration{Duration: time.Minute},
+					},
+					FailurePolicy: &FailurePolicy{
+						Type: FallbackFailurePolicy,
+					},
+				},
+			}
+			By("Fails with nothing to fall back to")
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with an invalid fallback schedule")
+			gameautoscaler.Spec.FailurePolicy.FallbackSchedule = &ScheduleAutoscalerSpec{
+				Windows: []ScheduleWindow{{Name: "peak", Start: "not a cron", Duration: metav1.Duration{Duration: time.Hour}, MinReplicas: 5}},
+			}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with a negative backoff")
+			fallbackReplicas := int32(5)
+			gameautoscaler.Spec.FailurePolicy.FallbackSchedule = nil
+			gameautoscaler.Spec.FailurePolicy.FallbackReplicas = &fallbackReplicas
+			gameautoscaler.Spec.FailurePolicy.InitialBackoff = &metav1.Duration{Duration: -time.Second}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds if no issues")
+			gameautoscaler.Spec.FailurePolicy.InitialBackoff = nil
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		It("Should validate the composite policy", func() {
+			url := "http://localhost"
+			gameautoscaler := &GameAutoscaler{
+				Spec: GameAutoscalerSpec{
+					GameName: "random",
+					AutoscalePolicy: AutoscalePolicy{
+						Type: Composite,
+					},
+					Sync: Sync{
+						Type: FixedInterval,
+						Time: &metav1.Duration{Duration: time.Minute},
+					},
+				},
+			}
+			By("Fails without policies")
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with an invalid policy")
+			composite := &CompositeAutoscalerSpec{
+				Policies: []CompositePolicy{
+					{Name: "buffer", Type: Buffer, BufferAutoscalerSpec: &BufferAutoscalerSpec{Size: 5}},
+					{Name: "webhook", Type: Webhook},
+				},
+			}
+			gameautoscaler.Spec.AutoscalePolicy.CompositeAutoscalerSpec = composite
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with a duplicate name")
+			composite.Policies[1] = CompositePolicy{Name: "buffer", Type: Webhook, WebhookAutoscalerSpec: &WebhookAutoscalerSpec{Url: &url}}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with a nested composite policy")
+			composite.Policies[1] = CompositePolicy{Name: "nested", Type: Composite}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Fails with an unknown combinator")
+			composite.Policies[1] = CompositePolicy{Name: "webhook", Type: Webhook, WebhookAutoscalerSpec: &WebhookAutoscalerSpec{Url: &url}}
+			composite.Combinator = "average"
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds if no issues")
+			composite.Combinator = FirstSuccessfulCompositeCombinator
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+		})
+
+		It("Should validate the target", func() {
+			url := "http://localhost"
+			gameautoscaler := &GameAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "autoscaler", Namespace: "default"},
+				Spec: GameAutoscalerSpec{
+					GameName: "game",
+					AutoscalePolicy: AutoscalePolicy{
+						Type: Webhook,
+						WebhookAutoscalerSpec: WebhookAutoscalerSpec{
+							Url: &url,
+						},
+					},
+					Sync: Sync{
+						Type: FixedInterval,
+						Time: &metav1.Duration{Duration: time.Minute},
+					},
+				},
+			}
+			By("Converting the game name to a target")
+			gameautoscaler.Default()
+			Expect(gameautoscaler.Spec.TargetRef).To(Equal(&TargetRef{Kind: GameTypeTarget, Name: "game"}))
+			_, err := gameautoscaler.ValidateCreate()
+			Expect(err).To(Not(HaveOccurred()))
+
+			By("Fails if the game name does not match the target")
+			gameautoscaler.Spec.TargetRef = &TargetRef{Kind: FleetTarget, Name: "game"}
+			_, err = gameautoscaler.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+
+			scheme := runtime.NewScheme()
+			Expect(AddToScheme(scheme)).To(Succeed())
+			owned := &Fleet{ObjectMeta: metav1.ObjectMeta{
+				Name:            "game-fleet",
+				Namespace:       "default",
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(&GameType{ObjectMeta: metav1.ObjectMeta{Name: "game"}}, GroupVersion.WithKind("GameType"))},
+			}}
+			standalone := &Fleet{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "default"}}
+			validator := &gameAutoscalerValidator{
+				reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(owned, standalone).Build(),
+			}
+
+			By("Fails if the target does not exist")
+			gameautoscaler.Spec.GameName = ""
+			gameautoscaler.Spec.TargetRef = &TargetRef{Kind: GameTypeTarget, Name: "game"}
+			_, err = validator.ValidateCreate(ctx, gameautoscaler)
+			Expect(err).To(HaveOccurred())
+
+			By("Fails if the fleet is managed by a gametype")
+			gameautoscaler.Spec.TargetRef = &TargetRef{Kind: FleetTarget, Name: "game-fleet"}
+			_, err = validator.ValidateCreate(ctx, gameautoscaler)
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeds with a standalone fleet")
+			old := gameautoscaler.DeepCopy()
+			gameautoscaler.Spec.TargetRef = &TargetRef{Kind: FleetTarget, Name: "standalone"}
+			_, err = validator.ValidateUpdate(ctx, old, gameautoscaler)
+			Expect(err).To(Not(HaveOccurred()))
+
+			By("Only checking the target again when it changes")
+			missing := gameautoscaler.DeepCopy()
+			missing.Spec.TargetRef = &TargetRef{Kind: GameTypeTarget, Name: "removed"}
+			updated := missing.DeepCopy()
+			updated.Spec.DryRun = true
+			_, err = validator.ValidateUpdate(ctx, missing, updated)
+			Expect(err).To(Not(HaveOccurred()))
+			_, err = validator.ValidateUpdate(ctx, gameautoscaler, updated)
+			Expect(err).To(HaveOccurred())
+		})
+
 		It("Should validate delete", func() {
 			gameautoscaler := &GameAutoscaler{
 				Spec: GameAutoscalerSpec{
@@ -23,6 +23,9 @@ import (
 // GameTypeSpec defines the desired state of GameType
 type GameTypeSpec struct {
 	FleetSpec FleetSpec `json:"fleetSpec"`
+	// How the GameType shares the capacity pools selecting it
+	// +kubebuilder:validation:Optional
+	Capacity *GameTypeCapacity `json:"capacity,omitempty"`
 }
 
 // GameTypeStatus defines the observed state of GameType
@@ -57,3 +60,13 @@ type GameTypeList struct {
 func init() {
 	SchemeBuilder.Register(&GameType{}, &GameTypeList{})
 }
+
+// GetReplicas returns the replica count of the fleets of the gametype
+func (r *GameType) GetReplicas() int32 {
+	return r.Spec.FleetSpec.Scaling.Replicas
+}
+
+// SetReplicas sets the replica count of the fleets of the gametype
+func (r *GameType) SetReplicas(replicas int32) {
+	r.Spec.FleetSpec.Scaling.Replicas = replicas
+}
@@ -65,12 +65,12 @@ func (r *GameType) ValidateCreate() (admission.Warnings, error) {
 	if len(r.Spec.FleetSpec.ServerSpec.Pod.Containers) == 0 {
 		return nil, errors.New("at least one container is required")
 	}
-	return nil, nil
+	return shutdownDeliveryWarnings(r.Spec.FleetSpec.ServerSpec), nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *GameType) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
-	return nil, nil
+	return shutdownDeliveryWarnings(r.Spec.FleetSpec.ServerSpec), nil
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
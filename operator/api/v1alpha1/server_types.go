@@ -29,11 +29,95 @@ type ServerSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=false
 	AllowForceDelete bool `json:"allowForceDelete,omitempty"`
+	// PushState makes the sidecar patch its state into the status of the Server whenever it changes,
+	// so the operator reads it from there instead of asking the sidecar over HTTP.
+	// +kubebuilder:validation:Optional
+	PushState bool `json:"pushState,omitempty"`
+	// ShutdownDelivery delivers the shutdown request to game servers that cannot call the sidecar themselves
+	// +kubebuilder:validation:Optional
+	ShutdownDelivery *ShutdownDelivery `json:"shutdownDelivery,omitempty"`
+	// Lifecycle is Persistent for servers that run until they are deleted, or OneShot for servers that run a single match and exit.
+	// A OneShot server is marked Completed once its game containers exit, which allows its deletion, and its fleet replaces it.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Persistent
+	// +kubebuilder:validation:Enum=Persistent;OneShot
+	Lifecycle Lifecycle `json:"lifecycle,omitempty"`
+}
+
+type Lifecycle string
+
+const (
+	LifecyclePersistent Lifecycle = "Persistent"
+	LifecycleOneShot    Lifecycle = "OneShot"
+)
+
+const (
+	// ServerCompleted is true once the game containers of a OneShot server exited
+	ServerCompleted = "Completed"
+)
+
+// ShutdownDelivery configures how the sidecar translates the shutdown request for the game, and how it infers that the game allows its deletion.
+// The files are kept in a directory shared by every container of the pod, given to them in the GAME_SHARED_DIR environment variable.
+// Signalling the game and watching it exit both require the game to run as the same user as the sidecar, SidecarUser.
+// +kubebuilder:validation:XValidation:rule="!(has(self.signal) || (has(self.allowDeleteOnExit) && self.allowDeleteOnExit)) || (has(self.process) && size(self.process) > 0)",message="process is required with signal or allowDeleteOnExit"
+type ShutdownDelivery struct {
+	// Signal is sent to the game process when the shutdown is requested. It shares the process namespace of the pod.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=SIGTERM;SIGINT;SIGHUP;SIGQUIT;SIGUSR1;SIGUSR2
+	Signal string `json:"signal,omitempty"`
+	// Process is the name of the game process, matched against its command name or the file name of its executable.
+	// It is required with Signal or AllowDeleteOnExit, as other processes in the pod, like a mesh proxy or a shell, would count as the game otherwise.
+	// +kubebuilder:validation:Optional
+	Process string `json:"process,omitempty"`
+	// File is written into the shared directory when the shutdown is requested, and removed if the request is withdrawn
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9._-]+$`
+	File string `json:"file,omitempty"`
+	// AllowDeleteFile allows the deletion once the game creates it in the shared directory
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9._-]+$`
+	AllowDeleteFile string `json:"allowDeleteFile,omitempty"`
+	// AllowDeleteOnExit allows the deletion once the game process exits. It shares the process namespace of the pod.
+	// +kubebuilder:validation:Optional
+	AllowDeleteOnExit bool `json:"allowDeleteOnExit,omitempty"`
+}
+
+// SidecarUser is the user the sidecar image runs as. A game that is signalled or watched by the sidecar has to run as this user,
+// as Linux only allows signalling processes of the same user.
+const SidecarUser int64 = 65532
+
+// SharesProcessNamespace returns whether the sidecar needs to see the processes of the game
+func (d *ShutdownDelivery) SharesProcessNamespace() bool {
+	return d != nil && (d.Signal != "" || d.AllowDeleteOnExit)
+}
+
+// UsesSharedDir returns whether the sidecar and the game exchange files
+func (d *ShutdownDelivery) UsesSharedDir() bool {
+	return d != nil && (d.File != "" || d.AllowDeleteFile != "")
 }
 
 // ServerStatus defines the observed state of Server
 type ServerStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// Number of players reported for the server
+	Players int64 `json:"players,omitempty"`
+	// Custom counters reported for the server, for example the number of matches in progress
+	Counters map[string]int64 `json:"counters,omitempty"`
+	// State pushed by the sidecar, when PushState is enabled
+	Sidecar *SidecarStatus `json:"sidecar,omitempty"`
+}
+
+// SidecarStatus is the state of the sidecar, as last pushed by it
+type SidecarStatus struct {
+	DeleteAllowed     bool `json:"deleteAllowed"`
+	ShutdownRequested bool `json:"shutdownRequested"`
+	Ready             bool `json:"ready"`
+	// Allocated is set by the game server while it is in use
+	Allocated bool `json:"allocated,omitempty"`
+	// Version of the state in the sidecar, increasing on every change
+	Version int64 `json:"version"`
+	// Time the state was pushed
+	UpdateTime metav1.Time `json:"updateTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
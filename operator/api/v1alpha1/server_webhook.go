@@ -68,7 +68,7 @@ func (r *Server) ValidateCreate() (admission.Warnings, error) {
 			return nil, errors.New("image is required for every container")
 		}
 	}
-	return nil, nil
+	return shutdownDeliveryWarnings(r.Spec), nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
@@ -80,6 +80,12 @@ func (r *Server) ValidateUpdate(old runtime.Object) (admission.Warnings, error)
 	if !arePodSpecsEqual(oldServer.Spec.Pod, r.Spec.Pod) {
 		return nil, errors.New("updating a servers pod spec is not allowed, please remake the server")
 	}
+	if !reflect.DeepEqual(oldServer.Spec.ShutdownDelivery, r.Spec.ShutdownDelivery) {
+		return nil, errors.New("updating a servers shutdown delivery is not allowed, please remake the server")
+	}
+	if oldServer.Spec.Lifecycle != r.Spec.Lifecycle {
+		return nil, errors.New("updating a servers lifecycle is not allowed, please remake the server")
+	}
 
 	return nil, nil
 }
@@ -89,6 +95,27 @@ func (r *Server) ValidateDelete() (admission.Warnings, error) {
 	return nil, nil
 }
 
+// shutdownDeliveryWarnings warns about game containers that run as another user than the sidecar, when the sidecar has to signal or watch them.
+// Containers without a user set run as the user of their image, which cannot be checked here.
+func shutdownDeliveryWarnings(spec ServerSpec) admission.Warnings {
+	if !spec.ShutdownDelivery.SharesProcessNamespace() {
+		return nil
+	}
+	var warnings admission.Warnings
+	for _, container := range spec.Pod.Containers {
+		var runAsUser *int64
+		if container.SecurityContext != nil && container.SecurityContext.RunAsUser != nil {
+			runAsUser = container.SecurityContext.RunAsUser
+		} else if spec.Pod.SecurityContext != nil {
+			runAsUser = spec.Pod.SecurityContext.RunAsUser
+		}
+		if runAsUser != nil && *runAsUser != SidecarUser {
+			warnings = append(warnings, fmt.Sprintf("container %s runs as user %d, but the sidecar can only signal and watch processes of user %d", container.Name, *runAsUser, SidecarUser))
+		}
+	}
+	return warnings
+}
+
 func arePodSpecsEqual(a, b corev1.PodSpec) bool {
 	return reflect.DeepEqual(a, b)
 }
@@ -73,6 +73,37 @@ var _ = Describe("Server Webhook", func() {
 			_, err = server.ValidateCreate()
 			Expect(err).To(Succeed())
 		})
+
+		It("Should warn if the game runs as another user than the sidecar", func() {
+			otherUser := int64(1000)
+			sidecarUser := SidecarUser
+			server := Server{
+				Spec: ServerSpec{
+					Pod: corev1.PodSpec{
+						SecurityContext: &corev1.PodSecurityContext{RunAsUser: &otherUser},
+						Containers:      []corev1.Container{{Name: "game", Image: "image"}},
+					},
+				},
+			}
+
+			By("Not warning without a signal or exit detection")
+			server.Spec.ShutdownDelivery = &ShutdownDelivery{File: "shutdown"}
+			warnings, err := server.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+
+			By("Warning about the user of the pod")
+			server.Spec.ShutdownDelivery = &ShutdownDelivery{Signal: "SIGTERM", Process: "game"}
+			warnings, err = server.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+
+			By("Not warning when the container runs as the sidecar user")
+			server.Spec.Pod.Containers[0].SecurityContext = &corev1.SecurityContext{RunAsUser: &sidecarUser}
+			warnings, err = server.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
 	})
 
 	Context("When updating Server under Validating Webhook", func() {
@@ -114,6 +145,17 @@ var _ = Describe("Server Webhook", func() {
 			newServer.Spec.TimeOut = &metav1.Duration{Duration: time.Minute * 20}
 			_, err = server.ValidateUpdate(&newServer)
 			Expect(err).To(Succeed())
+
+			By("Check if fails if different shutdown delivery")
+			newServer.Spec.ShutdownDelivery = &ShutdownDelivery{Signal: "SIGTERM", Process: "game"}
+			_, err = server.ValidateUpdate(&newServer)
+			Expect(err).To(HaveOccurred())
+
+			By("Check if fails if different lifecycle")
+			newServer.Spec.ShutdownDelivery = nil
+			newServer.Spec.Lifecycle = LifecycleOneShot
+			_, err = server.ValidateUpdate(&newServer)
+			Expect(err).To(HaveOccurred())
 		})
 	})
 	Context("When deleting Server under Validating Webhook", func() {
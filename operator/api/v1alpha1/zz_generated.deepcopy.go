@@ -21,7 +21,8 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -29,6 +30,36 @@ import (
 func (in *AutoscalePolicy) DeepCopyInto(out *AutoscalePolicy) {
 	*out = *in
 	in.WebhookAutoscalerSpec.DeepCopyInto(&out.WebhookAutoscalerSpec)
+	if in.MetricsAutoscalerSpec != nil {
+		in, out := &in.MetricsAutoscalerSpec, &out.MetricsAutoscalerSpec
+		*out = new(MetricsAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScheduleAutoscalerSpec != nil {
+		in, out := &in.ScheduleAutoscalerSpec, &out.ScheduleAutoscalerSpec
+		*out = new(ScheduleAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BufferAutoscalerSpec != nil {
+		in, out := &in.BufferAutoscalerSpec, &out.BufferAutoscalerSpec
+		*out = new(BufferAutoscalerSpec)
+		**out = **in
+	}
+	if in.CompositeAutoscalerSpec != nil {
+		in, out := &in.CompositeAutoscalerSpec, &out.CompositeAutoscalerSpec
+		*out = new(CompositeAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PredictiveAutoscalerSpec != nil {
+		in, out := &in.PredictiveAutoscalerSpec, &out.PredictiveAutoscalerSpec
+		*out = new(PredictiveAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PushAutoscalerSpec != nil {
+		in, out := &in.PushAutoscalerSpec, &out.PushAutoscalerSpec
+		*out = new(PushAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalePolicy.
@@ -41,6 +72,285 @@ func (in *AutoscalePolicy) DeepCopy() *AutoscalePolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BufferAutoscalerSpec) DeepCopyInto(out *BufferAutoscalerSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BufferAutoscalerSpec.
+func (in *BufferAutoscalerSpec) DeepCopy() *BufferAutoscalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BufferAutoscalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityAllocation) DeepCopyInto(out *CapacityAllocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityAllocation.
+func (in *CapacityAllocation) DeepCopy() *CapacityAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityPool) DeepCopyInto(out *CapacityPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityPool.
+func (in *CapacityPool) DeepCopy() *CapacityPool {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacityPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityPoolList) DeepCopyInto(out *CapacityPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CapacityPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityPoolList.
+func (in *CapacityPoolList) DeepCopy() *CapacityPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacityPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityPoolSpec) DeepCopyInto(out *CapacityPoolSpec) {
+	*out = *in
+	in.GameTypeSelector.DeepCopyInto(&out.GameTypeSelector)
+	if in.MaxServers != nil {
+		in, out := &in.MaxServers, &out.MaxServers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxResources != nil {
+		in, out := &in.MaxResources, &out.MaxResources
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityPoolSpec.
+func (in *CapacityPoolSpec) DeepCopy() *CapacityPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityPoolStatus) DeepCopyInto(out *CapacityPoolStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.GameTypes != nil {
+		in, out := &in.GameTypes, &out.GameTypes
+		*out = make([]CapacityAllocation, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityPoolStatus.
+func (in *CapacityPoolStatus) DeepCopy() *CapacityPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeAutoscalerSpec) DeepCopyInto(out *CompositeAutoscalerSpec) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]CompositePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeAutoscalerSpec.
+func (in *CompositeAutoscalerSpec) DeepCopy() *CompositeAutoscalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeAutoscalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositePolicy) DeepCopyInto(out *CompositePolicy) {
+	*out = *in
+	if in.WebhookAutoscalerSpec != nil {
+		in, out := &in.WebhookAutoscalerSpec, &out.WebhookAutoscalerSpec
+		*out = new(WebhookAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricsAutoscalerSpec != nil {
+		in, out := &in.MetricsAutoscalerSpec, &out.MetricsAutoscalerSpec
+		*out = new(MetricsAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScheduleAutoscalerSpec != nil {
+		in, out := &in.ScheduleAutoscalerSpec, &out.ScheduleAutoscalerSpec
+		*out = new(ScheduleAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BufferAutoscalerSpec != nil {
+		in, out := &in.BufferAutoscalerSpec, &out.BufferAutoscalerSpec
+		*out = new(BufferAutoscalerSpec)
+		**out = **in
+	}
+	if in.PredictiveAutoscalerSpec != nil {
+		in, out := &in.PredictiveAutoscalerSpec, &out.PredictiveAutoscalerSpec
+		*out = new(PredictiveAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositePolicy.
+func (in *CompositePolicy) DeepCopy() *CompositePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DemandHistory) DeepCopyInto(out *DemandHistory) {
+	*out = *in
+	out.Interval = in.Interval
+	in.LastSampleTime.DeepCopyInto(&out.LastSampleTime)
+	if in.Samples != nil {
+		in, out := &in.Samples, &out.Samples
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Forecast != nil {
+		in, out := &in.Forecast, &out.Forecast
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DemandHistory.
+func (in *DemandHistory) DeepCopy() *DemandHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(DemandHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailurePolicy) DeepCopyInto(out *FailurePolicy) {
+	*out = *in
+	if in.FallbackReplicas != nil {
+		in, out := &in.FallbackReplicas, &out.FallbackReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FallbackSchedule != nil {
+		in, out := &in.FallbackSchedule, &out.FallbackSchedule
+		*out = new(ScheduleAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailurePolicy.
+func (in *FailurePolicy) DeepCopy() *FailurePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FailurePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Fleet) DeepCopyInto(out *Fleet) {
 	*out = *in
@@ -137,7 +447,7 @@ func (in *FleetStatus) DeepCopyInto(out *FleetStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -160,7 +470,7 @@ func (in *GameAutoscaler) DeepCopyInto(out *GameAutoscaler) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameAutoscaler.
@@ -216,8 +526,33 @@ func (in *GameAutoscalerList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GameAutoscalerSpec) DeepCopyInto(out *GameAutoscalerSpec) {
 	*out = *in
+	if in.TargetRef != nil {
+		in, out := &in.TargetRef, &out.TargetRef
+		*out = new(TargetRef)
+		**out = **in
+	}
 	in.AutoscalePolicy.DeepCopyInto(&out.AutoscalePolicy)
 	in.Sync.DeepCopyInto(&out.Sync)
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(ScalingBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailurePolicy != nil {
+		in, out := &in.FailurePolicy, &out.FailurePolicy
+		*out = new(FailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameAutoscalerSpec.
@@ -233,6 +568,69 @@ func (in *GameAutoscalerSpec) DeepCopy() *GameAutoscalerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GameAutoscalerStatus) DeepCopyInto(out *GameAutoscalerStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastEvaluationTime != nil {
+		in, out := &in.LastEvaluationTime, &out.LastEvaluationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastWebhookCallTime != nil {
+		in, out := &in.LastWebhookCallTime, &out.LastWebhookCallTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDesiredReplicas != nil {
+		in, out := &in.LastDesiredReplicas, &out.LastDesiredReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastScaleUpTime != nil {
+		in, out := &in.LastScaleUpTime, &out.LastScaleUpTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastScaleDownTime != nil {
+		in, out := &in.LastScaleDownTime, &out.LastScaleDownTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = make([]ScaleRecommendation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ScaleEvents != nil {
+		in, out := &in.ScaleEvents, &out.ScaleEvents
+		*out = make([]ScaleEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PolicyRecommendations != nil {
+		in, out := &in.PolicyRecommendations, &out.PolicyRecommendations
+		*out = make([]PolicyRecommendation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DemandHistory != nil {
+		in, out := &in.DemandHistory, &out.DemandHistory
+		*out = new(DemandHistory)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActivePush != nil {
+		in, out := &in.ActivePush, &out.ActivePush
+		*out = new(PushedReplicas)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameAutoscalerStatus.
@@ -272,6 +670,21 @@ func (in *GameType) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameTypeCapacity) DeepCopyInto(out *GameTypeCapacity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameTypeCapacity.
+func (in *GameTypeCapacity) DeepCopy() *GameTypeCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(GameTypeCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GameTypeList) DeepCopyInto(out *GameTypeList) {
 	*out = *in
@@ -308,6 +721,11 @@ func (in *GameTypeList) DeepCopyObject() runtime.Object {
 func (in *GameTypeSpec) DeepCopyInto(out *GameTypeSpec) {
 	*out = *in
 	in.FleetSpec.DeepCopyInto(&out.FleetSpec)
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(GameTypeCapacity)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameTypeSpec.
@@ -325,7 +743,7 @@ func (in *GameTypeStatus) DeepCopyInto(out *GameTypeStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -342,6 +760,265 @@ func (in *GameTypeStatus) DeepCopy() *GameTypeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsAutoscalerSpec) DeepCopyInto(out *MetricsAutoscalerSpec) {
+	*out = *in
+	out.TargetValue = in.TargetValue.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsAutoscalerSpec.
+func (in *MetricsAutoscalerSpec) DeepCopy() *MetricsAutoscalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsAutoscalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyRecommendation) DeepCopyInto(out *PolicyRecommendation) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyRecommendation.
+func (in *PolicyRecommendation) DeepCopy() *PolicyRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictiveAutoscalerSpec) DeepCopyInto(out *PredictiveAutoscalerSpec) {
+	*out = *in
+	if in.SampleInterval != nil {
+		in, out := &in.SampleInterval, &out.SampleInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Seasons != nil {
+		in, out := &in.Seasons, &out.Seasons
+		*out = make([]metav1.Duration, len(*in))
+		copy(*out, *in)
+	}
+	if in.LeadTime != nil {
+		in, out := &in.LeadTime, &out.LeadTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictiveAutoscalerSpec.
+func (in *PredictiveAutoscalerSpec) DeepCopy() *PredictiveAutoscalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictiveAutoscalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PushAutoscalerSpec) DeepCopyInto(out *PushAutoscalerSpec) {
+	*out = *in
+	if in.Base != nil {
+		in, out := &in.Base, &out.Base
+		*out = new(CompositePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxTTL != nil {
+		in, out := &in.MaxTTL, &out.MaxTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PushAutoscalerSpec.
+func (in *PushAutoscalerSpec) DeepCopy() *PushAutoscalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PushAutoscalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PushedReplicas) DeepCopyInto(out *PushedReplicas) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	in.ExpirationTime.DeepCopyInto(&out.ExpirationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PushedReplicas.
+func (in *PushedReplicas) DeepCopy() *PushedReplicas {
+	if in == nil {
+		return nil
+	}
+	out := new(PushedReplicas)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleEvent) DeepCopyInto(out *ScaleEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleEvent.
+func (in *ScaleEvent) DeepCopy() *ScaleEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleRecommendation) DeepCopyInto(out *ScaleRecommendation) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleRecommendation.
+func (in *ScaleRecommendation) DeepCopy() *ScaleRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingBehavior) DeepCopyInto(out *ScalingBehavior) {
+	*out = *in
+	if in.ScaleUp != nil {
+		in, out := &in.ScaleUp, &out.ScaleUp
+		*out = new(ScalingRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScaleDown != nil {
+		in, out := &in.ScaleDown, &out.ScaleDown
+		*out = new(ScalingRules)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingBehavior.
+func (in *ScalingBehavior) DeepCopy() *ScalingBehavior {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingBehavior)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPolicy) DeepCopyInto(out *ScalingPolicy) {
+	*out = *in
+	out.Period = in.Period
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPolicy.
+func (in *ScalingPolicy) DeepCopy() *ScalingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingRules) DeepCopyInto(out *ScalingRules) {
+	*out = *in
+	if in.StabilizationWindow != nil {
+		in, out := &in.StabilizationWindow, &out.StabilizationWindow
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]ScalingPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.Cooldown != nil {
+		in, out := &in.Cooldown, &out.Cooldown
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingRules.
+func (in *ScalingRules) DeepCopy() *ScalingRules {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleAutoscalerSpec) DeepCopyInto(out *ScheduleAutoscalerSpec) {
+	*out = *in
+	if in.DefaultReplicas != nil {
+		in, out := &in.DefaultReplicas, &out.DefaultReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]ScheduleWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleAutoscalerSpec.
+func (in *ScheduleAutoscalerSpec) DeepCopy() *ScheduleAutoscalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleAutoscalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleWindow) DeepCopyInto(out *ScheduleWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.DesiredReplicas != nil {
+		in, out := &in.DesiredReplicas, &out.DesiredReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleWindow.
+func (in *ScheduleWindow) DeepCopy() *ScheduleWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Server) DeepCopyInto(out *Server) {
 	*out = *in
@@ -407,7 +1084,12 @@ func (in *ServerSpec) DeepCopyInto(out *ServerSpec) {
 	in.Pod.DeepCopyInto(&out.Pod)
 	if in.TimeOut != nil {
 		in, out := &in.TimeOut, &out.TimeOut
-		*out = new(v1.Duration)
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ShutdownDelivery != nil {
+		in, out := &in.ShutdownDelivery, &out.ShutdownDelivery
+		*out = new(ShutdownDelivery)
 		**out = **in
 	}
 }
@@ -427,11 +1109,23 @@ func (in *ServerStatus) DeepCopyInto(out *ServerStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Counters != nil {
+		in, out := &in.Counters, &out.Counters
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Sidecar != nil {
+		in, out := &in.Sidecar, &out.Sidecar
+		*out = new(SidecarStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerStatus.
@@ -459,12 +1153,53 @@ func (in *Service) DeepCopy() *Service {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShutdownDelivery) DeepCopyInto(out *ShutdownDelivery) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShutdownDelivery.
+func (in *ShutdownDelivery) DeepCopy() *ShutdownDelivery {
+	if in == nil {
+		return nil
+	}
+	out := new(ShutdownDelivery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SidecarStatus) DeepCopyInto(out *SidecarStatus) {
+	*out = *in
+	in.UpdateTime.DeepCopyInto(&out.UpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarStatus.
+func (in *SidecarStatus) DeepCopy() *SidecarStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SidecarStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Sync) DeepCopyInto(out *Sync) {
 	*out = *in
 	if in.Time != nil {
 		in, out := &in.Time, &out.Time
-		*out = new(v1.Duration)
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Debounce != nil {
+		in, out := &in.Debounce, &out.Debounce
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MinInterval != nil {
+		in, out := &in.MinInterval, &out.MinInterval
+		*out = new(metav1.Duration)
 		**out = **in
 	}
 }
@@ -479,6 +1214,21 @@ func (in *Sync) DeepCopy() *Sync {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetRef) DeepCopyInto(out *TargetRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetRef.
+func (in *TargetRef) DeepCopy() *TargetRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebhookAutoscalerSpec) DeepCopyInto(out *WebhookAutoscalerSpec) {
 	*out = *in
@@ -492,11 +1242,31 @@ func (in *WebhookAutoscalerSpec) DeepCopyInto(out *WebhookAutoscalerSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ProtocolVersion != nil {
+		in, out := &in.ProtocolVersion, &out.ProtocolVersion
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Service != nil {
 		in, out := &in.Service, &out.Service
 		*out = new(Service)
 		**out = **in
 	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(WebhookSecretRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookAutoscalerSpec.
@@ -508,3 +1278,25 @@ func (in *WebhookAutoscalerSpec) DeepCopy() *WebhookAutoscalerSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSecretRef) DeepCopyInto(out *WebhookSecretRef) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSecretRef.
+func (in *WebhookSecretRef) DeepCopy() *WebhookSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
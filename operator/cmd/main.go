@@ -198,7 +198,8 @@ func main() {
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorderFor("gameautoscaler"),
-		Webhook:  utils.ProductionWebhookRequest{},
+		Webhook:  utils.ProductionWebhookRequest{Client: mgr.GetClient(), SecretReader: mgr.GetAPIReader()},
+		Metrics:  utils.ProductionMetricsQuery{},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GameAutoscaler")
 		os.Exit(1)
@@ -215,6 +216,20 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	if err = (&controller.CapacityPoolReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("capacitypool"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CapacityPool")
+		os.Exit(1)
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&networkv1alpha1.CapacityPool{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "CapacityPool")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,155 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"github.com/unfamousthomas/thesis-operator/internal/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"strings"
+	"time"
+)
+
+// capacityPoolSyncInterval is how often the pool status is refreshed with the servers of its GameTypes
+const capacityPoolSyncInterval = 30 * time.Second
+
+// CapacityPoolReconciler reconciles a CapacityPool object
+type CapacityPoolReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=capacitypools,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=capacitypools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=gametypes;fleets;servers,verbs=get;list;watch
+
+// Reconcile arbitrates the pool between its GameTypes and saves the share granted to each of them to the status.
+// The pool itself does not scale anything, the autoscalers and fleets limit their scale-ups with the same arbitration.
+func (r *CapacityPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	pool := &networkv1alpha1.CapacityPool{}
+	if err := r.Get(ctx, req.NamespacedName, pool); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	claims, err := utils.CollectCapacityClaims(ctx, r.Client, pool)
+	if err != nil {
+		r.emitEventf(pool, corev1.EventTypeWarning, utils.ReasonCapacityPoolFailed, "Failed to collect the gametypes: %s", err)
+		return ctrl.Result{}, err
+	}
+	granted := utils.ArbitrateCapacity(pool.Spec, claims)
+
+	previous := map[types.NamespacedName]networkv1alpha1.CapacityAllocation{}
+	for _, allocation := range pool.Status.GameTypes {
+		previous[types.NamespacedName{Namespace: allocation.Namespace, Name: allocation.Name}] = allocation
+	}
+	allocations := make([]networkv1alpha1.CapacityAllocation, 0, len(claims))
+	limited := make([]string, 0)
+	for i, claim := range claims {
+		allocation := networkv1alpha1.CapacityAllocation{
+			Namespace:          claim.Namespace,
+			Name:               claim.Name,
+			Priority:           claim.Priority,
+			GuaranteedReplicas: claim.GuaranteedReplicas,
+			Servers:            claim.Servers,
+			DesiredReplicas:    claim.Replicas,
+			GrantedReplicas:    granted[i],
+		}
+		if allocation.GrantedReplicas < allocation.DesiredReplicas {
+			limited = append(limited, claim.Namespace+"/"+claim.Name)
+			last, exists := previous[types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}]
+			if !exists || last.GrantedReplicas != allocation.GrantedReplicas || last.DesiredReplicas != allocation.DesiredReplicas {
+				r.emitEventf(pool, corev1.EventTypeNormal, utils.ReasonCapacityPoolLimited, "Limiting %s/%s to %d of %d replicas",
+					claim.Namespace, claim.Name, allocation.GrantedReplicas, allocation.DesiredReplicas)
+			}
+		}
+		allocations = append(allocations, allocation)
+	}
+
+	pool.Status.GameTypes = allocations
+	pool.Status.Servers, pool.Status.Requests = utils.UsedCapacity(claims)
+	pool.Status.LastSyncTime = &metav1.Time{Time: time.Now()}
+	condition := metav1.Condition{
+		Type:    networkv1alpha1.CapacityPoolLimited,
+		Status:  metav1.ConditionFalse,
+		Reason:  "WithinCapacity",
+		Message: "Every gametype is granted the replicas it wants",
+	}
+	if len(limited) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ScaleUpsLimited"
+		condition.Message = fmt.Sprintf("The pool limits the replicas of %s", strings.Join(limited, ", "))
+	}
+	meta.SetStatusCondition(&pool.Status.Conditions, condition)
+
+	if err := r.Status().Update(ctx, pool); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to update capacity pool status: %w", err)
+	}
+	return ctrl.Result{RequeueAfter: capacityPoolSyncInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Only spec changes of the pool trigger a sync, so writing the status does not cause a loop.
+// GameTypes are watched so spec and label changes show up in the pool right away.
+func (r *CapacityPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&networkv1alpha1.CapacityPool{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&networkv1alpha1.GameType{}, handler.EnqueueRequestsFromMapFunc(r.poolsForGameType)).
+		Complete(r)
+}
+
+// poolsForGameType finds the pools selecting the GameType
+func (r *CapacityPoolReconciler) poolsForGameType(ctx context.Context, object client.Object) []reconcile.Request {
+	pools := &networkv1alpha1.CapacityPoolList{}
+	if err := r.List(ctx, pools); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list capacity pools for gametype", "gametype", object.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	for _, pool := range pools.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&pool.Spec.GameTypeSelector)
+		if err != nil || !selector.Matches(labels.Set(object.GetLabels())) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: pool.Name}})
+	}
+	return requests
+}
+
+// emitEventf is used by the CapacityPoolReconciler to easily add events to objects with arguments
+func (r *CapacityPoolReconciler) emitEventf(object runtime.Object, eventtype string, reason utils.EventReason, message string, args ...interface{}) {
+	r.Recorder.Eventf(object, eventtype, string(reason), message, args...)
+}
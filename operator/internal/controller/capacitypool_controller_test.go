@@ -0,0 +1,183 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
+)
+
+var _ = Describe("CapacityPool Controller", func() {
+	Context("When reconciling a resource", Ordered, func() {
+		const poolName = "test-pool"
+
+		ctx := context.Background()
+		poolNamespacedName := types.NamespacedName{Name: poolName}
+		poolGametype := func(name string, replicas int32, capacity networkv1alpha1.GameTypeCapacity) *networkv1alpha1.GameType {
+			spec := *basicGametypeSpec.DeepCopy()
+			spec.FleetSpec.Scaling.Replicas = replicas
+			spec.Capacity = &capacity
+			return &networkv1alpha1.GameType{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{"pool": poolName},
+				},
+				Spec: spec,
+			}
+		}
+
+		BeforeAll(func() {
+			maxServers := int32(5)
+			pool := &networkv1alpha1.CapacityPool{
+				ObjectMeta: metav1.ObjectMeta{Name: poolName},
+				Spec: networkv1alpha1.CapacityPoolSpec{
+					GameTypeSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pool": poolName}},
+					MaxServers:       &maxServers,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pool)).To(Succeed())
+			Expect(k8sClient.Create(ctx, poolGametype("pool-low", 3, networkv1alpha1.GameTypeCapacity{GuaranteedReplicas: 2}))).To(Succeed())
+			Expect(k8sClient.Create(ctx, poolGametype("pool-high", 4, networkv1alpha1.GameTypeCapacity{Priority: 5}))).To(Succeed())
+		})
+
+		AfterAll(func() {
+			Expect(k8sClient.DeleteAllOf(ctx, &networkv1alpha1.Server{}, client.InNamespace(namespace),
+				client.MatchingLabels{"type": "pool-high"})).To(Succeed())
+			for _, name := range []string{"pool-low", "pool-high"} {
+				gametype := &networkv1alpha1.GameType{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, gametype)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, gametype)).To(Succeed())
+			}
+			pool := &networkv1alpha1.CapacityPool{}
+			Expect(k8sClient.Get(ctx, poolNamespacedName, pool)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, pool)).To(Succeed())
+		})
+
+		It("Should arbitrate the pool by priority and guarantees", func() {
+			recorder := NewFakeRecorder()
+			reconciler := &CapacityPoolReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+			res, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: poolNamespacedName})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(capacityPoolSyncInterval))
+
+			pool := &networkv1alpha1.CapacityPool{}
+			Expect(k8sClient.Get(ctx, poolNamespacedName, pool)).To(Succeed())
+			Expect(pool.Status.GameTypes).To(HaveLen(2))
+			allocations := map[string]networkv1alpha1.CapacityAllocation{}
+			for _, allocation := range pool.Status.GameTypes {
+				allocations[allocation.Name] = allocation
+			}
+			By("Holding back the guaranteed replicas of the lower priority")
+			Expect(allocations["pool-low"].GrantedReplicas).To(Equal(int32(2)))
+			Expect(allocations["pool-high"].DesiredReplicas).To(Equal(int32(4)))
+			Expect(allocations["pool-high"].GrantedReplicas).To(Equal(int32(3)))
+			Expect(meta.IsStatusConditionTrue(pool.Status.Conditions, networkv1alpha1.CapacityPoolLimited)).To(BeTrue())
+
+			messages := make([]string, 0, len(recorder.Events))
+			for _, event := range recorder.Events {
+				messages = append(messages, event.Message)
+			}
+			Expect(messages).To(ContainElement("Limiting default/pool-high to 3 of 4 replicas"))
+		})
+
+		It("Should limit the servers created by the fleet", func() {
+			fleet := &networkv1alpha1.Fleet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pool-high-fleet",
+					Namespace: namespace,
+					Labels:    map[string]string{"type": "pool-high"},
+				},
+				Spec: *basicFleetSpec.DeepCopy(),
+			}
+			fleet.Spec.Scaling.Replicas = 4
+			Expect(k8sClient.Create(ctx, fleet)).To(Succeed())
+			fleetNamespacedName := types.NamespacedName{Name: fleet.Name, Namespace: namespace}
+
+			recorder := NewFakeRecorder()
+			reconciler := &FleetReconciler{
+				Client:          k8sClient,
+				Scheme:          k8sClient.Scheme(),
+				Recorder:        recorder,
+				DeletionChecker: prodChecker,
+			}
+			By("Adding the finalizer and scaling up")
+			for range 2 {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: fleetNamespacedName})
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, fleetNamespacedName, fleet)).To(Succeed())
+			Expect(fleet.Status.CurrentReplicas).To(Equal(int32(3)))
+			condition := meta.FindStatusCondition(fleet.Status.Conditions, networkv1alpha1.FleetCapacityLimited)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(Equal("Capacity pool test-pool limits the fleet to 3 of 4 servers"))
+
+			By("Deleting the fleet")
+			Expect(k8sClient.Delete(ctx, fleet)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: fleetNamespacedName})
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, fleetNamespacedName, fleet))
+			}, time.Second*10, time.Millisecond*500).Should(BeTrue())
+		})
+
+		It("Should limit the scale-ups of the autoscaler", func() {
+			autoscaler := &networkv1alpha1.GameAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "pool-high-autoscaler", Namespace: namespace},
+				Spec:       *basicGameautoscaler.DeepCopy(),
+			}
+			autoscaler.Spec.GameName = "pool-high"
+			Expect(k8sClient.Create(ctx, autoscaler)).To(Succeed())
+			autoscalerNamespacedName := types.NamespacedName{Name: autoscaler.Name, Namespace: namespace}
+
+			reconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: NewFakeRecorder(),
+				Webhook:  &TestWebhook{Scale: true, Replicas: 6},
+			}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).ToNot(HaveOccurred())
+
+			gametype := &networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "pool-high", Namespace: namespace}, gametype)).To(Succeed())
+			Expect(gametype.Spec.FleetSpec.Scaling.Replicas).To(Equal(int32(4)))
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, autoscaler)).To(Succeed())
+			condition := meta.FindStatusCondition(autoscaler.Status.Conditions, networkv1alpha1.GameAutoscalerScalingLimited)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Reason).To(Equal("CapacityPoolLimited"))
+			Expect(condition.Message).To(Equal("The capacity pool test-pool limits the desired replica count of 6 to 4"))
+
+			Expect(k8sClient.Delete(ctx, autoscaler)).To(Succeed())
+		})
+	})
+})
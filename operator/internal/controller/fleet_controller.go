@@ -22,6 +22,8 @@ import (
 	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
 	"github.com/unfamousthomas/thesis-operator/internal/utils"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -43,6 +45,7 @@ type FleetReconciler struct {
 // +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=fleets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=fleets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=fleets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=capacitypools;gametypes,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -76,11 +79,18 @@ func (r *FleetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	if err := r.deleteCompletedServers(ctx, fleet); err != nil {
+		return ctrl.Result{Requeue: true}, err
+	}
+
 	servers, err := r.getServers(ctx, fleet)
 	if err != nil {
 		return ctrl.Result{Requeue: true}, err
 	}
 	fleet.Status.CurrentReplicas = int32(len(servers.Items))
+	if fleet.Spec.Scaling.Replicas <= fleet.Status.CurrentReplicas {
+		r.setCapacityCondition(fleet, "", fleet.Spec.Scaling.Replicas)
+	}
 	if fleet.Spec.Scaling.Replicas != fleet.Status.CurrentReplicas {
 		if err := r.scaleServerCount(ctx, fleet, req.Namespace); err != nil {
 			return ctrl.Result{}, err
@@ -111,8 +121,17 @@ func (r *FleetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 // It either adds more or remove some servers
 func (r *FleetReconciler) scaleServerCount(ctx context.Context, fleet *networkv1alpha1.Fleet, namespace string) error {
 	if fleet.Status.CurrentReplicas < fleet.Spec.Scaling.Replicas {
-		//Scale up
-		serversNeeded := fleet.Spec.Scaling.Replicas - fleet.Status.CurrentReplicas
+		//Scale up, as far as the capacity pools of the gametype allow
+		replicas, pool, err := utils.FleetCapacity(ctx, r.Client, fleet)
+		if err != nil {
+			r.emitEventf(fleet, corev1.EventTypeWarning, utils.ReasonFleetScaleServers, "Failed to check the capacity pools: %s", err)
+			return err
+		}
+		r.setCapacityCondition(fleet, pool, replicas)
+		serversNeeded := replicas - fleet.Status.CurrentReplicas
+		if serversNeeded <= 0 {
+			return nil
+		}
 		for range serversNeeded {
 			server := utils.CreateServerForFleet(*fleet, namespace)
 			err := r.Create(ctx, server)
@@ -121,7 +140,7 @@ func (r *FleetReconciler) scaleServerCount(ctx context.Context, fleet *networkv1
 				return err
 			}
 		}
-		r.emitEventf(fleet, corev1.EventTypeNormal, utils.ReasonFleetScaleServers, "Scaled servers up to %d", fleet.Spec.Scaling.Replicas)
+		r.emitEventf(fleet, corev1.EventTypeNormal, utils.ReasonFleetScaleServers, "Scaled servers up to %d", replicas)
 	}
 	//Scale down
 	if fleet.Status.CurrentReplicas > fleet.Spec.Scaling.Replicas {
@@ -142,9 +161,51 @@ func (r *FleetReconciler) scaleServerCount(ctx context.Context, fleet *networkv1
 	return nil
 }
 
-// getServers is used by the FleetReconciler to get all the servers associated with a fleet
-// Internally it just matches the fleet label in the same namespace
+// setCapacityCondition sets the CapacityLimited condition when a capacity pool limits the fleet, and clears it once none does.
+// Fleets that were never limited do not get the condition.
+func (r *FleetReconciler) setCapacityCondition(fleet *networkv1alpha1.Fleet, pool string, replicas int32) {
+	if pool == "" {
+		if meta.IsStatusConditionTrue(fleet.Status.Conditions, networkv1alpha1.FleetCapacityLimited) {
+			meta.SetStatusCondition(&fleet.Status.Conditions, metav1.Condition{
+				Type:    networkv1alpha1.FleetCapacityLimited,
+				Status:  metav1.ConditionFalse,
+				Reason:  "WithinCapacity",
+				Message: "The capacity pools allow every server of the fleet",
+			})
+		}
+		return
+	}
+	if !meta.IsStatusConditionTrue(fleet.Status.Conditions, networkv1alpha1.FleetCapacityLimited) {
+		r.emitEventf(fleet, corev1.EventTypeNormal, utils.ReasonFleetScaleServers, "Capacity pool %s limits the fleet to %d servers", pool, replicas)
+	}
+	meta.SetStatusCondition(&fleet.Status.Conditions, metav1.Condition{
+		Type:    networkv1alpha1.FleetCapacityLimited,
+		Status:  metav1.ConditionTrue,
+		Reason:  "CapacityPoolLimited",
+		Message: fmt.Sprintf("Capacity pool %s limits the fleet to %d of %d servers", pool, replicas, fleet.Spec.Scaling.Replicas),
+	})
+}
+
+// getServers is used by the FleetReconciler to get the servers associated with a fleet, leaving out completed OneShot servers.
+// Those are being replaced, so they do not count towards the replicas.
 func (r *FleetReconciler) getServers(ctx context.Context, fleet *networkv1alpha1.Fleet) (*networkv1alpha1.ServerList, error) {
+	serverList, err := r.getAllServers(ctx, fleet)
+	if err != nil {
+		return nil, err
+	}
+	active := serverList.Items[:0]
+	for _, server := range serverList.Items {
+		if !utils.IsServerCompleted(&server) {
+			active = append(active, server)
+		}
+	}
+	serverList.Items = active
+	return serverList, nil
+}
+
+// getAllServers is used by the FleetReconciler to get all the servers associated with a fleet
+// Internally it just matches the fleet label in the same namespace
+func (r *FleetReconciler) getAllServers(ctx context.Context, fleet *networkv1alpha1.Fleet) (*networkv1alpha1.ServerList, error) {
 	serverList := &networkv1alpha1.ServerList{}
 	labelSelector := client.MatchingLabels{"fleet": fleet.Name}
 	if err := r.List(ctx, serverList, client.InNamespace(fleet.Namespace), labelSelector); err != nil {
@@ -153,13 +214,32 @@ func (r *FleetReconciler) getServers(ctx context.Context, fleet *networkv1alpha1
 	return serverList, nil
 }
 
+// deleteCompletedServers deletes the OneShot servers of the fleet whose game exited, so they are replaced with fresh servers
+func (r *FleetReconciler) deleteCompletedServers(ctx context.Context, fleet *networkv1alpha1.Fleet) error {
+	servers, err := r.getAllServers(ctx, fleet)
+	if err != nil {
+		return err
+	}
+	for _, server := range servers.Items {
+		if !utils.IsServerCompleted(&server) || !server.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.Delete(ctx, &server); client.IgnoreNotFound(err) != nil {
+			r.emitEventf(fleet, corev1.EventTypeWarning, utils.ReasonFleetReplaceServers, "Failed to delete completed server %s: %s", server.Name, err)
+			return err
+		}
+		r.emitEventf(fleet, corev1.EventTypeNormal, utils.ReasonFleetReplaceServers, "Replacing completed server %s", server.Name)
+	}
+	return nil
+}
+
 // handleDeletion is used by the FleetReconciler to handle deletion.
 // Internally, it first getts all the associated servers, then triggers them for deletion.
 // It requeues the reconcilation, until the amount of servers is 0.
 // Once it is 0, it removes the finalizer.
 func (r *FleetReconciler) handleDeletion(ctx context.Context, fleet *networkv1alpha1.Fleet) error {
 	//Gets the fleet-connected servers
-	servers, err := r.getServers(ctx, fleet)
+	servers, err := r.getAllServers(ctx, fleet)
 	if err != nil {
 		return err
 	}
@@ -169,7 +249,7 @@ func (r *FleetReconciler) handleDeletion(ctx context.Context, fleet *networkv1al
 		}
 	}
 	//Get them again to check if any were deleted already
-	servers, err = r.getServers(ctx, fleet)
+	servers, err = r.getAllServers(ctx, fleet)
 	if err != nil {
 		return err
 	}
@@ -24,6 +24,7 @@ import (
 	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
 	"github.com/unfamousthomas/thesis-operator/internal/utils"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -267,6 +268,57 @@ var _ = Describe("Fleet Controller", func() {
 
 		})
 
+		It("should replace completed OneShot servers", func() {
+			recorder := NewFakeRecorder()
+			reconciler := &FleetReconciler{
+				Client:          k8sClient,
+				Scheme:          k8sClient.Scheme(),
+				Recorder:        recorder,
+				DeletionChecker: prodChecker,
+			}
+			for range 2 {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			serverList := &networkv1alpha1.ServerList{}
+			Expect(k8sClient.List(ctx, serverList)).To(Succeed())
+			Expect(serverList.Items).To(HaveLen(int(basicFleetSpec.Scaling.Replicas)))
+
+			By("Completing one of the servers")
+			completed := serverList.Items[0]
+			meta.SetStatusCondition(&completed.Status.Conditions, metav1.Condition{
+				Type:    networkv1alpha1.ServerCompleted,
+				Status:  metav1.ConditionTrue,
+				Reason:  "GameExited",
+				Message: "The game exited",
+			})
+			Expect(k8sClient.Status().Update(ctx, &completed)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() []string {
+				serverList := &networkv1alpha1.ServerList{}
+				if err := k8sClient.List(ctx, serverList); err != nil {
+					return nil
+				}
+				var names []string
+				for _, server := range serverList.Items {
+					names = append(names, server.Name)
+				}
+				return names
+			}, time.Second*10, time.Millisecond*500).Should(And(HaveLen(int(basicFleetSpec.Scaling.Replicas)), Not(ContainElement(completed.Name))))
+
+			replacedEvent := false
+			for _, event := range recorder.Events {
+				if event.Message == "Replacing completed server "+completed.Name {
+					replacedEvent = true
+				}
+			}
+			Expect(replacedEvent).To(BeTrue())
+		})
+
 		It("should delete all servers when fleet is deleted", func() {
 			reconciler := &FleetReconciler{
 				Client:          k8sClient,
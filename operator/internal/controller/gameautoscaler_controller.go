@@ -21,8 +21,18 @@ import (
 	"fmt"
 	"github.com/unfamousthomas/thesis-operator/internal/utils"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -38,12 +48,20 @@ type GameAutoscalerReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Webhook  utils.Webhook
+	Metrics  utils.Metrics
 	Recorder record.EventRecorder
 }
 
+const (
+	defaultEventDebounce    = 2 * time.Second
+	defaultEventMinInterval = 10 * time.Second
+)
+
 // +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=gameautoscalers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=gameautoscalers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=gameautoscalers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=network.unfamousthomas.me,resources=fleets;servers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -55,68 +73,571 @@ func (r *GameAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	autoscaler := &networkv1alpha1.GameAutoscaler{}
 	if err := r.Get(ctx, req.NamespacedName, autoscaler); err != nil {
+		if apierrors.IsNotFound(err) {
+			//The autoscaler was deleted, so only its dry run gauge is left to clean up
+			clearDryRunReplicas(req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
 		logger.Error(err, "Failed to get autoscaler resource")
 		return ctrl.Result{Requeue: true}, err
 	}
 
-	gametype := &networkv1alpha1.GameType{}
-	namespacedGametype := types.NamespacedName{
-		Name:      autoscaler.Spec.GameName,
-		Namespace: autoscaler.Namespace,
-	}
-	if err := r.Get(ctx, namespacedGametype, gametype); err != nil {
-		r.emitEvent(autoscaler, corev1.EventTypeWarning, utils.ReasonGameAutoscalerInvalidServer, "Failed to find the gametype")
+	target, err := r.getTarget(ctx, autoscaler)
+	if err != nil {
+		r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameAutoscalerInvalidServer, "Failed to find the %s", strings.ToLower(string(autoscaler.Spec.Target().Kind)))
+		r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerAbleToScale, "TargetNotFound", err)
 		return ctrl.Result{Requeue: true}, err
 	}
 
-	//Make sure the type is fine
-	if autoscaler.Spec.AutoscalePolicy.Type != networkv1alpha1.Webhook {
-		r.emitEvent(autoscaler, corev1.EventTypeWarning, utils.ReasonGameAutoscalerInvalidAutoscalePolicy,
-			"invalid game autoscaler policy type")
-		return ctrl.Result{}, fmt.Errorf("%s is not a valid policy type", autoscaler.Spec.AutoscalePolicy.Type)
+	now := time.Now()
+	currentReplicas := target.GetReplicas()
+	schedule := autoscaler.Spec.AutoscalePolicy.ScheduleAutoscalerSpec
+
+	//Syncs triggered by events are limited by the minimum interval, the rest are delayed until it has passed
+	if autoscaler.Spec.Sync.Type == networkv1alpha1.Event {
+		if wait := untilNextEvaluation(autoscaler, now); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
 	}
+	autoscaler.Status.LastEvaluationTime = &metav1.Time{Time: now}
 
-	//Send request to defined webhook
-	result, err := r.Webhook.SendScaleWebhookRequest(autoscaler, gametype)
+	//Evaluate the policy based on its type
+	autoscaler.Status.PolicyRecommendations = nil
+	result, reason, err := r.evaluatePolicy(ctx, autoscaler, autoscaler.Spec.AutoscalePolicy, target, now)
 	if err != nil {
-		r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerWebhook, "failed to send the webhook request: %v", err)
-		return ctrl.Result{RequeueAfter: time.Minute}, fmt.Errorf("failed to send scale webhook request: %w", err)
+		switch reason {
+		case "WebhookFailed", "MetricsFailed", "BufferFailed", "PredictiveFailed", "CompositeFailed":
+			return r.handlePolicyFailure(ctx, autoscaler, target, reason, err, now)
+		}
+		r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerActive, reason, err)
+		return ctrl.Result{}, err
+	}
+
+	//With other policy types, the schedule acts as the floor
+	if autoscaler.Spec.AutoscalePolicy.Type != networkv1alpha1.Schedule && schedule != nil {
+		result, err = utils.ApplyScheduleFloor(result, schedule, currentReplicas, now)
+		if err != nil {
+			r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerSchedule, "failed to evaluate the schedule: %v", err)
+			r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerActive, "ScheduleFailed", err)
+			return ctrl.Result{}, fmt.Errorf("failed to evaluate schedule: %w", err)
+		}
 	}
 
 	//Check that the sync type is fine
-	if autoscaler.Spec.Sync.Type != networkv1alpha1.FixedInterval {
+	if autoscaler.Spec.Sync.Type != networkv1alpha1.FixedInterval && autoscaler.Spec.Sync.Type != networkv1alpha1.Event {
 		r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameAutoscalerInvalidSyncType, "%s is not a valid sync type", autoscaler.Spec.Sync.Type)
-		return ctrl.Result{}, fmt.Errorf("%s is not a valid sync type, currently only fixed interval is supported", autoscaler.Spec.Sync.Type)
+		err = fmt.Errorf("%s is not a valid sync type, only fixed interval and event are supported", autoscaler.Spec.Sync.Type)
+		r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerActive, "InvalidSyncType", err)
+		return ctrl.Result{}, err
 	}
 
+	//Smooth the recommendation with the behavior of the autoscaler
+	recommended := currentReplicas
+	if result.Scale {
+		recommended = int32(result.DesiredReplicas)
+	}
+	stabilized, behaviorReason := utils.StabilizeReplicas(autoscaler.Spec.Behavior, &autoscaler.Status, currentReplicas, recommended, now)
+	result = utils.AutoscaleResponse{
+		Scale:           stabilized != currentReplicas,
+		DesiredReplicas: int(stabilized),
+	}
+
+	//Keep the replicas within the limits of the autoscaler and the capacity pools of the game
+	result = limitReplicas(autoscaler, result, currentReplicas)
+	result = r.limitCapacity(ctx, autoscaler, target, result, currentReplicas)
+	if behaviorReason != "" && !meta.IsStatusConditionTrue(autoscaler.Status.Conditions, networkv1alpha1.GameAutoscalerScalingLimited) {
+		setCondition(autoscaler, networkv1alpha1.GameAutoscalerScalingLimited, metav1.ConditionTrue, behaviorReason,
+			fmt.Sprintf("The behavior changed the recommended replica count of %d to %d", recommended, stabilized))
+	}
+	desiredReplicas := int32(result.DesiredReplicas)
+	autoscaler.Status.LastDesiredReplicas = &desiredReplicas
+	setCondition(autoscaler, networkv1alpha1.GameAutoscalerActive, metav1.ConditionTrue, "PolicyEvaluated", "The policy was evaluated successfully")
+	if meta.IsStatusConditionTrue(autoscaler.Status.Conditions, networkv1alpha1.GameAutoscalerDegraded) {
+		r.emitEvent(autoscaler, corev1.EventTypeNormal, utils.ReasonGameautoscalerDegraded, "The policy recovered")
+		setCondition(autoscaler, networkv1alpha1.GameAutoscalerDegraded, metav1.ConditionFalse, "PolicyRecovered", "The policy recovered from its failures")
+	} else if autoscaler.Spec.FailurePolicy != nil {
+		setCondition(autoscaler, networkv1alpha1.GameAutoscalerDegraded, metav1.ConditionFalse, "PolicyHealthy", "The policy is evaluated successfully")
+	}
+
+	//In dry run mode, only record what would have been done
+	if autoscaler.Spec.DryRun {
+		setDryRunReplicas(autoscaler.Namespace, autoscaler.Name, target.GetName(), desiredReplicas)
+		if result.Scale {
+			r.emitEventf(autoscaler, corev1.EventTypeNormal, utils.ReasonGameautoscalerDryRun, "Dry run, would scale game to %d", result.DesiredReplicas)
+		}
+		setCondition(autoscaler, networkv1alpha1.GameAutoscalerAbleToScale, metav1.ConditionTrue, "DryRun",
+			fmt.Sprintf("Dry run, the game would have %d replicas", result.DesiredReplicas))
+		r.recordSuccess(ctx, autoscaler)
+		return ctrl.Result{
+			RequeueAfter: requeueAfter(autoscaler, now),
+		}, nil
+	}
+	clearDryRunReplicas(autoscaler.Namespace, autoscaler.Name)
+
 	//If scaleing not requested, requeue
 	if !result.Scale {
+		setCondition(autoscaler, networkv1alpha1.GameAutoscalerAbleToScale, metav1.ConditionTrue, "ReadyForNewScale", "The game has the desired replica count")
+		r.recordSuccess(ctx, autoscaler)
 		return ctrl.Result{
-			RequeueAfter: autoscaler.Spec.Sync.Time.Duration,
+			RequeueAfter: requeueAfter(autoscaler, now),
 		}, nil
 	}
 
 	//Otherwise, scale to new replica count
-	gametype.Spec.FleetSpec.Scaling.Replicas = int32(result.DesiredReplicas)
-	if err := r.Client.Update(ctx, gametype); err != nil {
-		r.emitEvent(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerScale, "failed to update the gametype")
-		return ctrl.Result{}, fmt.Errorf("failed to update gametype with new replica count: %w", err)
+	target.SetReplicas(desiredReplicas)
+	if err := r.Client.Update(ctx, target); err != nil {
+		r.emitEvent(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerScale, "failed to update the target")
+		r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerAbleToScale, "FailedUpdateTarget", err)
+		return ctrl.Result{}, fmt.Errorf("failed to update target with new replica count: %w", err)
 	}
 	r.emitEventf(autoscaler, corev1.EventTypeNormal, utils.ReasonGameautoscalerScale, "Scaling game to %d", result.DesiredReplicas)
+	utils.RecordScaleEvent(autoscaler.Spec.Behavior, &autoscaler.Status, desiredReplicas-currentReplicas, now)
+	autoscaler.Status.LastScaleTime = &metav1.Time{Time: now}
+	setCondition(autoscaler, networkv1alpha1.GameAutoscalerAbleToScale, metav1.ConditionTrue, "SucceededRescale", fmt.Sprintf("Scaled the game to %d", result.DesiredReplicas))
+	r.recordSuccess(ctx, autoscaler)
 
 	//Requeue after the defined time
 	return ctrl.Result{
-		RequeueAfter: autoscaler.Spec.Sync.Time.Duration,
+		RequeueAfter: requeueAfter(autoscaler, now),
 	}, nil
 }
 
+// getTarget gets the GameType or Fleet scaled by the autoscaler
+func (r *GameAutoscalerReconciler) getTarget(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler) (utils.ScaleTarget, error) {
+	targetRef := autoscaler.Spec.Target()
+	var target utils.ScaleTarget
+	switch targetRef.Kind {
+	case networkv1alpha1.FleetTarget:
+		target = &networkv1alpha1.Fleet{}
+	case networkv1alpha1.GameTypeTarget:
+		target = &networkv1alpha1.GameType{}
+	default:
+		return nil, fmt.Errorf("%s is not a valid target kind", targetRef.Kind)
+	}
+	key := types.NamespacedName{
+		Name:      targetRef.Name,
+		Namespace: autoscaler.Namespace,
+	}
+	if err := r.Get(ctx, key, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// evaluatePolicy evaluates a single policy against the target.
+// On failure, the event is emitted and the reason for the Active condition is returned along with the error.
+func (r *GameAutoscalerReconciler) evaluatePolicy(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler,
+	policy networkv1alpha1.AutoscalePolicy, target utils.ScaleTarget, now time.Time) (utils.AutoscaleResponse, string, error) {
+	currentReplicas := target.GetReplicas()
+	//The webhook and metrics read the policy from the autoscaler, so they get a copy using this policy
+	scoped := autoscaler.DeepCopy()
+	scoped.Spec.AutoscalePolicy = policy
+
+	switch policy.Type {
+	case networkv1alpha1.Webhook:
+		//Send request to defined webhook
+		result, err := r.Webhook.SendScaleWebhookRequest(scoped, target)
+		if err != nil {
+			r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerWebhook, "failed to send the webhook request: %v", err)
+			return result, "WebhookFailed", fmt.Errorf("failed to send scale webhook request: %w", err)
+		}
+		autoscaler.Status.LastWebhookCallTime = &metav1.Time{Time: now}
+		autoscaler.Status.WebhookProtocolVersion = int32(max(result.ProtocolVersion, 1))
+		return result, "", nil
+	case networkv1alpha1.Metrics:
+		//Query the defined metrics endpoint
+		result, err := r.Metrics.QueryDesiredReplicas(ctx, scoped, target)
+		if err != nil {
+			r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerMetrics, "failed to query metrics: %v", err)
+			return result, "MetricsFailed", fmt.Errorf("failed to query metrics: %w", err)
+		}
+		return result, "", nil
+	case networkv1alpha1.Schedule:
+		//Use the replicas of the currently active windows
+		result, err := utils.ScheduleResponse(policy.ScheduleAutoscalerSpec, currentReplicas, now)
+		if err != nil {
+			r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerSchedule, "failed to evaluate the schedule: %v", err)
+			return result, "ScheduleFailed", fmt.Errorf("failed to evaluate schedule: %w", err)
+		}
+		return result, "", nil
+	case networkv1alpha1.Buffer:
+		//Keep the buffer of unallocated servers
+		result, err := utils.BufferResponse(ctx, r.Client, policy.BufferAutoscalerSpec, target)
+		if err != nil {
+			r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerBuffer, "failed to evaluate the buffer: %v", err)
+			return result, "BufferFailed", fmt.Errorf("failed to evaluate buffer: %w", err)
+		}
+		return result, "", nil
+	case networkv1alpha1.Predictive:
+		//Record the demand and scale ahead of its forecast
+		result, err := utils.PredictiveResponse(ctx, r.Client, policy.PredictiveAutoscalerSpec, &autoscaler.Status, target, now)
+		if err != nil {
+			r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerPredictive, "failed to forecast the demand: %v", err)
+			return result, "PredictiveFailed", fmt.Errorf("failed to forecast demand: %w", err)
+		}
+		return result, "", nil
+	case networkv1alpha1.Push:
+		//Use the pushed replicas until they expire, the base policy otherwise
+		return r.evaluatePush(ctx, autoscaler, policy.PushAutoscalerSpec, target, now)
+	case networkv1alpha1.Composite:
+		//Combine the recommendations of every policy
+		result, err := r.evaluateComposite(ctx, autoscaler, policy.CompositeAutoscalerSpec, target, now)
+		if err != nil {
+			r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerComposite, "failed to evaluate the composite policy: %v", err)
+			return result, "CompositeFailed", fmt.Errorf("failed to evaluate composite policy: %w", err)
+		}
+		return result, "", nil
+	default:
+		r.emitEvent(autoscaler, corev1.EventTypeWarning, utils.ReasonGameAutoscalerInvalidAutoscalePolicy,
+			"invalid game autoscaler policy type")
+		return utils.AutoscaleResponse{}, "InvalidPolicyType", fmt.Errorf("%s is not a valid policy type", policy.Type)
+	}
+}
+
+// evaluateComposite evaluates the policies of the composite policy and combines their recommendations.
+// The recommendation of every evaluated policy is saved to the status.
+// With the firstsuccessful combinator, the policies after the first successful one are not evaluated.
+func (r *GameAutoscalerReconciler) evaluateComposite(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler,
+	composite *networkv1alpha1.CompositeAutoscalerSpec, target utils.ScaleTarget, now time.Time) (utils.AutoscaleResponse, error) {
+	if composite == nil {
+		return utils.AutoscaleResponse{}, fmt.Errorf("composite policy requires the composite field")
+	}
+	currentReplicas := target.GetReplicas()
+
+	recommendations := make([]networkv1alpha1.PolicyRecommendation, 0, len(composite.Policies))
+	for _, policy := range composite.Policies {
+		recommendation := networkv1alpha1.PolicyRecommendation{
+			Name: policy.Name,
+			Type: policy.Type,
+		}
+		result, _, err := r.evaluatePolicy(ctx, autoscaler, policy.AutoscalePolicy(), target, now)
+		if err != nil {
+			recommendation.Error = err.Error()
+		} else {
+			replicas := currentReplicas
+			if result.Scale {
+				replicas = int32(result.DesiredReplicas)
+			}
+			recommendation.Replicas = &replicas
+		}
+		recommendations = append(recommendations, recommendation)
+
+		if err == nil && composite.Combinator == networkv1alpha1.FirstSuccessfulCompositeCombinator {
+			break
+		}
+	}
+
+	result, err := utils.CombineRecommendations(composite.Combinator, recommendations, currentReplicas)
+	autoscaler.Status.PolicyRecommendations = recommendations
+	return result, err
+}
+
+// evaluatePush scales to the replicas pushed in the annotation of the autoscaler while they have not expired,
+// and evaluates the base policy otherwise. The base policy fails with its own reason, so the failure policy still applies.
+func (r *GameAutoscalerReconciler) evaluatePush(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler,
+	push *networkv1alpha1.PushAutoscalerSpec, target utils.ScaleTarget, now time.Time) (utils.AutoscaleResponse, string, error) {
+	currentReplicas := target.GetReplicas()
+	if push == nil {
+		r.emitEvent(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerPush, "push policy requires the push field")
+		return utils.AutoscaleResponse{}, "PushFailed", fmt.Errorf("push policy requires the push field")
+	}
+
+	pushed, err := autoscaler.PushedReplicas()
+	if err != nil {
+		r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerPush, "ignoring the pushed replicas: %v", err)
+	}
+	if pushed != nil && utils.PushActive(push, pushed, now) {
+		if autoscaler.Status.ActivePush == nil || !autoscaler.Status.ActivePush.Time.Equal(&pushed.Time) {
+			r.emitEventf(autoscaler, corev1.EventTypeNormal, utils.ReasonGameautoscalerPush, "Applying %d pushed replicas: %s", pushed.Replicas, pushed.Reason)
+		}
+		autoscaler.Status.ActivePush = pushed
+		return utils.AutoscaleResponse{
+			Scale:           pushed.Replicas != currentReplicas,
+			DesiredReplicas: int(pushed.Replicas),
+		}, "", nil
+	}
+	if autoscaler.Status.ActivePush != nil {
+		r.emitEvent(autoscaler, corev1.EventTypeNormal, utils.ReasonGameautoscalerPush, "The pushed replicas expired, reverting to the base policy")
+		autoscaler.Status.ActivePush = nil
+	}
+
+	if push.Base == nil {
+		return utils.AutoscaleResponse{DesiredReplicas: int(currentReplicas)}, "", nil
+	}
+	return r.evaluatePolicy(ctx, autoscaler, push.Base.AutoscalePolicy(), target, now)
+}
+
+// handlePolicyFailure records a failed webhook or metrics evaluation and applies the failure policy.
+// Without a failure policy the replicas are held and the error is returned, so the sync is retried after a minute.
+// With one, the sync is retried with a backoff, and once the threshold is reached the autoscaler is degraded and
+// either holds the replicas or scales to the fallback replicas.
+func (r *GameAutoscalerReconciler) handlePolicyFailure(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler,
+	target utils.ScaleTarget, reason string, err error, now time.Time) (ctrl.Result, error) {
+	policy := autoscaler.Spec.FailurePolicy
+	if policy == nil {
+		r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerActive, reason, err)
+		return ctrl.Result{RequeueAfter: time.Minute}, err
+	}
+
+	failures := autoscaler.Status.ConsecutiveFailures + 1
+	backoff := ctrl.Result{RequeueAfter: utils.FailureBackoff(policy, failures)}
+	if failures < utils.FailureThreshold(policy) {
+		r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerActive, reason, err)
+		return backoff, nil
+	}
+
+	if policy.Type != networkv1alpha1.FallbackFailurePolicy {
+		if !meta.IsStatusConditionTrue(autoscaler.Status.Conditions, networkv1alpha1.GameAutoscalerDegraded) {
+			r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerDegraded, "Holding the replicas after %d failures", failures)
+		}
+		setCondition(autoscaler, networkv1alpha1.GameAutoscalerDegraded, metav1.ConditionTrue, "HoldingReplicas",
+			fmt.Sprintf("Holding the replicas after %d failures", failures))
+		r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerActive, reason, err)
+		return backoff, nil
+	}
+
+	currentReplicas := target.GetReplicas()
+	fallback, fallbackErr := utils.FallbackReplicas(policy, currentReplicas, now)
+	if fallbackErr != nil {
+		r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerDegraded, "failed to get the fallback replicas: %v", fallbackErr)
+		r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerActive, reason, err)
+		return backoff, nil
+	}
+	result := limitReplicas(autoscaler, utils.AutoscaleResponse{Scale: true, DesiredReplicas: int(fallback)}, currentReplicas)
+	result = r.limitCapacity(ctx, autoscaler, target, result, currentReplicas)
+	desiredReplicas := int32(result.DesiredReplicas)
+	setCondition(autoscaler, networkv1alpha1.GameAutoscalerDegraded, metav1.ConditionTrue, "FallbackReplicas",
+		fmt.Sprintf("Using %d fallback replicas after %d failures", desiredReplicas, failures))
+	if result.Scale && autoscaler.Spec.DryRun {
+		setDryRunReplicas(autoscaler.Namespace, autoscaler.Name, target.GetName(), desiredReplicas)
+		r.emitEventf(autoscaler, corev1.EventTypeNormal, utils.ReasonGameautoscalerDryRun, "Dry run, would fall back to %d replicas after %d failures", desiredReplicas, failures)
+		autoscaler.Status.LastDesiredReplicas = &desiredReplicas
+	} else if result.Scale {
+		target.SetReplicas(desiredReplicas)
+		if updateErr := r.Client.Update(ctx, target); updateErr != nil {
+			r.emitEvent(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerScale, "failed to update the target")
+			r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerAbleToScale, "FailedUpdateTarget", updateErr)
+			return ctrl.Result{}, fmt.Errorf("failed to update target with fallback replica count: %w", updateErr)
+		}
+		r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerDegraded, "Falling back to %d replicas after %d failures", desiredReplicas, failures)
+		utils.RecordScaleEvent(autoscaler.Spec.Behavior, &autoscaler.Status, desiredReplicas-currentReplicas, now)
+		autoscaler.Status.LastScaleTime = &metav1.Time{Time: now}
+		autoscaler.Status.LastDesiredReplicas = &desiredReplicas
+	}
+	r.recordFailure(ctx, autoscaler, networkv1alpha1.GameAutoscalerActive, reason, err)
+	return backoff, nil
+}
+
+// requeueAfter returns the sync interval, unless a schedule window starts or ends or a push expires before that
+func requeueAfter(autoscaler *networkv1alpha1.GameAutoscaler, now time.Time) time.Duration {
+	interval := autoscaler.Spec.Sync.Time.Duration
+	//Pushed replicas are reverted as soon as they expire
+	if push := autoscaler.Spec.AutoscalePolicy.PushAutoscalerSpec; push != nil && autoscaler.Status.ActivePush != nil {
+		if untilExpiration := utils.PushExpiration(push, autoscaler.Status.ActivePush).Sub(now); untilExpiration > 0 && untilExpiration < interval {
+			interval = untilExpiration
+		}
+	}
+	schedule := autoscaler.Spec.AutoscalePolicy.ScheduleAutoscalerSpec
+	if schedule == nil {
+		return interval
+	}
+	next, err := utils.NextScheduleChange(schedule, now)
+	if err != nil || next.IsZero() {
+		return interval
+	}
+	if untilNext := next.Sub(now); untilNext > 0 && untilNext < interval {
+		return untilNext
+	}
+	return interval
+}
+
+// untilNextEvaluation returns how long to wait before the autoscaler can be evaluated again
+func untilNextEvaluation(autoscaler *networkv1alpha1.GameAutoscaler, now time.Time) time.Duration {
+	if autoscaler.Status.LastEvaluationTime == nil {
+		return 0
+	}
+	minInterval := defaultEventMinInterval
+	if autoscaler.Spec.Sync.MinInterval != nil {
+		minInterval = autoscaler.Spec.Sync.MinInterval.Duration
+	}
+	return autoscaler.Status.LastEvaluationTime.Add(minInterval).Sub(now)
+}
+
+// limitReplicas keeps the response within the min and max replicas of the autoscaler and updates the ScalingLimited condition
+func limitReplicas(autoscaler *networkv1alpha1.GameAutoscaler, response utils.AutoscaleResponse, current int32) utils.AutoscaleResponse {
+	target := int(current)
+	if response.Scale {
+		target = response.DesiredReplicas
+	}
+
+	switch {
+	case autoscaler.Spec.MinReplicas != nil && target < int(*autoscaler.Spec.MinReplicas):
+		target = int(*autoscaler.Spec.MinReplicas)
+		setCondition(autoscaler, networkv1alpha1.GameAutoscalerScalingLimited, metav1.ConditionTrue, "TooFewReplicas",
+			fmt.Sprintf("The desired replica count is below the minimum of %d", target))
+	case autoscaler.Spec.MaxReplicas != nil && target > int(*autoscaler.Spec.MaxReplicas):
+		target = int(*autoscaler.Spec.MaxReplicas)
+		setCondition(autoscaler, networkv1alpha1.GameAutoscalerScalingLimited, metav1.ConditionTrue, "TooManyReplicas",
+			fmt.Sprintf("The desired replica count is above the maximum of %d", target))
+	default:
+		setCondition(autoscaler, networkv1alpha1.GameAutoscalerScalingLimited, metav1.ConditionFalse, "DesiredWithinRange",
+			"The desired replica count is within the limits")
+	}
+
+	return utils.AutoscaleResponse{
+		Scale:           target != int(current),
+		DesiredReplicas: target,
+	}
+}
+
+// limitCapacity keeps scale-ups of a GameType within the replicas granted by the capacity pools selecting it.
+// If the pools cannot be read the response is kept, the fleets still limit their servers to the pools.
+func (r *GameAutoscalerReconciler) limitCapacity(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler,
+	target utils.ScaleTarget, response utils.AutoscaleResponse, current int32) utils.AutoscaleResponse {
+	gametype, ok := target.(*networkv1alpha1.GameType)
+	if !ok || response.DesiredReplicas <= int(current) {
+		return response
+	}
+	granted, pool, err := utils.GrantedReplicas(ctx, r.Client, gametype, int32(response.DesiredReplicas))
+	if err != nil {
+		r.emitEventf(autoscaler, corev1.EventTypeWarning, utils.ReasonGameautoscalerCapacity, "failed to check the capacity pools: %v", err)
+		return response
+	}
+	if pool == "" {
+		return response
+	}
+	desired := max(granted, current)
+	setCondition(autoscaler, networkv1alpha1.GameAutoscalerScalingLimited, metav1.ConditionTrue, "CapacityPoolLimited",
+		fmt.Sprintf("The capacity pool %s limits the desired replica count of %d to %d", pool, response.DesiredReplicas, desired))
+	return utils.AutoscaleResponse{
+		Scale:           desired != current,
+		DesiredReplicas: int(desired),
+	}
+}
+
+// setCondition sets the condition on the autoscaler, the transition time only changes with the status
+func setCondition(autoscaler *networkv1alpha1.GameAutoscaler, conditionType string, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&autoscaler.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// recordSuccess resets the failure tracking and saves the status
+func (r *GameAutoscalerReconciler) recordSuccess(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler) {
+	autoscaler.Status.ConsecutiveFailures = 0
+	autoscaler.Status.LastError = ""
+	r.updateStatus(ctx, autoscaler)
+}
+
+// recordFailure saves the error to the status and marks the given condition as false
+func (r *GameAutoscalerReconciler) recordFailure(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler, conditionType string, reason string, err error) {
+	autoscaler.Status.ConsecutiveFailures++
+	autoscaler.Status.LastError = err.Error()
+	setCondition(autoscaler, conditionType, metav1.ConditionFalse, reason, err.Error())
+	r.updateStatus(ctx, autoscaler)
+}
+
+// updateStatus saves the status of the autoscaler.
+// Failing to do so is only logged, as the next sync will write the status again.
+func (r *GameAutoscalerReconciler) updateStatus(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler) {
+	if err := r.Status().Update(ctx, autoscaler); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update autoscaler status", "autoscaler", autoscaler.Name)
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
+// Only spec and annotation changes of the autoscaler trigger a sync, so writing the status does not cause a loop
+// and pushed replicas are applied right away.
+// Fleets, servers and pods are watched so the event sync type can react to changes in the target.
 func (r *GameAutoscalerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	hasTarget := builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetLabels()["type"] != "" || eventFleetName(object) != ""
+	}))
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&networkv1alpha1.GameAutoscaler{}).
+		For(&networkv1alpha1.GameAutoscaler{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(&networkv1alpha1.Fleet{}, r.gameEventHandler(), hasTarget).
+		Watches(&networkv1alpha1.Server{}, r.gameEventHandler(), hasTarget).
+		Watches(&corev1.Pod{}, r.gameEventHandler(), hasTarget).
 		Complete(r)
 }
 
+// gameEventHandler queues the autoscalers found by autoscalersForEvent after their debounce.
+// If the autoscaler is already queued, the earlier time is kept, so a constant stream of events cannot postpone the sync.
+func (r *GameAutoscalerReconciler) gameEventHandler() handler.EventHandler {
+	enqueue := func(ctx context.Context, object client.Object, queue workqueue.RateLimitingInterface) {
+		for request, debounce := range r.autoscalersForEvent(ctx, object) {
+			queue.AddAfter(request, debounce)
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+			enqueue(ctx, e.Object, queue)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+			enqueue(ctx, e.ObjectNew, queue)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+			enqueue(ctx, e.Object, queue)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+			enqueue(ctx, e.Object, queue)
+		},
+	}
+}
+
+// autoscalersForEvent finds the autoscalers using the event sync type for the target the object belongs to.
+// GameTypes are found from the type label, which fleets pass on to their servers and pods.
+// Fleets are found from the fleet label of servers and pods, or from the fleet itself.
+func (r *GameAutoscalerReconciler) autoscalersForEvent(ctx context.Context, object client.Object) map[reconcile.Request]time.Duration {
+	gameName := object.GetLabels()["type"]
+	fleetName := eventFleetName(object)
+	if gameName == "" && fleetName == "" {
+		return nil
+	}
+
+	autoscalers := &networkv1alpha1.GameAutoscalerList{}
+	if err := r.List(ctx, autoscalers, client.InNamespace(object.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list autoscalers for event", "game", gameName, "fleet", fleetName)
+		return nil
+	}
+
+	requests := make(map[reconcile.Request]time.Duration)
+	for _, autoscaler := range autoscalers.Items {
+		if autoscaler.Spec.Sync.Type != networkv1alpha1.Event {
+			continue
+		}
+		target := autoscaler.Spec.Target()
+		switch {
+		case target.Kind == networkv1alpha1.GameTypeTarget && gameName != "" && target.Name == gameName:
+		case target.Kind == networkv1alpha1.FleetTarget && fleetName != "" && target.Name == fleetName:
+		default:
+			continue
+		}
+		debounce := defaultEventDebounce
+		if autoscaler.Spec.Sync.Debounce != nil {
+			debounce = autoscaler.Spec.Sync.Debounce.Duration
+		}
+		request := reconcile.Request{NamespacedName: types.NamespacedName{
+			Name:      autoscaler.Name,
+			Namespace: autoscaler.Namespace,
+		}}
+		requests[request] = debounce
+	}
+	return requests
+}
+
+// eventFleetName returns the name of the fleet the object is or belongs to
+func eventFleetName(object client.Object) string {
+	if _, ok := object.(*networkv1alpha1.Fleet); ok {
+		return object.GetName()
+	}
+	return object.GetLabels()["fleet"]
+}
+
 // emitEvent is used by the GameAutoscalerReconciler to easily add events to objects
 func (r *GameAutoscalerReconciler) emitEvent(object runtime.Object, eventtype string, reason utils.EventReason, message string) {
 	r.Recorder.Event(object, eventtype, string(reason), message)
@@ -18,12 +18,21 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	autoscalerv1 "github.com/unfamousthomas/thesis-operator/api/proto/autoscaler/v1"
 	"github.com/unfamousthomas/thesis-operator/internal/utils"
+	"google.golang.org/grpc"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"time"
 
@@ -38,7 +47,7 @@ type TestWebhook struct {
 	Error    bool
 }
 
-func (t *TestWebhook) SendScaleWebhookRequest(autoscaler *networkv1alpha1.GameAutoscaler, gametype *networkv1alpha1.GameType) (utils.AutoscaleResponse, error) {
+func (t *TestWebhook) SendScaleWebhookRequest(autoscaler *networkv1alpha1.GameAutoscaler, target utils.ScaleTarget) (utils.AutoscaleResponse, error) {
 	if t.Error {
 		return utils.AutoscaleResponse{}, fmt.Errorf("random error with webhook")
 	}
@@ -48,6 +57,22 @@ func (t *TestWebhook) SendScaleWebhookRequest(autoscaler *networkv1alpha1.GameAu
 	}, nil
 }
 
+// testGrpcAutoscaler is an in-process autoscaler gRPC service responding with the configured replicas
+type testGrpcAutoscaler struct {
+	autoscalerv1.UnimplementedAutoscalerServer
+	Replicas int32
+	GameName string
+}
+
+func (t *testGrpcAutoscaler) Scale(ctx context.Context, request *autoscalerv1.AutoscaleRequest) (*autoscalerv1.AutoscaleResponse, error) {
+	t.GameName = request.GameName
+	return &autoscalerv1.AutoscaleResponse{
+		Scale:           true,
+		DesiredReplicas: t.Replicas,
+		ProtocolVersion: utils.AutoscaleProtocolVersion,
+	}, nil
+}
+
 var duration = metav1.Duration{Duration: 5 * time.Second}
 var path = "/scale"
 
@@ -216,8 +241,8 @@ var _ = Describe("GameAutoscaler Controller", func() {
 			},
 			})
 
-			Expect(err).To(Not(BeNil()))
-			Expect(err).To(Not(Succeed()))
+			Expect(err).To(BeNil())
+			Expect(res).To(Equal(reconcile.Result{}))
 
 			By("Reconcile with webhook error")
 			hook.Error = true
@@ -336,6 +361,736 @@ var _ = Describe("GameAutoscaler Controller", func() {
 			Expect(err).To(Not(BeNil()))
 		})
 
+		It("Reconcile with metrics policy", func() {
+			By("Setup a fake query endpoint")
+			body := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"35"]}]}}`
+			queryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprint(w, body)
+			}))
+			defer queryServer.Close()
+
+			recorder := NewFakeRecorder()
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  &TestWebhook{},
+				Metrics:  utils.ProductionMetricsQuery{},
+				Recorder: recorder,
+			}
+
+			By("Switch the autoscaler to the metrics policy")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			gameautoscaler.Spec.AutoscalePolicy = networkv1alpha1.AutoscalePolicy{
+				Type: networkv1alpha1.Metrics,
+				MetricsAutoscalerSpec: &networkv1alpha1.MetricsAutoscalerSpec{
+					Url:         queryServer.URL,
+					Query:       "sum(players)",
+					TargetValue: resource.MustParse("10"),
+				},
+			}
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+
+			By("Reconcile and check the scaled game")
+			res, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(BeEquivalentTo(5 * time.Second))
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(4))
+
+			By("Keep the replicas when the query has no data")
+			body = `{"status":"success","data":{"resultType":"vector","result":[]}}`
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).ToNot(BeNil())
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(4))
+			hasMetricsEvent := false
+			for _, event := range recorder.Events {
+				if event.Reason == string(utils.ReasonGameautoscalerMetrics) {
+					hasMetricsEvent = true
+					break
+				}
+			}
+			Expect(hasMetricsEvent).To(BeTrue())
+		})
+
+		It("Reconcile with schedule policy", func() {
+			recorder := NewFakeRecorder()
+			hook := &TestWebhook{
+				Scale:    true,
+				Replicas: 2,
+			}
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  hook,
+				Recorder: recorder,
+			}
+			desired := int32(6)
+			defaultReplicas := int32(1)
+
+			By("Switch the autoscaler to an always active schedule")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			gameautoscaler.Spec.AutoscalePolicy = networkv1alpha1.AutoscalePolicy{
+				Type: networkv1alpha1.Schedule,
+				ScheduleAutoscalerSpec: &networkv1alpha1.ScheduleAutoscalerSpec{
+					DefaultReplicas: &defaultReplicas,
+					Windows: []networkv1alpha1.ScheduleWindow{
+						{
+							Name:            "always",
+							Start:           "* * * * *",
+							Duration:        metav1.Duration{Duration: 2 * time.Minute},
+							MinReplicas:     3,
+							DesiredReplicas: &desired,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+
+			By("Reconcile and check the scheduled replicas")
+			res, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(BeNumerically("<=", 5*time.Second))
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(6))
+
+			By("Use the webhook with the schedule as the floor")
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			schedule := gameautoscaler.Spec.AutoscalePolicy.ScheduleAutoscalerSpec
+			gameautoscaler.Spec.AutoscalePolicy = basicGameautoscaler.AutoscalePolicy
+			gameautoscaler.Spec.AutoscalePolicy.ScheduleAutoscalerSpec = schedule
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(3))
+
+			By("Follow the webhook when no window is active")
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			gameautoscaler.Spec.AutoscalePolicy.ScheduleAutoscalerSpec.Windows[0].Start = "0 0 30 2 *"
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+			res, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(BeEquivalentTo(5 * time.Second))
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(2))
+		})
+
+		It("Reconcile with event sync", func() {
+			hook := &TestWebhook{
+				Scale:    true,
+				Replicas: 2,
+			}
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  hook,
+				Recorder: NewFakeRecorder(),
+			}
+			fleet := &networkv1alpha1.Fleet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-fleet",
+					Namespace: namespace,
+					Labels:    map[string]string{"type": resourceName},
+				},
+			}
+
+			By("Not queueing autoscalers with the fixed interval")
+			Expect(controllerReconciler.autoscalersForEvent(ctx, fleet)).To(BeEmpty())
+
+			By("Switch the autoscaler to event sync")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			gameautoscaler.Spec.Sync.Type = networkv1alpha1.Event
+			gameautoscaler.Spec.Sync.Debounce = &metav1.Duration{Duration: time.Second}
+			gameautoscaler.Spec.Sync.MinInterval = &metav1.Duration{Duration: time.Minute}
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+
+			By("Queueing the autoscaler of the changed game")
+			requests := controllerReconciler.autoscalersForEvent(ctx, fleet)
+			Expect(requests).To(HaveLen(1))
+			Expect(requests).To(HaveKeyWithValue(reconcile.Request{NamespacedName: autoscalerNamespacedName}, time.Second))
+			fleet.Labels["type"] = "other-game"
+			Expect(controllerReconciler.autoscalersForEvent(ctx, fleet)).To(BeEmpty())
+
+			By("Evaluating on the first sync")
+			res, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(BeEquivalentTo(5 * time.Second))
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(2))
+
+			By("Delaying syncs inside the minimum interval")
+			hook.Replicas = 3
+			res, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(BeNumerically(">", 50*time.Second))
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(2))
+		})
+
+		It("Reconcile should update the status", func() {
+			hook := &TestWebhook{
+				Scale:    true,
+				Replicas: 20,
+			}
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  hook,
+				Recorder: NewFakeRecorder(),
+			}
+
+			By("Limit the replicas of the autoscaler")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			maxReplicas := int32(8)
+			gameautoscaler.Spec.MaxReplicas = &maxReplicas
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+
+			By("Saving the limited scale")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(8))
+
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			status := gameautoscaler.Status
+			Expect(status.LastEvaluationTime).ToNot(BeNil())
+			Expect(status.LastWebhookCallTime).ToNot(BeNil())
+			Expect(status.WebhookProtocolVersion).To(BeEquivalentTo(1))
+			Expect(status.LastScaleTime).ToNot(BeNil())
+			Expect(status.LastDesiredReplicas).To(HaveValue(BeEquivalentTo(8)))
+			Expect(status.ConsecutiveFailures).To(BeEquivalentTo(0))
+			Expect(meta.IsStatusConditionTrue(status.Conditions, networkv1alpha1.GameAutoscalerActive)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(status.Conditions, networkv1alpha1.GameAutoscalerAbleToScale)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(status.Conditions, networkv1alpha1.GameAutoscalerScalingLimited)).To(BeTrue())
+
+			By("Counting the failures")
+			hook.Error = true
+			for i := 0; i < 2; i++ {
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+				Expect(err).ToNot(BeNil())
+			}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			status = gameautoscaler.Status
+			Expect(status.ConsecutiveFailures).To(BeEquivalentTo(2))
+			Expect(status.LastError).To(ContainSubstring("random error with webhook"))
+			Expect(meta.IsStatusConditionFalse(status.Conditions, networkv1alpha1.GameAutoscalerActive)).To(BeTrue())
+			Expect(status.LastDesiredReplicas).To(HaveValue(BeEquivalentTo(8)))
+
+			By("Resetting the failures on success")
+			hook.Error = false
+			hook.Replicas = 5
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			status = gameautoscaler.Status
+			Expect(status.ConsecutiveFailures).To(BeEquivalentTo(0))
+			Expect(status.LastError).To(BeEmpty())
+			Expect(meta.IsStatusConditionFalse(status.Conditions, networkv1alpha1.GameAutoscalerScalingLimited)).To(BeTrue())
+		})
+
+		It("Reconcile with scaling behavior", func() {
+			hook := &TestWebhook{
+				Scale:    true,
+				Replicas: 20,
+			}
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  hook,
+				Recorder: NewFakeRecorder(),
+			}
+
+			By("Limit scaling up to 2 replicas per minute")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			gameautoscaler.Spec.Behavior = &networkv1alpha1.ScalingBehavior{
+				ScaleUp: &networkv1alpha1.ScalingRules{
+					Policies: []networkv1alpha1.ScalingPolicy{
+						{
+							Type:   networkv1alpha1.ReplicasScalingPolicy,
+							Value:  2,
+							Period: metav1.Duration{Duration: time.Minute},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+
+			By("Only scaling by the step")
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			initialReplicas := updatedGameType.Spec.FleetSpec.Scaling.Replicas
+			for i := 0; i < 2; i++ {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+				Expect(err).To(BeNil())
+			}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(initialReplicas + 2))
+
+			By("Keeping the history in the status")
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			Expect(gameautoscaler.Status.ScaleEvents).To(HaveLen(1))
+			Expect(gameautoscaler.Status.ScaleEvents[0].Change).To(BeEquivalentTo(2))
+			condition := meta.FindStatusCondition(gameautoscaler.Status.Conditions, networkv1alpha1.GameAutoscalerScalingLimited)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("ScaleUpLimit"))
+		})
+
+		It("Reconcile with failure policy", func() {
+			recorder := NewFakeRecorder()
+			hook := &TestWebhook{
+				Error: true,
+			}
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  hook,
+				Recorder: recorder,
+			}
+
+			By("Falling back to 3 replicas after 2 failures")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			fallbackReplicas := int32(3)
+			gameautoscaler.Spec.Sync.Type = networkv1alpha1.FixedInterval
+			gameautoscaler.Spec.Behavior = nil
+			gameautoscaler.Spec.FailurePolicy = &networkv1alpha1.FailurePolicy{
+				Type:             networkv1alpha1.FallbackFailurePolicy,
+				FailureThreshold: 2,
+				FallbackReplicas: &fallbackReplicas,
+				InitialBackoff:   &metav1.Duration{Duration: 10 * time.Second},
+				MaxBackoff:       &metav1.Duration{Duration: time.Minute},
+			}
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+
+			By("Retrying with a backoff before the threshold")
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			initialReplicas := updatedGameType.Spec.FleetSpec.Scaling.Replicas
+			res, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(BeNumerically("~", 7500*time.Millisecond, 2500*time.Millisecond))
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(Equal(initialReplicas))
+
+			By("Scaling to the fallback replicas at the threshold")
+			res, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(BeNumerically("~", 15*time.Second, 5*time.Second))
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(3))
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			Expect(gameautoscaler.Status.ConsecutiveFailures).To(BeEquivalentTo(2))
+			condition := meta.FindStatusCondition(gameautoscaler.Status.Conditions, networkv1alpha1.GameAutoscalerDegraded)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("FallbackReplicas"))
+			Expect(recorder.Events).To(ContainElement(HaveField("Message", "Falling back to 3 replicas after 2 failures")))
+
+			By("Recovering once the webhook works again")
+			hook.Error = false
+			hook.Scale = true
+			hook.Replicas = 4
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(4))
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(gameautoscaler.Status.Conditions, networkv1alpha1.GameAutoscalerDegraded)).To(BeTrue())
+
+			By("Removing the failure policy")
+			gameautoscaler.Spec.FailurePolicy = nil
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+		})
+
+		It("Reconcile with composite policy", func() {
+			hook := &TestWebhook{
+				Scale:    true,
+				Replicas: 4,
+			}
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  hook,
+				Recorder: NewFakeRecorder(),
+			}
+
+			By("Combining the webhook and a schedule")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			originalPolicy := gameautoscaler.Spec.AutoscalePolicy
+			webhook := originalPolicy.WebhookAutoscalerSpec
+			gameautoscaler.Spec.AutoscalePolicy = networkv1alpha1.AutoscalePolicy{
+				Type: networkv1alpha1.Composite,
+				CompositeAutoscalerSpec: &networkv1alpha1.CompositeAutoscalerSpec{
+					Combinator: networkv1alpha1.MaxCompositeCombinator,
+					Policies: []networkv1alpha1.CompositePolicy{
+						{
+							Name:                  "game-webhook",
+							Type:                  networkv1alpha1.Webhook,
+							WebhookAutoscalerSpec: &webhook,
+						},
+						{
+							Name: "always",
+							Type: networkv1alpha1.Schedule,
+							ScheduleAutoscalerSpec: &networkv1alpha1.ScheduleAutoscalerSpec{
+								Windows: []networkv1alpha1.ScheduleWindow{
+									{
+										Name:        "always",
+										Start:       "* * * * *",
+										Duration:    metav1.Duration{Duration: time.Hour},
+										MinReplicas: 6,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+
+			By("Using the highest recommendation")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(6))
+
+			By("Saving every recommendation to the status")
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			recommendations := gameautoscaler.Status.PolicyRecommendations
+			Expect(recommendations).To(HaveLen(2))
+			Expect(recommendations[0].Name).To(Equal("game-webhook"))
+			Expect(recommendations[0].Replicas).To(HaveValue(BeEquivalentTo(4)))
+			Expect(recommendations[0].Selected).To(BeFalse())
+			Expect(recommendations[1].Replicas).To(HaveValue(BeEquivalentTo(6)))
+			Expect(recommendations[1].Selected).To(BeTrue())
+
+			By("Using the first successful policy when the webhook fails")
+			hook.Error = true
+			gameautoscaler.Spec.AutoscalePolicy.CompositeAutoscalerSpec.Combinator = networkv1alpha1.FirstSuccessfulCompositeCombinator
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			recommendations = gameautoscaler.Status.PolicyRecommendations
+			Expect(recommendations).To(HaveLen(2))
+			Expect(recommendations[0].Error).To(ContainSubstring("random error with webhook"))
+			Expect(recommendations[1].Selected).To(BeTrue())
+
+			By("Failing the composite policy with min when the webhook fails")
+			gameautoscaler.Spec.AutoscalePolicy.CompositeAutoscalerSpec.Combinator = networkv1alpha1.MinCompositeCombinator
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).ToNot(BeNil())
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(6))
+
+			By("Resetting the policy")
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			gameautoscaler.Spec.AutoscalePolicy = originalPolicy
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+		})
+
+		It("Reconcile with dry run", func() {
+			recorder := NewFakeRecorder()
+			hook := &TestWebhook{
+				Scale:    true,
+				Replicas: 7,
+			}
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  hook,
+				Recorder: recorder,
+			}
+
+			By("Enabling dry run")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			gameautoscaler.Spec.DryRun = true
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			initialReplicas := updatedGameType.Spec.FleetSpec.Scaling.Replicas
+
+			By("Not updating the gametype")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(Equal(initialReplicas))
+
+			By("Recording the replicas it would scale to")
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			Expect(gameautoscaler.Status.LastDesiredReplicas).To(HaveValue(BeEquivalentTo(7)))
+			condition := meta.FindStatusCondition(gameautoscaler.Status.Conditions, networkv1alpha1.GameAutoscalerAbleToScale)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Reason).To(Equal("DryRun"))
+			Expect(recorder.Events).To(ContainElement(HaveField("Message", "Dry run, would scale game to 7")))
+			Expect(testutil.ToFloat64(dryRunReplicas.WithLabelValues(namespace, resourceName, resourceName))).To(BeEquivalentTo(7))
+
+			By("Removing the metric once dry run is disabled")
+			gameautoscaler.Spec.DryRun = false
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(testutil.CollectAndCount(dryRunReplicas)).To(BeZero())
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(7))
+		})
+
+		It("should forget a deleted autoscaler without retrying", func() {
+			setDryRunReplicas(namespace, "deleted-autoscaler", resourceName, 3)
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: NewFakeRecorder(),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "deleted-autoscaler", Namespace: namespace}})
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(reconcile.Result{}))
+			Expect(testutil.CollectAndCount(dryRunReplicas)).To(BeZero())
+		})
+
+		It("Reconcile with fleet target", func() {
+			hook := &TestWebhook{
+				Scale:    true,
+				Replicas: 3,
+			}
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  hook,
+				Recorder: NewFakeRecorder(),
+			}
+
+			By("Creating a standalone fleet")
+			fleetNamespacedName := types.NamespacedName{Name: "standalone-fleet", Namespace: namespace}
+			fleet := &networkv1alpha1.Fleet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fleetNamespacedName.Name,
+					Namespace: namespace,
+				},
+				Spec: basicFleetSpec,
+			}
+			fleet.Spec.Scaling.Replicas = 1
+			Expect(k8sClient.Create(ctx, fleet)).To(Succeed())
+
+			By("Targeting the fleet")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			gameautoscaler.Spec.GameName = ""
+			gameautoscaler.Spec.TargetRef = &networkv1alpha1.TargetRef{
+				Kind: networkv1alpha1.FleetTarget,
+				Name: fleetNamespacedName.Name,
+			}
+			gameautoscaler.Spec.Sync.Type = networkv1alpha1.Event
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			gametypeReplicas := updatedGameType.Spec.FleetSpec.Scaling.Replicas
+
+			By("Queueing the autoscaler for events of the fleet and its servers")
+			Expect(controllerReconciler.autoscalersForEvent(ctx, fleet)).To(HaveKey(reconcile.Request{NamespacedName: autoscalerNamespacedName}))
+			server := &networkv1alpha1.Server{ObjectMeta: metav1.ObjectMeta{
+				Name:      "standalone-server",
+				Namespace: namespace,
+				Labels:    map[string]string{"fleet": fleetNamespacedName.Name},
+			}}
+			Expect(controllerReconciler.autoscalersForEvent(ctx, server)).To(HaveKey(reconcile.Request{NamespacedName: autoscalerNamespacedName}))
+			server.Labels = map[string]string{"type": resourceName}
+			Expect(controllerReconciler.autoscalersForEvent(ctx, server)).To(BeEmpty())
+
+			By("Scaling the fleet instead of the gametype")
+			gameautoscaler.Status.LastEvaluationTime = nil
+			Expect(k8sClient.Status().Update(ctx, gameautoscaler)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(k8sClient.Get(ctx, fleetNamespacedName, fleet)).To(Succeed())
+			Expect(fleet.Spec.Scaling.Replicas).To(BeEquivalentTo(3))
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).To(Equal(gametypeReplicas))
+
+			By("Resetting the target")
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			gameautoscaler.Spec.GameName = resourceName
+			gameautoscaler.Spec.TargetRef = nil
+			gameautoscaler.Spec.Sync.Type = networkv1alpha1.FixedInterval
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, fleet)).To(Succeed())
+		})
+
+		It("Reconcile with grpc transport", func() {
+			By("Starting the gRPC autoscaler")
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			grpcAutoscaler := &testGrpcAutoscaler{Replicas: 4}
+			server := grpc.NewServer()
+			autoscalerv1.RegisterAutoscalerServer(server, grpcAutoscaler)
+			go func() {
+				_ = server.Serve(listener)
+			}()
+			defer server.Stop()
+
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  utils.ProductionWebhookRequest{Client: k8sClient},
+				Recorder: NewFakeRecorder(),
+			}
+
+			By("Switching the webhook to the grpc transport")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			previousWebhook := gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec
+			address := listener.Addr().String()
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec = networkv1alpha1.WebhookAutoscalerSpec{
+				Url:       &address,
+				Transport: networkv1alpha1.GrpcTransport,
+				Timeout:   &metav1.Duration{Duration: 2 * time.Second},
+			}
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+
+			By("Scaling to the replicas of the gRPC response")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(grpcAutoscaler.GameName).To(Equal(resourceName))
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(4))
+
+			By("Failing once the server is gone")
+			server.Stop()
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(Not(BeNil()))
+
+			By("Resetting the webhook")
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			gameautoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec = previousWebhook
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+		})
+
+		It("Reconcile with predictive policy", func() {
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  &TestWebhook{},
+				Recorder: NewFakeRecorder(),
+			}
+
+			By("Switching to the predictive policy")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			previousPolicy := gameautoscaler.Spec.AutoscalePolicy
+			gameautoscaler.Spec.Behavior = nil
+			gameautoscaler.Spec.AutoscalePolicy = networkv1alpha1.AutoscalePolicy{
+				Type:                     networkv1alpha1.Predictive,
+				PredictiveAutoscalerSpec: &networkv1alpha1.PredictiveAutoscalerSpec{},
+			}
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+			Expect(gameautoscaler.Spec.AutoscalePolicy.PredictiveAutoscalerSpec.SampleInterval).To(HaveValue(Equal(metav1.Duration{Duration: 5 * time.Minute})))
+
+			By("Recording a steady demand of 5 servers")
+			gameautoscaler.Status.DemandHistory = &networkv1alpha1.DemandHistory{
+				Interval:       metav1.Duration{Duration: 5 * time.Minute},
+				LastSampleTime: metav1.Now(),
+				Samples:        []int32{5, 5, 5, 5},
+			}
+			Expect(k8sClient.Status().Update(ctx, gameautoscaler)).To(Succeed())
+
+			By("Scaling to the forecast with the safety margin")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(6))
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			Expect(gameautoscaler.Status.DemandHistory.Samples).To(Equal([]int32{5, 5, 5, 5}))
+			Expect(gameautoscaler.Status.DemandHistory.Forecast).To(HaveValue(BeEquivalentTo(5)))
+
+			By("Resetting the policy")
+			gameautoscaler.Spec.AutoscalePolicy = previousPolicy
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+		})
+
+		It("Reconcile with push policy", func() {
+			recorder := NewFakeRecorder()
+			controllerReconciler := &GameAutoscalerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Webhook:  &TestWebhook{Scale: true, Replicas: 2},
+				Recorder: recorder,
+			}
+
+			By("Pushing 5 replicas for an hour")
+			gameautoscaler := &networkv1alpha1.GameAutoscaler{}
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			previousPolicy := gameautoscaler.Spec.AutoscalePolicy
+			webhook := previousPolicy.WebhookAutoscalerSpec
+			gameautoscaler.Spec.Behavior = nil
+			gameautoscaler.Spec.AutoscalePolicy = networkv1alpha1.AutoscalePolicy{
+				Type: networkv1alpha1.Push,
+				PushAutoscalerSpec: &networkv1alpha1.PushAutoscalerSpec{
+					Base: &networkv1alpha1.CompositePolicy{Name: "base", Type: networkv1alpha1.Webhook, WebhookAutoscalerSpec: &webhook},
+				},
+			}
+			pushTime := time.Now().Add(-time.Minute)
+			pushed, err := json.Marshal(networkv1alpha1.PushedReplicas{
+				Replicas:       5,
+				Reason:         "tournament",
+				Time:           metav1.NewTime(pushTime),
+				ExpirationTime: metav1.NewTime(pushTime.Add(time.Hour)),
+			})
+			Expect(err).To(BeNil())
+			gameautoscaler.Annotations = map[string]string{networkv1alpha1.PushAnnotation: string(pushed)}
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+
+			By("Applying the pushed replicas until they expire")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			updatedGameType := networkv1alpha1.GameType{}
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(5))
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			Expect(gameautoscaler.Status.ActivePush).ToNot(BeNil())
+			Expect(gameautoscaler.Status.ActivePush.Reason).To(Equal("tournament"))
+			Expect(recorder.Events).To(ContainElement(HaveField("Message", "Applying 5 pushed replicas: tournament")))
+
+			By("Reverting to the base policy once the push expired")
+			pushed, err = json.Marshal(networkv1alpha1.PushedReplicas{
+				Replicas:       5,
+				Reason:         "tournament",
+				Time:           metav1.NewTime(pushTime),
+				ExpirationTime: metav1.NewTime(pushTime.Add(30 * time.Second)),
+			})
+			Expect(err).To(BeNil())
+			gameautoscaler.Annotations[networkv1alpha1.PushAnnotation] = string(pushed)
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: autoscalerNamespacedName})
+			Expect(err).To(BeNil())
+			Expect(k8sClient.Get(ctx, gameTypeNamespacedName, &updatedGameType)).To(Succeed())
+			Expect(updatedGameType.Spec.FleetSpec.Scaling.Replicas).Should(BeEquivalentTo(2))
+			Expect(k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)).To(Succeed())
+			Expect(gameautoscaler.Status.ActivePush).To(BeNil())
+			Expect(recorder.Events).To(ContainElement(HaveField("Message", "The pushed replicas expired, reverting to the base policy")))
+
+			By("Resetting the policy")
+			gameautoscaler.Spec.AutoscalePolicy = previousPolicy
+			delete(gameautoscaler.Annotations, networkv1alpha1.PushAnnotation)
+			Expect(k8sClient.Update(ctx, gameautoscaler)).To(Succeed())
+		})
+
 		It("Should emit the correct events", func() {
 			recorder := NewFakeRecorder()
 
@@ -379,6 +1134,8 @@ var _ = Describe("GameAutoscaler Controller", func() {
 			Expect(hasGametypeErrorEvent).To(BeTrue())
 
 			By("Reset game name")
+			err = k8sClient.Get(ctx, autoscalerNamespacedName, gameautoscaler)
+			Expect(err).To(BeNil())
 			gameautoscaler.Spec.GameName = originalGametype
 			err = k8sClient.Update(ctx, gameautoscaler)
 			Expect(err).To(BeNil())
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// dryRunReplicas is the replica count autoscalers in dry run mode would have scaled their game to
+var dryRunReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "gameautoscaler_dry_run_replicas",
+	Help: "Replica count a GameAutoscaler in dry run mode would scale its game to",
+}, []string{"namespace", "autoscaler", "game"})
+
+func init() {
+	metrics.Registry.MustRegister(dryRunReplicas)
+}
+
+// setDryRunReplicas sets the dry run replicas of the autoscaler, removing the series of any game it used before
+func setDryRunReplicas(namespace string, autoscaler string, game string, replicas int32) {
+	clearDryRunReplicas(namespace, autoscaler)
+	dryRunReplicas.WithLabelValues(namespace, autoscaler, game).Set(float64(replicas))
+}
+
+// clearDryRunReplicas removes the dry run replicas of the autoscaler
+func clearDryRunReplicas(namespace string, autoscaler string) {
+	dryRunReplicas.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "autoscaler": autoscaler})
+}
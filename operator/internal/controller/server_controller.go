@@ -54,6 +54,8 @@ type ServerReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patcch;delete
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=create
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=create
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -117,6 +119,10 @@ func (r *ServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
+	if err := r.checkCompleted(ctx, server); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if err := r.Status().Update(ctx, server); err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to update Server resource: %w", err)
 	}
@@ -143,6 +149,12 @@ func (r *ServerReconciler) ensurePodExists(ctx context.Context, server *networkv
 	}
 
 	if err != nil { // Pod does not exist
+		if utils.UsesSidecarAccount(server) {
+			if err := utils.EnsureSidecarAccess(ctx, r.Client, server); err != nil {
+				r.emitEventf(server, corev1.EventTypeWarning, utils.ReasonServerPodCreationFailed, "Failed to set up the sidecar service account: %s", err)
+				return false, err
+			}
+		}
 		newPod, defaultImg := utils.GetNewPod(server, server.Namespace)
 		if defaultImg {
 			r.emitEvent(server, corev1.EventTypeNormal, utils.ReasonServerInitialized, "Setting up sidecar with default image")
@@ -242,6 +254,31 @@ func (r *ServerReconciler) ensurePodFinalizer(ctx context.Context, server *netwo
 	return true, nil
 }
 
+// checkCompleted marks a OneShot server Completed once its game containers exited, which allows its deletion
+func (r *ServerReconciler) checkCompleted(ctx context.Context, server *networkv1alpha1.Server) error {
+	if server.Spec.Lifecycle != networkv1alpha1.LifecycleOneShot || utils.IsServerCompleted(server) {
+		return nil
+	}
+	pod := &corev1.Pod{}
+	namespacedName := types.NamespacedName{Namespace: server.Namespace, Name: server.Name + "-pod"}
+	if err := r.Get(ctx, namespacedName, pod); err != nil {
+		return err
+	}
+	exited, message := utils.GameExited(pod)
+	if !exited {
+		return nil
+	}
+	meta.SetStatusCondition(&server.Status.Conditions, metav1.Condition{
+		Type:               networkv1alpha1.ServerCompleted,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "GameExited",
+		Message:            "The game exited: " + message,
+	})
+	r.emitEventf(server, corev1.EventTypeNormal, utils.ReasonServerCompleted, "Server completed, %s", message)
+	return nil
+}
+
 // emitEvent is used by the ServerReconciler to add events to an object easily
 func (r *ServerReconciler) emitEvent(object runtime.Object, eventtype string, reason utils.EventReason, message string) {
 	r.Recorder.Event(object, eventtype, string(reason), message)
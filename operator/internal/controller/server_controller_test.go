@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -332,6 +333,88 @@ var _ = Describe("ServerReconciler", func() {
 			Expect(hasGlobalFinalizerRemoved).To(BeTrue())
 		})
 
+		It("should mark a OneShot server Completed once its game exits", func() {
+			recorder := NewFakeRecorder()
+			reconciler := &ServerReconciler{
+				Client:            k8sClient,
+				Scheme:            k8sClient.Scheme(),
+				ErrorOnNotAllowed: true,
+				DeletionAllowed:   TestChecker{deleteAllowed: make(map[string]bool)},
+				Recorder:          recorder,
+			}
+			server := &networkv1alpha1.Server{}
+			Expect(k8sClient.Get(ctx, namespacedName, server)).To(Succeed())
+			server.Spec.Lifecycle = networkv1alpha1.LifecycleOneShot
+			Expect(k8sClient.Update(ctx, server)).To(Succeed())
+
+			By("Reconciling until the pod exists")
+			for range 3 {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			pod := &corev1.Pod{}
+			podName := types.NamespacedName{Name: ServerName + "-pod", Namespace: ServerNamespace}
+			Expect(k8sClient.Get(ctx, podName, pod)).To(Succeed())
+			Expect(pod.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+			Expect(pod.Spec.InitContainers).To(ContainElement(HaveField("Name", "loputoo-sidecar")))
+			Expect(k8sClient.Get(ctx, namespacedName, server)).To(Succeed())
+			Expect(meta.FindStatusCondition(server.Status.Conditions, networkv1alpha1.ServerCompleted)).To(BeNil())
+
+			By("Exiting the game container while the sidecar keeps running")
+			pod.Status.Phase = corev1.PodRunning
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{
+					Name:    "nginx",
+					Image:   "nginx:1.7.9",
+					ImageID: "nginx",
+					State:   corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+				},
+			}
+			pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+				{
+					Name:    "loputoo-sidecar",
+					Image:   "sidecar",
+					ImageID: "sidecar",
+					State:   corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, namespacedName, server)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(server.Status.Conditions, networkv1alpha1.ServerCompleted)).To(BeTrue())
+
+			completedEvent := false
+			for _, event := range recorder.Events {
+				if event.Message == "Server completed, container nginx exited with code 0" {
+					completedEvent = true
+				}
+			}
+			Expect(completedEvent).To(BeTrue())
+		})
+
+		It("should require the game process to signal or watch", func() {
+			server := &networkv1alpha1.Server{}
+			Expect(k8sClient.Get(ctx, namespacedName, server)).To(Succeed())
+
+			By("Rejecting a signal without the game process")
+			server.Spec.ShutdownDelivery = &networkv1alpha1.ShutdownDelivery{Signal: "SIGUSR1"}
+			Expect(k8sClient.Update(ctx, server)).NotTo(Succeed())
+
+			By("Rejecting allowDeleteOnExit without the game process")
+			server.Spec.ShutdownDelivery = &networkv1alpha1.ShutdownDelivery{AllowDeleteOnExit: true}
+			Expect(k8sClient.Update(ctx, server)).NotTo(Succeed())
+
+			By("Accepting files without the game process")
+			server.Spec.ShutdownDelivery = &networkv1alpha1.ShutdownDelivery{File: "shutdown"}
+			Expect(k8sClient.Update(ctx, server)).To(Succeed())
+
+			By("Accepting a signal to the game process")
+			server.Spec.ShutdownDelivery = &networkv1alpha1.ShutdownDelivery{Signal: "SIGUSR1", Process: "game"}
+			Expect(k8sClient.Update(ctx, server)).To(Succeed())
+		})
+
 		It("Should return error on get fail", func() {
 			checker := TestChecker{
 				deleteAllowed: make(map[string]bool),
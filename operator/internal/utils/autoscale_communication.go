@@ -2,31 +2,72 @@ package utils
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
 	"io"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"sync"
 	"time"
 )
 
 type Webhook interface {
-	SendScaleWebhookRequest(autoscaler *networkv1alpha1.GameAutoscaler, gametype *networkv1alpha1.GameType) (AutoscaleResponse, error)
+	SendScaleWebhookRequest(autoscaler *networkv1alpha1.GameAutoscaler, target ScaleTarget) (AutoscaleResponse, error)
 }
 
-type ProductionWebhookRequest struct{}
+// AutoscaleProtocolVersion is the newest version of the webhook protocol the operator supports
+const AutoscaleProtocolVersion = 2
+
+// ProductionWebhookRequest sends the scale request to the webhook of the autoscaler.
+// The Client is used to collect the state of the fleets for version 2 requests, without it only the version 1 fields are sent.
+// The SecretReader is used to read the secret of the webhook, it should not be cached so secrets do not have to be watched.
+type ProductionWebhookRequest struct {
+	Client       client.Reader
+	SecretReader client.Reader
+}
+
+const (
+	// WebhookTimestampHeader holds the unix time the request was signed at
+	WebhookTimestampHeader = "X-Autoscaler-Timestamp"
+	// WebhookSignatureHeader holds the HMAC-SHA256 of "<timestamp>.<body>", as "sha256=<hex>"
+	WebhookSignatureHeader = "X-Autoscaler-Signature"
+)
 
 func (w ProductionWebhookRequest) SendScaleWebhookRequest(autoscaler *networkv1alpha1.GameAutoscaler,
-	gametype *networkv1alpha1.GameType) (AutoscaleResponse, error) {
+	target ScaleTarget) (AutoscaleResponse, error) {
 	autoscalerSpec := autoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec
 
+	ctx, cancel := context.WithTimeout(context.Background(), WebhookTimeout(autoscalerSpec))
+	defer cancel()
+	request, err := w.buildRequest(ctx, autoscaler, target)
+	if err != nil {
+		return AutoscaleResponse{}, fmt.Errorf("failed to build request: %w", err)
+	}
+	if autoscalerSpec.Transport == networkv1alpha1.GrpcTransport {
+		return w.sendGrpcRequest(ctx, autoscaler.Namespace, autoscalerSpec, request)
+	}
+
 	var url string
 	if autoscalerSpec.Url != nil {
 		url = *autoscalerSpec.Url
 	} else {
 		service := autoscalerSpec.Service
-		url = fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", service.Name, service.Namespace, service.Port)
+		scheme := service.Scheme
+		if scheme == "" {
+			scheme = "http"
+		}
+		url = fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d", scheme, service.Name, service.Namespace, service.Port)
 	}
 	if autoscalerSpec.Path == nil {
 		return AutoscaleResponse{}, errors.New("missing path")
@@ -34,23 +75,26 @@ func (w ProductionWebhookRequest) SendScaleWebhookRequest(autoscaler *networkv1a
 	path := *autoscalerSpec.Path
 	url = url + "/" + path
 
-	httpClient := &http.Client{
-		Timeout: 10 * time.Second,
+	httpClient, err := newWebhookClient(autoscalerSpec.CABundle)
+	if err != nil {
+		return AutoscaleResponse{}, err
 	}
 
-	request := AutoscaleRequest{
-		GameName:        autoscaler.Spec.GameName,
-		CurrentReplicas: int(gametype.Spec.FleetSpec.Scaling.Replicas),
-	}
 	requestBody, err := json.Marshal(request)
 	if err != nil {
 		return AutoscaleResponse{}, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return AutoscaleResponse{}, err
 	}
+	req.Header.Set("Content-Type", "application/json")
+	if autoscalerSpec.SecretRef != nil {
+		if err := w.addCredentials(ctx, req, autoscaler.Namespace, autoscalerSpec.SecretRef, requestBody, time.Now()); err != nil {
+			return AutoscaleResponse{}, fmt.Errorf("failed to add credentials: %w", err)
+		}
+	}
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -73,15 +117,219 @@ func (w ProductionWebhookRequest) SendScaleWebhookRequest(autoscaler *networkv1a
 	if err != nil {
 		return AutoscaleResponse{}, fmt.Errorf("failed to decode response: %w\nRaw response: %s\n", err, string(bodyBytes))
 	}
+	if response.ProtocolVersion > AutoscaleProtocolVersion {
+		return AutoscaleResponse{}, fmt.Errorf("webhook responded with unsupported protocol version %d", response.ProtocolVersion)
+	}
 	return response, nil
 }
 
+// DefaultWebhookTimeout is the deadline of a webhook request without a configured timeout
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WebhookTimeout returns the deadline of a single request to the webhook
+func WebhookTimeout(spec networkv1alpha1.WebhookAutoscalerSpec) time.Duration {
+	if spec.Timeout == nil || spec.Timeout.Duration <= 0 {
+		return DefaultWebhookTimeout
+	}
+	return spec.Timeout.Duration
+}
+
+// webhookClients caches the clients used to call the webhooks by the hash of their CA bundle,
+// so the connections of a webhook are reused between requests instead of a new transport being left behind on every call.
+var webhookClients = struct {
+	sync.Mutex
+	clients map[[sha256.Size]byte]*http.Client
+}{clients: make(map[[sha256.Size]byte]*http.Client)}
+
+// newWebhookClient returns the client used to call the webhook, trusting the CA bundle if one is set.
+// The transport is cloned from the default one, so the proxy and dial settings are kept. The deadline comes from the context of the request.
+func newWebhookClient(caBundle []byte) (*http.Client, error) {
+	if len(caBundle) == 0 {
+		return http.DefaultClient, nil
+	}
+	key := sha256.Sum256(caBundle)
+	webhookClients.Lock()
+	defer webhookClients.Unlock()
+	if httpClient, ok := webhookClients.clients[key]; ok {
+		return httpClient, nil
+	}
+
+	tlsConfig, err := newWebhookTLSConfig(caBundle)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	httpClient := &http.Client{Transport: transport}
+	webhookClients.clients[key] = httpClient
+	return httpClient, nil
+}
+
+// newWebhookTLSConfig creates the TLS config trusting the CA bundle, or the system roots if it is empty
+func newWebhookTLSConfig(caBundle []byte) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if len(caBundle) == 0 {
+		return tlsConfig, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBundle) {
+		return nil, errors.New("caBundle does not contain any PEM encoded certificates")
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// addCredentials reads the secret of the webhook and adds the token, headers and signature from it to the request
+func (w ProductionWebhookRequest) addCredentials(ctx context.Context, req *http.Request, namespace string,
+	ref *networkv1alpha1.WebhookSecretRef, body []byte, now time.Time) error {
+	headers, signingKey, err := w.readCredentials(ctx, namespace, ref)
+	if err != nil {
+		return err
+	}
+	for header, headerValue := range headers {
+		req.Header.Set(header, headerValue)
+	}
+	if signingKey != "" {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, SignWebhookBody([]byte(signingKey), timestamp, body))
+	}
+	return nil
+}
+
+// readCredentials reads the secret of the webhook and returns the headers to send, including the token,
+// and the signing key if one is set
+func (w ProductionWebhookRequest) readCredentials(ctx context.Context, namespace string,
+	ref *networkv1alpha1.WebhookSecretRef) (map[string]string, string, error) {
+	if w.SecretReader == nil {
+		return nil, "", errors.New("no secret reader configured")
+	}
+	secret := &corev1.Secret{}
+	if err := w.SecretReader.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+		return nil, "", err
+	}
+	if secret.Labels[networkv1alpha1.WebhookCredentialsLabel] != "true" {
+		return nil, "", fmt.Errorf("secret %s is not labelled %s=true", ref.Name, networkv1alpha1.WebhookCredentialsLabel)
+	}
+	value := func(key string) (string, error) {
+		data, exists := secret.Data[key]
+		if !exists {
+			return "", fmt.Errorf("secret %s has no key %s", ref.Name, key)
+		}
+		return string(data), nil
+	}
+
+	headers := make(map[string]string, len(ref.Headers)+1)
+	for header, key := range ref.Headers {
+		headerValue, err := value(key)
+		if err != nil {
+			return nil, "", err
+		}
+		headers[header] = headerValue
+	}
+	if ref.TokenKey != "" {
+		token, err := value(ref.TokenKey)
+		if err != nil {
+			return nil, "", err
+		}
+		headers["Authorization"] = "Bearer " + token
+	}
+	var signingKey string
+	if ref.SigningKey != "" {
+		key, err := value(ref.SigningKey)
+		if err != nil {
+			return nil, "", err
+		}
+		signingKey = key
+	}
+	return headers, signingKey, nil
+}
+
+// SignWebhookBody returns the signature of the body, which covers the timestamp so old requests cannot be replayed
+func SignWebhookBody(key []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// buildRequest creates the request in the protocol version set on the autoscaler.
+// For fleet targets, the game name is taken from the type label of the fleet, as standalone fleets may not have one.
+func (w ProductionWebhookRequest) buildRequest(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler,
+	target ScaleTarget) (AutoscaleRequest, error) {
+	targetRef := autoscaler.Spec.Target()
+	request := AutoscaleRequest{
+		GameName:        target.GetName(),
+		CurrentReplicas: int(target.GetReplicas()),
+	}
+	if targetRef.Kind == networkv1alpha1.FleetTarget {
+		request.GameName = target.GetLabels()["type"]
+	}
+
+	version := AutoscaleProtocolVersion
+	if autoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.ProtocolVersion != nil {
+		version = int(*autoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.ProtocolVersion)
+	}
+	if version < 2 {
+		return request, nil
+	}
+
+	request.ProtocolVersion = version
+	request.Namespace = autoscaler.Namespace
+	request.TargetKind = string(targetRef.Kind)
+	request.TargetName = target.GetName()
+	request.MinReplicas = autoscaler.Spec.MinReplicas
+	request.MaxReplicas = autoscaler.Spec.MaxReplicas
+	if status := autoscaler.Status; status.LastDesiredReplicas != nil {
+		request.LastDecision = &AutoscaleDecision{
+			DesiredReplicas:     *status.LastDesiredReplicas,
+			ConsecutiveFailures: status.ConsecutiveFailures,
+		}
+		if status.LastScaleTime != nil {
+			request.LastDecision.ScaleTime = &status.LastScaleTime.Time
+		}
+	}
+
+	if w.Client != nil {
+		fleets, totals, err := CollectFleetStates(ctx, w.Client, target)
+		if err != nil {
+			return AutoscaleRequest{}, err
+		}
+		request.Fleets = fleets
+		request.Totals = &totals
+	}
+	return request, nil
+}
+
 type AutoscaleRequest struct {
 	GameName        string `json:"game_name"`
 	CurrentReplicas int    `json:"current_replicas"`
+
+	// The following fields are only sent since protocol version 2
+	ProtocolVersion int                `json:"protocol_version,omitempty"`
+	Namespace       string             `json:"namespace,omitempty"`
+	TargetKind      string             `json:"target_kind,omitempty"`
+	TargetName      string             `json:"target_name,omitempty"`
+	MinReplicas     *int32             `json:"min_replicas,omitempty"`
+	MaxReplicas     *int32             `json:"max_replicas,omitempty"`
+	Fleets          []FleetState       `json:"fleets,omitempty"`
+	Totals          *ServerCounts      `json:"totals,omitempty"`
+	LastDecision    *AutoscaleDecision `json:"last_decision,omitempty"`
+}
+
+// AutoscaleDecision is the last replica count the autoscaler decided on
+type AutoscaleDecision struct {
+	DesiredReplicas     int32      `json:"desired_replicas"`
+	ScaleTime           *time.Time `json:"scale_time,omitempty"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
 }
 
 type AutoscaleResponse struct {
 	Scale           bool `json:"scale"`
 	DesiredReplicas int  `json:"desired_replicas"`
+	// Version of the protocol the webhook speaks, responses without it are treated as version 1
+	ProtocolVersion int `json:"protocol_version,omitempty"`
 }
@@ -0,0 +1,323 @@
+package utils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"time"
+)
+
+// newFakeWebhook starts a webhook that saves the request body and responds with the given body
+func newFakeWebhook(response string, received *map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+}
+
+func gameServer(name string, players int64, deleting bool) *networkv1alpha1.Server {
+	server := &networkv1alpha1.Server{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"type": "game", "fleet": "game-fleet"},
+		},
+		Status: networkv1alpha1.ServerStatus{
+			Players:  players,
+			Counters: map[string]int64{"matches": 1},
+			Sidecar:  &networkv1alpha1.SidecarStatus{Ready: true},
+		},
+	}
+	if deleting {
+		server.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		server.Finalizers = []string{"servers.unfamousthomas.me/finalizer"}
+	}
+	return server
+}
+
+func gamePod(server string, ready bool, allocated bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      server + "-pod",
+			Namespace: "default",
+			Labels:    map[string]string{"type": "game", "fleet": "game-fleet", "server": server},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+	if allocated {
+		pod.Labels[AllocatedLabel] = "true"
+	}
+	return pod
+}
+
+var _ = Describe("Autoscale Webhook Testing", func() {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	Expect(networkv1alpha1.AddToScheme(scheme)).To(Succeed())
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	gametype := &networkv1alpha1.GameType{
+		ObjectMeta: metav1.ObjectMeta{Name: "game", Namespace: "default"},
+	}
+	gametype.Spec.FleetSpec.Scaling.Replicas = 3
+
+	newClient := func() client.Client {
+		fleet := &networkv1alpha1.Fleet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "game-fleet",
+				Namespace: "default",
+				Labels:    map[string]string{"type": "game"},
+			},
+		}
+		fleet.Spec.Scaling.Replicas = 3
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			fleet,
+			gameServer("server-1", 10, false), gamePod("server-1", true, true),
+			gameServer("server-2", 5, false), gamePod("server-2", true, false),
+			gameServer("server-3", 0, true), gamePod("server-3", false, false),
+		).Build()
+	}
+
+	newAutoscaler := func(url string) *networkv1alpha1.GameAutoscaler {
+		path := "scale"
+		maxReplicas := int32(10)
+		desired := int32(3)
+		return &networkv1alpha1.GameAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default"},
+			Spec: networkv1alpha1.GameAutoscalerSpec{
+				GameName:    "game",
+				MaxReplicas: &maxReplicas,
+				AutoscalePolicy: networkv1alpha1.AutoscalePolicy{
+					Type: networkv1alpha1.Webhook,
+					WebhookAutoscalerSpec: networkv1alpha1.WebhookAutoscalerSpec{
+						Url:  &url,
+						Path: &path,
+					},
+				},
+			},
+			Status: networkv1alpha1.GameAutoscalerStatus{
+				LastDesiredReplicas: &desired,
+			},
+		}
+	}
+
+	Context("When summarizing the fleets", func() {
+		It("Counts the servers by state", func() {
+			states, totals, err := CollectFleetStates(ctx, newClient(), gametype)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(states).To(HaveLen(1))
+			Expect(states[0].Name).To(Equal("game-fleet"))
+			Expect(states[0].DesiredReplicas).To(BeEquivalentTo(3))
+			Expect(totals.Servers).To(Equal(3))
+			Expect(totals.Ready).To(Equal(2))
+			Expect(totals.Allocated).To(Equal(1))
+			Expect(totals.ShuttingDown).To(Equal(1))
+			Expect(totals.Players).To(HaveValue(BeEquivalentTo(15)))
+			Expect(totals.Counters).To(HaveKeyWithValue("matches", int64(3)))
+		})
+
+		It("Counts the servers allocated through the sidecar or the pod label", func() {
+			fleet := networkv1alpha1.Fleet{ObjectMeta: metav1.ObjectMeta{Name: "game-fleet"}}
+			pushed := *gameServer("server-1", 0, false)
+			pushed.Status.Sidecar.Allocated = true
+			labelled := *gameServer("server-2", 0, false)
+			free := *gameServer("server-3", 0, false)
+			pods := []corev1.Pod{*gamePod("server-1", true, false), *gamePod("server-2", true, true), *gamePod("server-3", true, false)}
+
+			state := SummarizeFleet(fleet, []networkv1alpha1.Server{pushed, labelled, free}, pods)
+			Expect(state.Allocated).To(Equal(2))
+			Expect(state.Ready).To(Equal(3))
+		})
+
+		It("Leaves out the players of servers that did not push their state", func() {
+			fleet := networkv1alpha1.Fleet{ObjectMeta: metav1.ObjectMeta{Name: "game-fleet"}}
+			pushed := *gameServer("server-1", 10, false)
+			polled := *gameServer("server-2", 5, false)
+			polled.Status.Sidecar = nil
+
+			state := SummarizeFleet(fleet, []networkv1alpha1.Server{pushed, polled}, nil)
+			Expect(state.Servers).To(Equal(2))
+			Expect(state.Players).To(HaveValue(BeEquivalentTo(10)))
+			Expect(state.Counters).To(HaveKeyWithValue("matches", int64(1)))
+
+			state = SummarizeFleet(fleet, []networkv1alpha1.Server{polled}, nil)
+			Expect(state.Players).To(BeNil())
+			Expect(state.Counters).To(BeNil())
+			body, err := json.Marshal(state)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).ToNot(ContainSubstring("players"))
+		})
+	})
+
+	Context("When sending the webhook request", func() {
+		It("Sends the version 2 payload by default", func() {
+			var received map[string]interface{}
+			server := newFakeWebhook(`{"scale":true,"desired_replicas":4,"protocol_version":2}`, &received)
+			defer server.Close()
+
+			response, err := ProductionWebhookRequest{Client: newClient()}.SendScaleWebhookRequest(newAutoscaler(server.URL), gametype)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.DesiredReplicas).To(Equal(4))
+			Expect(response.ProtocolVersion).To(Equal(2))
+
+			Expect(received).To(HaveKeyWithValue("game_name", "game"))
+			Expect(received).To(HaveKeyWithValue("current_replicas", BeEquivalentTo(3)))
+			Expect(received).To(HaveKeyWithValue("protocol_version", BeEquivalentTo(2)))
+			Expect(received).To(HaveKeyWithValue("max_replicas", BeEquivalentTo(10)))
+			Expect(received).To(HaveKey("fleets"))
+			Expect(received["totals"]).To(HaveKeyWithValue("allocated", BeEquivalentTo(1)))
+			Expect(received["last_decision"]).To(HaveKeyWithValue("desired_replicas", BeEquivalentTo(3)))
+		})
+
+		It("Sends the fleet when targeting a fleet", func() {
+			var received map[string]interface{}
+			server := newFakeWebhook(`{"scale":false,"desired_replicas":3}`, &received)
+			defer server.Close()
+
+			c := newClient()
+			fleet := &networkv1alpha1.Fleet{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "game-fleet", Namespace: "default"}, fleet)).To(Succeed())
+			autoscaler := newAutoscaler(server.URL)
+			autoscaler.Spec.GameName = ""
+			autoscaler.Spec.TargetRef = &networkv1alpha1.TargetRef{Kind: networkv1alpha1.FleetTarget, Name: "game-fleet"}
+			_, err := ProductionWebhookRequest{Client: c}.SendScaleWebhookRequest(autoscaler, fleet)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(received).To(HaveKeyWithValue("game_name", "game"))
+			Expect(received).To(HaveKeyWithValue("target_kind", "Fleet"))
+			Expect(received).To(HaveKeyWithValue("target_name", "game-fleet"))
+			Expect(received["fleets"]).To(HaveLen(1))
+			Expect(received["totals"]).To(HaveKeyWithValue("servers", BeEquivalentTo(3)))
+		})
+
+		It("Sends only the version 1 fields when pinned", func() {
+			var received map[string]interface{}
+			server := newFakeWebhook(`{"scale":false,"desired_replicas":3}`, &received)
+			defer server.Close()
+
+			autoscaler := newAutoscaler(server.URL)
+			version := int32(1)
+			autoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.ProtocolVersion = &version
+			response, err := ProductionWebhookRequest{Client: newClient()}.SendScaleWebhookRequest(autoscaler, gametype)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.ProtocolVersion).To(Equal(0))
+			Expect(received).To(HaveLen(2))
+		})
+
+		It("Verifies TLS with the CA bundle and sends the credentials", func() {
+			var headers http.Header
+			var body []byte
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				headers = r.Header.Clone()
+				body, _ = io.ReadAll(r.Body)
+				_, _ = w.Write([]byte(`{"scale":false,"desired_replicas":3}`))
+			}))
+			defer server.Close()
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "webhook-credentials",
+					Namespace: "default",
+					Labels:    map[string]string{networkv1alpha1.WebhookCredentialsLabel: "true"},
+				},
+				Data: map[string][]byte{
+					"token":   []byte("secret-token"),
+					"tenant":  []byte("eu-1"),
+					"signing": []byte("signing-key"),
+				},
+			}
+			sender := ProductionWebhookRequest{
+				Client:       newClient(),
+				SecretReader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(),
+			}
+			autoscaler := newAutoscaler(server.URL)
+
+			By("Failing without the CA bundle")
+			_, err := sender.SendScaleWebhookRequest(autoscaler, gametype)
+			Expect(err).To(HaveOccurred())
+
+			By("Succeeding with the CA bundle")
+			spec := &autoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec
+			spec.CABundle = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			spec.SecretRef = &networkv1alpha1.WebhookSecretRef{
+				Name:       "webhook-credentials",
+				TokenKey:   "token",
+				Headers:    map[string]string{"X-Tenant": "tenant"},
+				SigningKey: "signing",
+			}
+			_, err = sender.SendScaleWebhookRequest(autoscaler, gametype)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(headers.Get("Authorization")).To(Equal("Bearer secret-token"))
+			Expect(headers.Get("X-Tenant")).To(Equal("eu-1"))
+
+			By("Signing the body with the timestamp")
+			timestamp := headers.Get(WebhookTimestampHeader)
+			Expect(timestamp).ToNot(BeEmpty())
+			mac := hmac.New(sha256.New, []byte("signing-key"))
+			mac.Write([]byte(timestamp + "."))
+			mac.Write(body)
+			Expect(headers.Get(WebhookSignatureHeader)).To(Equal("sha256=" + hex.EncodeToString(mac.Sum(nil))))
+
+			By("Failing when the key is missing from the secret")
+			spec.SecretRef.TokenKey = "missing"
+			_, err = sender.SendScaleWebhookRequest(autoscaler, gametype)
+			Expect(err).To(HaveOccurred())
+
+			By("Failing when the secret is not labelled for webhook credentials")
+			spec.SecretRef.TokenKey = "token"
+			unlabelled := secret.DeepCopy()
+			unlabelled.Labels = nil
+			sender.SecretReader = fake.NewClientBuilder().WithScheme(scheme).WithObjects(unlabelled).Build()
+			_, err = sender.SendScaleWebhookRequest(autoscaler, gametype)
+			Expect(err).To(MatchError(ContainSubstring(networkv1alpha1.WebhookCredentialsLabel)))
+		})
+
+		It("Reuses the client of a CA bundle", func() {
+			server := httptest.NewTLSServer(http.NotFoundHandler())
+			defer server.Close()
+			caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+			first, err := newWebhookClient(caBundle)
+			Expect(err).ToNot(HaveOccurred())
+			second, err := newWebhookClient(append([]byte{}, caBundle...))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(second).To(BeIdenticalTo(first))
+			Expect(first.Transport.(*http.Transport).Proxy).ToNot(BeNil())
+
+			_, err = newWebhookClient([]byte("not a certificate"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Rejects unknown protocol versions", func() {
+			var received map[string]interface{}
+			server := newFakeWebhook(`{"scale":true,"desired_replicas":4,"protocol_version":99}`, &received)
+			defer server.Close()
+
+			_, err := ProductionWebhookRequest{Client: newClient()}.SendScaleWebhookRequest(newAutoscaler(server.URL), gametype)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
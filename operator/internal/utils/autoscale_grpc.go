@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	autoscalerv1 "github.com/unfamousthomas/thesis-operator/api/proto/autoscaler/v1"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
+	"sync"
+)
+
+// sendGrpcRequest calls the Scale method of the autoscaler gRPC service of the webhook.
+// The deadline of the context is sent along with the call, so the server knows how long it has to answer.
+func (w ProductionWebhookRequest) sendGrpcRequest(ctx context.Context, namespace string,
+	spec networkv1alpha1.WebhookAutoscalerSpec, request AutoscaleRequest) (AutoscaleResponse, error) {
+	address, secure, err := grpcAddress(spec)
+	if err != nil {
+		return AutoscaleResponse{}, err
+	}
+	conn, err := grpcConn(address, secure, spec.CABundle)
+	if err != nil {
+		return AutoscaleResponse{}, err
+	}
+
+	if spec.SecretRef != nil {
+		headers, _, err := w.readCredentials(ctx, namespace, spec.SecretRef)
+		if err != nil {
+			return AutoscaleResponse{}, fmt.Errorf("failed to add credentials: %w", err)
+		}
+		md := metadata.MD{}
+		for header, value := range headers {
+			md.Set(header, value)
+		}
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+
+	response, err := autoscalerv1.NewAutoscalerClient(conn).Scale(ctx, toProtoRequest(request))
+	if err != nil {
+		return AutoscaleResponse{}, err
+	}
+	if response.ProtocolVersion > AutoscaleProtocolVersion {
+		return AutoscaleResponse{}, fmt.Errorf("webhook responded with unsupported protocol version %d", response.ProtocolVersion)
+	}
+	return AutoscaleResponse{
+		Scale:           response.Scale,
+		DesiredReplicas: int(response.DesiredReplicas),
+		ProtocolVersion: int(response.ProtocolVersion),
+	}, nil
+}
+
+// grpcConnKey identifies a cached connection. The CA bundle is part of it, so a changed bundle gets a new connection.
+type grpcConnKey struct {
+	address  string
+	secure   bool
+	caBundle [sha256.Size]byte
+}
+
+// grpcConns caches the connections to the gRPC webhooks, so an evaluation does not pay for a new TCP and TLS handshake.
+// Connections that are no longer used go idle and close their transports on their own.
+var grpcConns = struct {
+	sync.Mutex
+	conns map[grpcConnKey]*grpc.ClientConn
+}{conns: make(map[grpcConnKey]*grpc.ClientConn)}
+
+// grpcConn returns the connection to the webhook at the address, creating it if there is none yet
+func grpcConn(address string, secure bool, caBundle []byte) (*grpc.ClientConn, error) {
+	key := grpcConnKey{address: address, secure: secure, caBundle: sha256.Sum256(caBundle)}
+	grpcConns.Lock()
+	defer grpcConns.Unlock()
+	if conn, ok := grpcConns.conns[key]; ok {
+		return conn, nil
+	}
+
+	transportCredentials := insecure.NewCredentials()
+	if secure {
+		tlsConfig, err := newWebhookTLSConfig(caBundle)
+		if err != nil {
+			return nil, err
+		}
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return nil, err
+	}
+	grpcConns.conns[key] = conn
+	return conn, nil
+}
+
+// grpcAddress returns the address to dial and whether TLS has to be used.
+// TLS is used for https urls and services, or whenever a CA bundle is set.
+func grpcAddress(spec networkv1alpha1.WebhookAutoscalerSpec) (string, bool, error) {
+	secure := len(spec.CABundle) > 0
+	if spec.Url != nil {
+		address := *spec.Url
+		switch {
+		case strings.HasPrefix(address, "https://"):
+			address, secure = strings.TrimPrefix(address, "https://"), true
+		case strings.HasPrefix(address, "http://"):
+			address = strings.TrimPrefix(address, "http://")
+		}
+		return strings.TrimSuffix(address, "/"), secure, nil
+	}
+	service := spec.Service
+	if service == nil {
+		return "", false, fmt.Errorf("missing url or service")
+	}
+	if service.Scheme == "https" {
+		secure = true
+	}
+	return fmt.Sprintf("%s.%s.svc.cluster.local:%d", service.Name, service.Namespace, service.Port), secure, nil
+}
+
+// toProtoRequest converts the request to its protobuf message
+func toProtoRequest(request AutoscaleRequest) *autoscalerv1.AutoscaleRequest {
+	message := &autoscalerv1.AutoscaleRequest{
+		GameName:        request.GameName,
+		CurrentReplicas: int32(request.CurrentReplicas),
+		ProtocolVersion: int32(request.ProtocolVersion),
+		Namespace:       request.Namespace,
+		TargetKind:      request.TargetKind,
+		TargetName:      request.TargetName,
+		MinReplicas:     request.MinReplicas,
+		MaxReplicas:     request.MaxReplicas,
+	}
+	for _, fleet := range request.Fleets {
+		message.Fleets = append(message.Fleets, &autoscalerv1.FleetState{
+			Name:            fleet.Name,
+			DesiredReplicas: fleet.DesiredReplicas,
+			Counts:          toProtoCounts(fleet.ServerCounts),
+		})
+	}
+	if request.Totals != nil {
+		message.Totals = toProtoCounts(*request.Totals)
+	}
+	if request.LastDecision != nil {
+		message.LastDecision = &autoscalerv1.AutoscaleDecision{
+			DesiredReplicas:     request.LastDecision.DesiredReplicas,
+			ConsecutiveFailures: request.LastDecision.ConsecutiveFailures,
+		}
+		if request.LastDecision.ScaleTime != nil {
+			message.LastDecision.ScaleTime = timestamppb.New(*request.LastDecision.ScaleTime)
+		}
+	}
+	return message
+}
+
+// toProtoCounts converts the counts. The players are sent as 0 when no server reported them.
+func toProtoCounts(counts ServerCounts) *autoscalerv1.ServerCounts {
+	var players int64
+	if counts.Players != nil {
+		players = *counts.Players
+	}
+	return &autoscalerv1.ServerCounts{
+		Servers:      int64(counts.Servers),
+		Ready:        int64(counts.Ready),
+		Allocated:    int64(counts.Allocated),
+		ShuttingDown: int64(counts.ShuttingDown),
+		Players:      players,
+		Counters:     counts.Counters,
+	}
+}
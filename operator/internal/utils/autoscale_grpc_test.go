@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"context"
+	"encoding/pem"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	autoscalerv1 "github.com/unfamousthomas/thesis-operator/api/proto/autoscaler/v1"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"time"
+)
+
+// fakeGrpcAutoscaler saves the request, its metadata and deadline and responds with the given response
+type fakeGrpcAutoscaler struct {
+	autoscalerv1.UnimplementedAutoscalerServer
+	response *autoscalerv1.AutoscaleResponse
+	request  *autoscalerv1.AutoscaleRequest
+	metadata metadata.MD
+	deadline time.Duration
+}
+
+func (f *fakeGrpcAutoscaler) Scale(ctx context.Context, request *autoscalerv1.AutoscaleRequest) (*autoscalerv1.AutoscaleResponse, error) {
+	f.request = request
+	f.metadata, _ = metadata.FromIncomingContext(ctx)
+	if deadline, exists := ctx.Deadline(); exists {
+		f.deadline = time.Until(deadline)
+	}
+	return f.response, nil
+}
+
+var _ = Describe("Autoscale gRPC Testing", func() {
+	scheme := runtime.NewScheme()
+	Expect(networkv1alpha1.AddToScheme(scheme)).To(Succeed())
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	gametype := &networkv1alpha1.GameType{
+		ObjectMeta: metav1.ObjectMeta{Name: "game", Namespace: "default"},
+	}
+	gametype.Spec.FleetSpec.Scaling.Replicas = 3
+
+	It("Verifies TLS with the CA bundle and sends the request with a deadline", func() {
+		// The test server of net/http has a certificate for 127.0.0.1 that can be reused
+		tlsServer := httptest.NewTLSServer(nil)
+		certificate := tlsServer.TLS.Certificates[0]
+		caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+		tlsServer.Close()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		fakeAutoscaler := &fakeGrpcAutoscaler{
+			response: &autoscalerv1.AutoscaleResponse{Scale: true, DesiredReplicas: 5, ProtocolVersion: 2},
+		}
+		server := grpc.NewServer(grpc.Creds(credentials.NewServerTLSFromCert(&certificate)))
+		autoscalerv1.RegisterAutoscalerServer(server, fakeAutoscaler)
+		go func() {
+			_ = server.Serve(listener)
+		}()
+		defer server.Stop()
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "webhook-credentials",
+				Namespace: "default",
+				Labels:    map[string]string{networkv1alpha1.WebhookCredentialsLabel: "true"},
+			},
+			Data: map[string][]byte{"token": []byte("secret-token")},
+		}
+		fleet := &networkv1alpha1.Fleet{
+			ObjectMeta: metav1.ObjectMeta{Name: "game-fleet", Namespace: "default", Labels: map[string]string{"type": "game"}},
+		}
+		fleet.Spec.Scaling.Replicas = 3
+		sender := ProductionWebhookRequest{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(fleet,
+				gameServer("server-1", 10, false), gamePod("server-1", true, true)).Build(),
+			SecretReader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(),
+		}
+		url := "https://" + listener.Addr().String()
+		autoscaler := &networkv1alpha1.GameAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default"},
+			Spec: networkv1alpha1.GameAutoscalerSpec{
+				GameName: "game",
+				AutoscalePolicy: networkv1alpha1.AutoscalePolicy{
+					Type: networkv1alpha1.Webhook,
+					WebhookAutoscalerSpec: networkv1alpha1.WebhookAutoscalerSpec{
+						Url:       &url,
+						Transport: networkv1alpha1.GrpcTransport,
+						Timeout:   &metav1.Duration{Duration: 3 * time.Second},
+						SecretRef: &networkv1alpha1.WebhookSecretRef{Name: "webhook-credentials", TokenKey: "token"},
+					},
+				},
+			},
+		}
+
+		By("Failing without the CA bundle")
+		_, err = sender.SendScaleWebhookRequest(autoscaler, gametype)
+		Expect(err).To(HaveOccurred())
+
+		By("Succeeding with the CA bundle")
+		autoscaler.Spec.AutoscalePolicy.WebhookAutoscalerSpec.CABundle = caBundle
+		response, err := sender.SendScaleWebhookRequest(autoscaler, gametype)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).To(Equal(AutoscaleResponse{Scale: true, DesiredReplicas: 5, ProtocolVersion: 2}))
+
+		Expect(fakeAutoscaler.request.GameName).To(Equal("game"))
+		Expect(fakeAutoscaler.request.CurrentReplicas).To(Equal(int32(3)))
+		Expect(fakeAutoscaler.request.TargetKind).To(Equal(string(networkv1alpha1.GameTypeTarget)))
+		Expect(fakeAutoscaler.request.Fleets).To(HaveLen(1))
+		Expect(fakeAutoscaler.request.Totals.Allocated).To(Equal(int64(1)))
+		Expect(fakeAutoscaler.metadata.Get("authorization")).To(ConsistOf("Bearer secret-token"))
+		Expect(fakeAutoscaler.deadline).To(BeNumerically("~", 3*time.Second, time.Second))
+
+		By("Reusing the connection of the webhook")
+		conn, err := grpcConn(listener.Addr().String(), true, caBundle)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = sender.SendScaleWebhookRequest(autoscaler, gametype)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(grpcConn(listener.Addr().String(), true, caBundle)).To(BeIdenticalTo(conn))
+		Expect(grpcConn(listener.Addr().String(), true, nil)).ToNot(BeIdenticalTo(conn))
+
+		By("Rejecting unknown protocol versions")
+		fakeAutoscaler.response = &autoscalerv1.AutoscaleResponse{Scale: true, DesiredReplicas: 5, ProtocolVersion: 99}
+		_, err = sender.SendScaleWebhookRequest(autoscaler, gametype)
+		Expect(err).To(HaveOccurred())
+	})
+})
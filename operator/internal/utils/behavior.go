@@ -0,0 +1,177 @@
+package utils
+
+import (
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"math"
+	"slices"
+	"time"
+)
+
+// StabilizeReplicas applies the behavior of the autoscaler to the replica count recommended by the policy.
+// The recommendation is saved to the status, so later syncs can use it in their stabilization windows.
+// It returns the replica count to scale to and, if the recommendation was changed, the reason why.
+func StabilizeReplicas(behavior *networkv1alpha1.ScalingBehavior, status *networkv1alpha1.GameAutoscalerStatus,
+	current int32, recommended int32, now time.Time) (int32, string) {
+	if behavior == nil {
+		status.Recommendations = nil
+		status.ScaleEvents = nil
+		return recommended, ""
+	}
+
+	//Save the recommendation and drop the ones that are outside both windows
+	upWindow := stabilizationWindow(behavior.ScaleUp)
+	downWindow := stabilizationWindow(behavior.ScaleDown)
+	longestWindow := max(upWindow, downWindow)
+	status.Recommendations = append(status.Recommendations, networkv1alpha1.ScaleRecommendation{
+		Time:     metav1.NewTime(now),
+		Replicas: recommended,
+	})
+	status.Recommendations = slices.DeleteFunc(status.Recommendations, func(recommendation networkv1alpha1.ScaleRecommendation) bool {
+		return now.Sub(recommendation.Time.Time) > longestWindow
+	})
+
+	//Only scale up as far as every recommendation in the window agrees, and the same for scaling down
+	upRecommendation, downRecommendation := recommended, recommended
+	for _, recommendation := range status.Recommendations {
+		age := now.Sub(recommendation.Time.Time)
+		if age <= upWindow {
+			upRecommendation = min(upRecommendation, recommendation.Replicas)
+		}
+		if age <= downWindow {
+			downRecommendation = max(downRecommendation, recommendation.Replicas)
+		}
+	}
+	desired := current
+	if desired < upRecommendation {
+		desired = upRecommendation
+	}
+	if desired > downRecommendation {
+		desired = downRecommendation
+	}
+	reason := ""
+	if desired != recommended {
+		reason = "Stabilized"
+	}
+
+	switch {
+	case desired > current:
+		if limited, limitReason := limitChange(behavior.ScaleUp, status, current, desired, now, true); limitReason != "" {
+			return limited, "ScaleUp" + limitReason
+		}
+	case desired < current:
+		if limited, limitReason := limitChange(behavior.ScaleDown, status, current, desired, now, false); limitReason != "" {
+			return limited, "ScaleDown" + limitReason
+		}
+	}
+	return desired, reason
+}
+
+// RecordScaleEvent saves the change to the status, so the cooldowns and policies can limit the changes that follow
+func RecordScaleEvent(behavior *networkv1alpha1.ScalingBehavior, status *networkv1alpha1.GameAutoscalerStatus, change int32, now time.Time) {
+	switch {
+	case change > 0:
+		status.LastScaleUpTime = &metav1.Time{Time: now}
+	case change < 0:
+		status.LastScaleDownTime = &metav1.Time{Time: now}
+	}
+	if behavior == nil || change == 0 {
+		return
+	}
+	longestPeriod := max(longestPeriod(behavior.ScaleUp), longestPeriod(behavior.ScaleDown))
+	status.ScaleEvents = append(status.ScaleEvents, networkv1alpha1.ScaleEvent{
+		Time:   metav1.NewTime(now),
+		Change: change,
+	})
+	status.ScaleEvents = slices.DeleteFunc(status.ScaleEvents, func(event networkv1alpha1.ScaleEvent) bool {
+		return now.Sub(event.Time.Time) > longestPeriod
+	})
+}
+
+// limitChange applies the cooldown and policies of one direction to the desired replica count
+func limitChange(rules *networkv1alpha1.ScalingRules, status *networkv1alpha1.GameAutoscalerStatus,
+	current int32, desired int32, now time.Time, up bool) (int32, string) {
+	if rules == nil {
+		return desired, ""
+	}
+	if rules.SelectPolicy == networkv1alpha1.DisabledPolicySelect {
+		return current, "Disabled"
+	}
+	lastScale := status.LastScaleDownTime
+	if up {
+		lastScale = status.LastScaleUpTime
+	}
+	if rules.Cooldown != nil && lastScale != nil && now.Before(lastScale.Add(rules.Cooldown.Duration)) {
+		return current, "Cooldown"
+	}
+	if len(rules.Policies) == 0 {
+		return desired, ""
+	}
+
+	//Max allows the largest change, which is the highest limit when scaling up and the lowest when scaling down
+	preferHigher := up == (rules.SelectPolicy != networkv1alpha1.MinChangePolicySelect)
+	var limit int32
+	for i, policy := range rules.Policies {
+		policyLimit := policyLimit(policy, status.ScaleEvents, current, now, up)
+		if i == 0 || (preferHigher && policyLimit > limit) || (!preferHigher && policyLimit < limit) {
+			limit = policyLimit
+		}
+	}
+
+	if up && desired > limit {
+		return max(limit, current), "Limit"
+	}
+	if !up && desired < limit {
+		return min(limit, current), "Limit"
+	}
+	return desired, ""
+}
+
+// policyLimit returns how far the policy allows scaling, based on the replicas at the start of its period
+func policyLimit(policy networkv1alpha1.ScalingPolicy, events []networkv1alpha1.ScaleEvent, current int32, now time.Time, up bool) int32 {
+	var changed int32
+	for _, event := range events {
+		if now.Sub(event.Time.Time) > policy.Period.Duration {
+			continue
+		}
+		if up && event.Change > 0 {
+			changed += event.Change
+		}
+		if !up && event.Change < 0 {
+			changed -= event.Change
+		}
+	}
+
+	if up {
+		periodStart := current - changed
+		if policy.Type == networkv1alpha1.PercentScalingPolicy {
+			//At least one replica can be added, so a game can still scale up from zero
+			return max(int32(math.Ceil(float64(periodStart)*(1+float64(policy.Value)/100))), periodStart+1)
+		}
+		return periodStart + policy.Value
+	}
+
+	periodStart := current + changed
+	if policy.Type == networkv1alpha1.PercentScalingPolicy {
+		return max(int32(math.Floor(float64(periodStart)*(1-float64(policy.Value)/100))), 0)
+	}
+	return max(periodStart-policy.Value, 0)
+}
+
+func stabilizationWindow(rules *networkv1alpha1.ScalingRules) time.Duration {
+	if rules == nil || rules.StabilizationWindow == nil {
+		return 0
+	}
+	return rules.StabilizationWindow.Duration
+}
+
+func longestPeriod(rules *networkv1alpha1.ScalingRules) time.Duration {
+	var longest time.Duration
+	if rules == nil {
+		return longest
+	}
+	for _, policy := range rules.Policies {
+		longest = max(longest, policy.Period.Duration)
+	}
+	return longest
+}
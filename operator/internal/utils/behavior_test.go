@@ -0,0 +1,161 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+var _ = Describe("Scaling Behavior Testing", func() {
+	now := time.Date(2024, 10, 19, 18, 0, 0, 0, time.UTC)
+
+	Context("When stabilizing the recommendation", func() {
+		It("Keeps the recommendation without a behavior", func() {
+			status := &networkv1alpha1.GameAutoscalerStatus{}
+			desired, reason := StabilizeReplicas(nil, status, 10, 100, now)
+			Expect(desired).To(BeEquivalentTo(100))
+			Expect(reason).To(BeEmpty())
+			Expect(status.Recommendations).To(BeEmpty())
+		})
+
+		It("Scales down to the highest recommendation in the window", func() {
+			behavior := &networkv1alpha1.ScalingBehavior{
+				ScaleDown: &networkv1alpha1.ScalingRules{
+					StabilizationWindow: &metav1.Duration{Duration: 5 * time.Minute},
+				},
+			}
+			status := &networkv1alpha1.GameAutoscalerStatus{
+				Recommendations: []networkv1alpha1.ScaleRecommendation{
+					{Time: metav1.NewTime(now.Add(-10 * time.Minute)), Replicas: 100},
+					{Time: metav1.NewTime(now.Add(-2 * time.Minute)), Replicas: 40},
+				},
+			}
+			desired, reason := StabilizeReplicas(behavior, status, 100, 10, now)
+			Expect(desired).To(BeEquivalentTo(40))
+			Expect(reason).To(Equal("Stabilized"))
+			Expect(status.Recommendations).To(HaveLen(2))
+		})
+
+		It("Scales up to the lowest recommendation in the window", func() {
+			behavior := &networkv1alpha1.ScalingBehavior{
+				ScaleUp: &networkv1alpha1.ScalingRules{
+					StabilizationWindow: &metav1.Duration{Duration: time.Minute},
+				},
+			}
+			status := &networkv1alpha1.GameAutoscalerStatus{
+				Recommendations: []networkv1alpha1.ScaleRecommendation{
+					{Time: metav1.NewTime(now.Add(-30 * time.Second)), Replicas: 20},
+				},
+			}
+			desired, _ := StabilizeReplicas(behavior, status, 10, 100, now)
+			Expect(desired).To(BeEquivalentTo(20))
+		})
+	})
+
+	Context("When limiting the change", func() {
+		It("Uses the policy that allows the largest change", func() {
+			behavior := &networkv1alpha1.ScalingBehavior{
+				ScaleUp: &networkv1alpha1.ScalingRules{
+					Policies: []networkv1alpha1.ScalingPolicy{
+						{Type: networkv1alpha1.ReplicasScalingPolicy, Value: 4, Period: metav1.Duration{Duration: time.Minute}},
+						{Type: networkv1alpha1.PercentScalingPolicy, Value: 100, Period: metav1.Duration{Duration: time.Minute}},
+					},
+				},
+			}
+			desired, reason := StabilizeReplicas(behavior, &networkv1alpha1.GameAutoscalerStatus{}, 10, 100, now)
+			Expect(desired).To(BeEquivalentTo(20))
+			Expect(reason).To(Equal("ScaleUpLimit"))
+
+			behavior.ScaleUp.SelectPolicy = networkv1alpha1.MinChangePolicySelect
+			desired, _ = StabilizeReplicas(behavior, &networkv1alpha1.GameAutoscalerStatus{}, 10, 100, now)
+			Expect(desired).To(BeEquivalentTo(14))
+		})
+
+		It("Counts the changes within the period", func() {
+			behavior := &networkv1alpha1.ScalingBehavior{
+				ScaleDown: &networkv1alpha1.ScalingRules{
+					Policies: []networkv1alpha1.ScalingPolicy{
+						{Type: networkv1alpha1.ReplicasScalingPolicy, Value: 5, Period: metav1.Duration{Duration: time.Minute}},
+					},
+				},
+			}
+			status := &networkv1alpha1.GameAutoscalerStatus{}
+			RecordScaleEvent(behavior, status, -10, now.Add(-2*time.Minute))
+			RecordScaleEvent(behavior, status, -3, now.Add(-30*time.Second))
+			Expect(status.ScaleEvents).To(HaveLen(1))
+
+			desired, reason := StabilizeReplicas(behavior, status, 20, 0, now)
+			Expect(desired).To(BeEquivalentTo(18))
+			Expect(reason).To(Equal("ScaleDownLimit"))
+		})
+
+		It("Waits for the cooldown", func() {
+			behavior := &networkv1alpha1.ScalingBehavior{
+				ScaleUp: &networkv1alpha1.ScalingRules{
+					Cooldown: &metav1.Duration{Duration: 3 * time.Minute},
+				},
+			}
+			status := &networkv1alpha1.GameAutoscalerStatus{
+				LastScaleUpTime: &metav1.Time{Time: now.Add(-time.Minute)},
+			}
+			desired, reason := StabilizeReplicas(behavior, status, 10, 15, now)
+			Expect(desired).To(BeEquivalentTo(10))
+			Expect(reason).To(Equal("ScaleUpCooldown"))
+
+			By("Not affecting the other direction")
+			desired, _ = StabilizeReplicas(behavior, status, 10, 5, now.Add(time.Second))
+			Expect(desired).To(BeEquivalentTo(5))
+		})
+
+		It("Keeps a separate cooldown for each direction", func() {
+			behavior := &networkv1alpha1.ScalingBehavior{
+				ScaleUp: &networkv1alpha1.ScalingRules{
+					Cooldown: &metav1.Duration{Duration: 3 * time.Minute},
+				},
+				ScaleDown: &networkv1alpha1.ScalingRules{
+					Cooldown: &metav1.Duration{Duration: 5 * time.Minute},
+				},
+			}
+			status := &networkv1alpha1.GameAutoscalerStatus{}
+
+			By("Scaling up")
+			desired, reason := StabilizeReplicas(behavior, status, 10, 15, now)
+			Expect(desired).To(BeEquivalentTo(15))
+			Expect(reason).To(BeEmpty())
+			RecordScaleEvent(behavior, status, desired-10, now)
+			Expect(status.LastScaleUpTime.Time).To(Equal(now))
+			Expect(status.LastScaleDownTime).To(BeNil())
+
+			By("Scaling down right after, as only the scale up cooldown is running")
+			later := now.Add(time.Minute)
+			desired, reason = StabilizeReplicas(behavior, status, 15, 12, later)
+			Expect(desired).To(BeEquivalentTo(12))
+			Expect(reason).To(BeEmpty())
+			RecordScaleEvent(behavior, status, desired-15, later)
+			Expect(status.LastScaleDownTime.Time).To(Equal(later))
+
+			By("Waiting for the cooldown of each direction")
+			desired, reason = StabilizeReplicas(behavior, status, 12, 14, later.Add(time.Minute))
+			Expect(desired).To(BeEquivalentTo(12))
+			Expect(reason).To(Equal("ScaleUpCooldown"))
+			desired, reason = StabilizeReplicas(behavior, status, 12, 10, now.Add(4*time.Minute))
+			Expect(desired).To(BeEquivalentTo(12))
+			Expect(reason).To(Equal("ScaleDownCooldown"))
+			desired, _ = StabilizeReplicas(behavior, status, 12, 14, now.Add(5*time.Minute))
+			Expect(desired).To(BeEquivalentTo(14))
+		})
+
+		It("Can disable a direction", func() {
+			behavior := &networkv1alpha1.ScalingBehavior{
+				ScaleDown: &networkv1alpha1.ScalingRules{
+					SelectPolicy: networkv1alpha1.DisabledPolicySelect,
+				},
+			}
+			desired, reason := StabilizeReplicas(behavior, &networkv1alpha1.GameAutoscalerStatus{}, 10, 5, now)
+			Expect(desired).To(BeEquivalentTo(10))
+			Expect(reason).To(Equal("ScaleDownDisabled"))
+		})
+	})
+})
@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"slices"
+)
+
+// CapacityClaim is the demand of a GameType on a capacity pool
+type CapacityClaim struct {
+	Namespace          string
+	Name               string
+	Priority           int32
+	GuaranteedReplicas int32
+	// The replicas the GameType wants
+	Replicas int32
+	// The servers of the GameType, including the servers of fleets being deleted
+	Servers int32
+	// The servers of fleets being deleted, they keep their capacity until they are gone
+	Draining int32
+	// The resources requested by one server
+	Requests corev1.ResourceList
+}
+
+// desiredServers returns the servers the GameType wants, the draining servers are kept until they are gone
+func (c CapacityClaim) desiredServers() int32 {
+	return c.Replicas + c.Draining
+}
+
+// ArbitrateCapacity returns the replicas granted to every claim by the pool.
+// Existing servers are never taken away, only the scale-ups are limited. The free capacity is first held back
+// for the guaranteed replicas, then handed out to the claims by priority, one server at a time to claims
+// of the same priority. Servers requesting none of the limited resources are only limited by maxServers.
+func ArbitrateCapacity(spec networkv1alpha1.CapacityPoolSpec, claims []CapacityClaim) []int32 {
+	budget := newCapacityBudget(spec)
+	for _, claim := range claims {
+		budget.take(claim.Requests, claim.Servers)
+	}
+
+	granted := make([]int32, len(claims))
+	for i, claim := range claims {
+		granted[i] = min(claim.Servers, claim.desiredServers())
+	}
+	order := make([]int, len(claims))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(claims[b].Priority, claims[a].Priority)
+	})
+
+	//Hold back the guaranteed replicas, even the ones that are not wanted right now
+	for _, i := range order {
+		claim := claims[i]
+		var held int32
+		for claim.Servers+held < claim.GuaranteedReplicas+claim.Draining && budget.fits(claim.Requests) {
+			budget.take(claim.Requests, 1)
+			held++
+		}
+		granted[i] += min(held, max(claim.desiredServers()-claim.Servers, 0))
+	}
+
+	//Hand out the rest by priority
+	for start := 0; start < len(order); {
+		end := start
+		for end < len(order) && claims[order[end]].Priority == claims[order[start]].Priority {
+			end++
+		}
+		for progress := true; progress; {
+			progress = false
+			for _, i := range order[start:end] {
+				if granted[i] < claims[i].desiredServers() && budget.fits(claims[i].Requests) {
+					budget.take(claims[i].Requests, 1)
+					granted[i]++
+					progress = true
+				}
+			}
+		}
+		start = end
+	}
+
+	for i, claim := range claims {
+		granted[i] -= claim.Draining
+	}
+	return granted
+}
+
+// CollectCapacityClaims finds the GameTypes selected by the pool and what they want from it
+func CollectCapacityClaims(ctx context.Context, c client.Reader, pool *networkv1alpha1.CapacityPool) ([]CapacityClaim, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&pool.Spec.GameTypeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gameTypeSelector: %w", err)
+	}
+	gametypes := &networkv1alpha1.GameTypeList{}
+	if err := c.List(ctx, gametypes, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list the gametypes: %w", err)
+	}
+	slices.SortFunc(gametypes.Items, func(a, b networkv1alpha1.GameType) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	claims := make([]CapacityClaim, 0, len(gametypes.Items))
+	for _, gametype := range gametypes.Items {
+		claim, err := collectCapacityClaim(ctx, c, &gametype)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, claim)
+	}
+	return claims, nil
+}
+
+// GrantedReplicas returns how many of the replicas the capacity pools selecting the GameType grant it.
+// When a pool grants fewer replicas, the name of the most limiting pool is returned as well.
+func GrantedReplicas(ctx context.Context, c client.Reader, gametype *networkv1alpha1.GameType, replicas int32) (int32, string, error) {
+	pools := &networkv1alpha1.CapacityPoolList{}
+	if err := c.List(ctx, pools); err != nil {
+		return replicas, "", fmt.Errorf("failed to list the capacity pools: %w", err)
+	}
+
+	granted, limitingPool := replicas, ""
+	for _, pool := range pools.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&pool.Spec.GameTypeSelector)
+		if err != nil || !selector.Matches(labels.Set(gametype.Labels)) {
+			continue
+		}
+		claims, err := CollectCapacityClaims(ctx, c, &pool)
+		if err != nil {
+			return replicas, "", err
+		}
+		index := slices.IndexFunc(claims, func(claim CapacityClaim) bool {
+			return claim.Namespace == gametype.Namespace && claim.Name == gametype.Name
+		})
+		if index < 0 {
+			continue
+		}
+		claims[index].Replicas = replicas
+		if poolGranted := ArbitrateCapacity(pool.Spec, claims)[index]; poolGranted < granted {
+			granted, limitingPool = poolGranted, pool.Name
+		}
+	}
+	return granted, limitingPool, nil
+}
+
+// FleetCapacity returns how many servers the fleet may have under the capacity pools of its GameType.
+// Fleets without a GameType are not part of any pool.
+func FleetCapacity(ctx context.Context, c client.Reader, fleet *networkv1alpha1.Fleet) (int32, string, error) {
+	replicas := fleet.Spec.Scaling.Replicas
+	name := fleet.Labels["type"]
+	if name == "" {
+		return replicas, "", nil
+	}
+	gametype := &networkv1alpha1.GameType{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: fleet.Namespace}, gametype); err != nil {
+		if apierrors.IsNotFound(err) {
+			return replicas, "", nil
+		}
+		return replicas, "", fmt.Errorf("failed to get the gametype: %w", err)
+	}
+	return GrantedReplicas(ctx, c, gametype, replicas)
+}
+
+// ServerRequests returns the resources requested by the containers of one server
+func ServerRequests(spec *corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			total := requests[name]
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+	return requests
+}
+
+// UsedCapacity returns the servers of the claims and the resources they request
+func UsedCapacity(claims []CapacityClaim) (int32, corev1.ResourceList) {
+	var servers int32
+	requests := corev1.ResourceList{}
+	for _, claim := range claims {
+		servers += claim.Servers
+		for name, quantity := range claim.Requests {
+			total := requests[name]
+			total.Add(*resource.NewMilliQuantity(quantity.MilliValue()*int64(claim.Servers), quantity.Format))
+			requests[name] = total
+		}
+	}
+	return servers, requests
+}
+
+// collectCapacityClaim counts the servers of the GameType, the servers of fleets being deleted are draining
+func collectCapacityClaim(ctx context.Context, c client.Reader, gametype *networkv1alpha1.GameType) (CapacityClaim, error) {
+	claim := CapacityClaim{
+		Namespace: gametype.Namespace,
+		Name:      gametype.Name,
+		Replicas:  gametype.GetReplicas(),
+		Requests:  ServerRequests(&gametype.Spec.FleetSpec.ServerSpec.Pod),
+	}
+	if gametype.Spec.Capacity != nil {
+		claim.Priority = gametype.Spec.Capacity.Priority
+		claim.GuaranteedReplicas = gametype.Spec.Capacity.GuaranteedReplicas
+	}
+
+	matchingType := client.MatchingLabels{"type": gametype.Name}
+	fleets := &networkv1alpha1.FleetList{}
+	if err := c.List(ctx, fleets, client.InNamespace(gametype.Namespace), matchingType); err != nil {
+		return claim, fmt.Errorf("failed to list the fleets of %s: %w", gametype.Name, err)
+	}
+	servers := &networkv1alpha1.ServerList{}
+	if err := c.List(ctx, servers, client.InNamespace(gametype.Namespace), matchingType); err != nil {
+		return claim, fmt.Errorf("failed to list the servers of %s: %w", gametype.Name, err)
+	}
+	draining := map[string]bool{}
+	for _, fleet := range fleets.Items {
+		if fleet.DeletionTimestamp != nil {
+			draining[fleet.Name] = true
+		}
+	}
+	claim.Servers = int32(len(servers.Items))
+	for _, server := range servers.Items {
+		if draining[server.Labels["fleet"]] {
+			claim.Draining++
+		}
+	}
+	return claim, nil
+}
+
+// capacityBudget is the free capacity of a pool, the resources are counted in thousandths
+type capacityBudget struct {
+	servers   *int64
+	resources map[corev1.ResourceName]int64
+}
+
+func newCapacityBudget(spec networkv1alpha1.CapacityPoolSpec) *capacityBudget {
+	budget := &capacityBudget{resources: map[corev1.ResourceName]int64{}}
+	if spec.MaxServers != nil {
+		servers := int64(*spec.MaxServers)
+		budget.servers = &servers
+	}
+	for name, quantity := range spec.MaxResources {
+		budget.resources[name] = quantity.MilliValue()
+	}
+	return budget
+}
+
+// fits returns whether one more server with the requests fits in the budget
+func (b *capacityBudget) fits(requests corev1.ResourceList) bool {
+	if b.servers != nil && *b.servers < 1 {
+		return false
+	}
+	for name, free := range b.resources {
+		if request := requests[name]; request.MilliValue() > free {
+			return false
+		}
+	}
+	return true
+}
+
+// take removes the servers with the requests from the budget, an overcommitted budget goes negative
+func (b *capacityBudget) take(requests corev1.ResourceList, servers int32) {
+	if b.servers != nil {
+		*b.servers -= int64(servers)
+	}
+	for name := range b.resources {
+		request := requests[name]
+		b.resources[name] -= request.MilliValue() * int64(servers)
+	}
+}
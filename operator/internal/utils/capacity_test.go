@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func int32Pointer(value int32) *int32 {
+	return &value
+}
+
+var _ = Describe("Capacity Pool Testing", func() {
+	Context("When arbitrating the pool", func() {
+		maxServers := func(servers int32) networkv1alpha1.CapacityPoolSpec {
+			return networkv1alpha1.CapacityPoolSpec{MaxServers: int32Pointer(servers)}
+		}
+
+		It("Hands out the free capacity by priority", func() {
+			claims := []CapacityClaim{
+				{Name: "low", Servers: 2, Replicas: 8},
+				{Name: "high", Priority: 10, Servers: 2, Replicas: 8},
+			}
+			Expect(ArbitrateCapacity(maxServers(10), claims)).To(Equal([]int32{2, 8}))
+		})
+
+		It("Shares the capacity between claims of the same priority", func() {
+			claims := []CapacityClaim{
+				{Name: "first", Replicas: 5},
+				{Name: "second", Replicas: 5},
+			}
+			Expect(ArbitrateCapacity(maxServers(6), claims)).To(Equal([]int32{3, 3}))
+		})
+
+		It("Holds back the guaranteed replicas", func() {
+			claims := []CapacityClaim{
+				{Name: "low", GuaranteedReplicas: 4, Servers: 2, Replicas: 8},
+				{Name: "high", Priority: 10, Servers: 2, Replicas: 8},
+			}
+			Expect(ArbitrateCapacity(maxServers(10), claims)).To(Equal([]int32{4, 6}))
+
+			By("Keeping them even when they are not wanted")
+			claims = []CapacityClaim{
+				{Name: "idle", GuaranteedReplicas: 4},
+				{Name: "high", Priority: 10, Replicas: 10},
+			}
+			Expect(ArbitrateCapacity(maxServers(10), claims)).To(Equal([]int32{0, 6}))
+		})
+
+		It("Limits the requested resources", func() {
+			requests := corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}
+			spec := networkv1alpha1.CapacityPoolSpec{
+				MaxResources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			}
+			claims := []CapacityClaim{{Name: "game", Servers: 1, Replicas: 10, Requests: requests}}
+			Expect(ArbitrateCapacity(spec, claims)).To(Equal([]int32{4}))
+
+			By("Applying the tightest limit")
+			spec.MaxServers = int32Pointer(2)
+			Expect(ArbitrateCapacity(spec, claims)).To(Equal([]int32{2}))
+		})
+
+		It("Never takes existing servers away", func() {
+			claims := []CapacityClaim{
+				{Name: "over", Servers: 12, Replicas: 12},
+				{Name: "waiting", Priority: 10, Replicas: 3},
+			}
+			Expect(ArbitrateCapacity(maxServers(10), claims)).To(Equal([]int32{12, 0}))
+
+			By("Granting scale downs")
+			claims[0].Replicas = 5
+			Expect(ArbitrateCapacity(maxServers(10), claims)).To(Equal([]int32{5, 0}))
+		})
+
+		It("Keeps the capacity of draining servers", func() {
+			claims := []CapacityClaim{{Name: "game", Servers: 6, Draining: 4, Replicas: 4}}
+			Expect(ArbitrateCapacity(maxServers(8), claims)).To(Equal([]int32{4}))
+			Expect(ArbitrateCapacity(maxServers(7), claims)).To(Equal([]int32{3}))
+		})
+	})
+
+	Context("When reading the pool from the cluster", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(networkv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		gametype := func(name string, replicas int32, priority int32) *networkv1alpha1.GameType {
+			gametype := &networkv1alpha1.GameType{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					Labels:    map[string]string{"pool": "shared"},
+				},
+				Spec: networkv1alpha1.GameTypeSpec{Capacity: &networkv1alpha1.GameTypeCapacity{Priority: priority}},
+			}
+			gametype.Spec.FleetSpec.Scaling.Replicas = replicas
+			gametype.Spec.FleetSpec.ServerSpec.Pod.Containers = []corev1.Container{{
+				Name: "game",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+				},
+			}}
+			return gametype
+		}
+
+		It("Grants the replicas of the pools selecting the game", func() {
+			pool := &networkv1alpha1.CapacityPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared-pool"},
+				Spec: networkv1alpha1.CapacityPoolSpec{
+					GameTypeSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pool": "shared"}},
+					MaxServers:       int32Pointer(5),
+				},
+			}
+			game := gametype("game", 3, 0)
+			fleet := &networkv1alpha1.Fleet{
+				ObjectMeta: metav1.ObjectMeta{Name: "game-fleet", Namespace: "default", Labels: map[string]string{"type": "game"}},
+			}
+			fleet.Spec.Scaling.Replicas = 3
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				pool, game, gametype("other", 4, 5), fleet, gameServer("server-1", 0, false),
+			).Build()
+
+			claims, err := CollectCapacityClaims(ctx, c, pool)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(claims).To(HaveLen(2))
+			Expect(claims[0].Name).To(Equal("game"))
+			Expect(claims[0].Servers).To(Equal(int32(1)))
+			servers, requests := UsedCapacity(claims)
+			Expect(servers).To(Equal(int32(1)))
+			Expect(requests.Cpu().String()).To(Equal("250m"))
+
+			By("Giving the free capacity to the game with the higher priority")
+			granted, limitingPool, err := GrantedReplicas(ctx, c, game, 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(granted).To(Equal(int32(1)))
+			Expect(limitingPool).To(Equal("shared-pool"))
+
+			replicas, limitingPool, err := FleetCapacity(ctx, c, fleet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(replicas).To(Equal(int32(1)))
+			Expect(limitingPool).To(Equal("shared-pool"))
+
+			By("Not limiting games outside of the pool")
+			game.Labels = nil
+			granted, limitingPool, err = GrantedReplicas(ctx, c, game, 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(granted).To(Equal(int32(3)))
+			Expect(limitingPool).To(BeEmpty())
+
+			By("Not limiting fleets without a game")
+			fleet.Labels = nil
+			replicas, limitingPool, err = FleetCapacity(ctx, c, fleet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(replicas).To(Equal(int32(3)))
+			Expect(limitingPool).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BufferResponse scales the target to the allocated servers of all its fleets plus the buffer size
+func BufferResponse(ctx context.Context, c client.Reader, spec *networkv1alpha1.BufferAutoscalerSpec, target ScaleTarget) (AutoscaleResponse, error) {
+	if spec == nil {
+		return AutoscaleResponse{}, errors.New("buffer policy requires the buffer field")
+	}
+	_, totals, err := CollectFleetStates(ctx, c, target)
+	if err != nil {
+		return AutoscaleResponse{}, fmt.Errorf("failed to collect the fleets: %w", err)
+	}
+	desired := totals.Allocated + int(spec.Size)
+	return AutoscaleResponse{
+		Scale:           desired != int(target.GetReplicas()),
+		DesiredReplicas: desired,
+	}, nil
+}
+
+// CombineRecommendations picks the recommendation to use with the combinator and marks it as selected.
+// Max and min fail if any of the policies failed, firstsuccessful only if all of them did.
+func CombineRecommendations(combinator networkv1alpha1.CompositeCombinator, recommendations []networkv1alpha1.PolicyRecommendation, current int32) (AutoscaleResponse, error) {
+	selected := -1
+	for i, recommendation := range recommendations {
+		if recommendation.Replicas == nil {
+			if combinator == networkv1alpha1.FirstSuccessfulCompositeCombinator {
+				continue
+			}
+			return AutoscaleResponse{}, fmt.Errorf("policy %s failed: %s", recommendation.Name, recommendation.Error)
+		}
+
+		switch {
+		case selected == -1:
+			selected = i
+		case combinator == networkv1alpha1.FirstSuccessfulCompositeCombinator:
+		case combinator == networkv1alpha1.MinCompositeCombinator:
+			if *recommendation.Replicas < *recommendations[selected].Replicas {
+				selected = i
+			}
+		default:
+			if *recommendation.Replicas > *recommendations[selected].Replicas {
+				selected = i
+			}
+		}
+	}
+	if selected == -1 {
+		return AutoscaleResponse{}, errors.New("all policies failed")
+	}
+
+	recommendations[selected].Selected = true
+	desired := *recommendations[selected].Replicas
+	return AutoscaleResponse{
+		Scale:           desired != current,
+		DesiredReplicas: int(desired),
+	}, nil
+}
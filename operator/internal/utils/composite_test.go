@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Composite Policy Testing", func() {
+	replicas := func(value int32) *int32 {
+		return &value
+	}
+	newRecommendations := func() []networkv1alpha1.PolicyRecommendation {
+		return []networkv1alpha1.PolicyRecommendation{
+			{Name: "buffer", Type: networkv1alpha1.Buffer, Replicas: replicas(5)},
+			{Name: "metrics", Type: networkv1alpha1.Metrics, Replicas: replicas(12)},
+			{Name: "schedule", Type: networkv1alpha1.Schedule, Replicas: replicas(8)},
+		}
+	}
+
+	Context("When combining recommendations", func() {
+		It("Uses the highest recommendation by default", func() {
+			recommendations := newRecommendations()
+			result, err := CombineRecommendations("", recommendations, 5)
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(AutoscaleResponse{Scale: true, DesiredReplicas: 12}))
+			Expect(recommendations[1].Selected).To(BeTrue())
+			Expect(recommendations[0].Selected).To(BeFalse())
+		})
+
+		It("Uses the lowest recommendation with min", func() {
+			recommendations := newRecommendations()
+			result, err := CombineRecommendations(networkv1alpha1.MinCompositeCombinator, recommendations, 5)
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(AutoscaleResponse{Scale: false, DesiredReplicas: 5}))
+			Expect(recommendations[0].Selected).To(BeTrue())
+		})
+
+		It("Fails with max if any policy failed", func() {
+			recommendations := newRecommendations()
+			recommendations[1].Replicas = nil
+			recommendations[1].Error = "metrics are down"
+			_, err := CombineRecommendations(networkv1alpha1.MaxCompositeCombinator, recommendations, 5)
+			Expect(err).To(MatchError(ContainSubstring("metrics are down")))
+		})
+
+		It("Uses the first successful recommendation", func() {
+			recommendations := newRecommendations()
+			recommendations[0].Replicas = nil
+			recommendations[0].Error = "failed"
+			result, err := CombineRecommendations(networkv1alpha1.FirstSuccessfulCompositeCombinator, recommendations, 5)
+			Expect(err).To(BeNil())
+			Expect(result.DesiredReplicas).To(Equal(12))
+			Expect(recommendations[1].Selected).To(BeTrue())
+
+			By("Failing when all policies failed")
+			for i := range recommendations {
+				recommendations[i].Replicas = nil
+				recommendations[i].Selected = false
+			}
+			_, err = CombineRecommendations(networkv1alpha1.FirstSuccessfulCompositeCombinator, recommendations, 5)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When keeping a buffer", func() {
+		It("Adds the buffer to the allocated servers", func() {
+			scheme := runtime.NewScheme()
+			Expect(networkv1alpha1.AddToScheme(scheme)).To(Succeed())
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			fleet := &networkv1alpha1.Fleet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "game-fleet",
+					Namespace: "default",
+					Labels:    map[string]string{"type": "game"},
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				fleet,
+				gameServer("server-1", 10, false), gamePod("server-1", true, true),
+				gameServer("server-2", 0, false), gamePod("server-2", true, true),
+				gameServer("server-3", 0, false), gamePod("server-3", true, false),
+			).Build()
+			gametype := &networkv1alpha1.GameType{
+				ObjectMeta: metav1.ObjectMeta{Name: "game", Namespace: "default"},
+			}
+			gametype.Spec.FleetSpec.Scaling.Replicas = 3
+
+			result, err := BufferResponse(context.Background(), c, &networkv1alpha1.BufferAutoscalerSpec{Size: 5}, gametype)
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(AutoscaleResponse{Scale: true, DesiredReplicas: 7}))
+
+			By("Failing without the buffer field")
+			_, err = BufferResponse(context.Background(), c, nil, gametype)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -9,11 +9,13 @@ const (
 	ReasonServerPodDeleted         EventReason = "ServerPodDeleted"
 	ReasonServerPodCreationFailed  EventReason = "ServerPodCreationFailed"
 	ReasonServerUpdateFAiled       EventReason = "ServerUpdateFailed"
+	ReasonServerCompleted          EventReason = "ServerCompleted"
 
 	ReasonFleetInitialized    EventReason = "FleetInitialized"
 	ReasonFleetUpdateFailed   EventReason = "FleetUpdateFailed"
 	ReasonFleetServersRemoved EventReason = "FleetServersRemoved"
 	ReasonFleetScaleServers   EventReason = "FleetScaleServers"
+	ReasonFleetReplaceServers EventReason = "FleetReplaceServers"
 
 	ReasonGametypeInitialized     EventReason = "GametypeInitialized"
 	ReasonGameTypeDeleting        EventReason = "GameTypeDeleting"
@@ -25,5 +27,17 @@ const (
 	ReasonGameAutoscalerInvalidAutoscalePolicy EventReason = "GameautoscalerInvalidAutoscalePolicy"
 	ReasonGameAutoscalerInvalidSyncType        EventReason = "GameautoscalerInvalidSyncType"
 	ReasonGameautoscalerWebhook                EventReason = "GameautoscalerWebhook"
+	ReasonGameautoscalerMetrics                EventReason = "GameautoscalerMetrics"
+	ReasonGameautoscalerSchedule               EventReason = "GameautoscalerSchedule"
+	ReasonGameautoscalerDegraded               EventReason = "GameautoscalerDegraded"
+	ReasonGameautoscalerBuffer                 EventReason = "GameautoscalerBuffer"
+	ReasonGameautoscalerComposite              EventReason = "GameautoscalerComposite"
+	ReasonGameautoscalerDryRun                 EventReason = "GameautoscalerDryRun"
+	ReasonGameautoscalerPredictive             EventReason = "GameautoscalerPredictive"
+	ReasonGameautoscalerPush                   EventReason = "GameautoscalerPush"
 	ReasonGameautoscalerScale                  EventReason = "GameautoscalerScale"
+	ReasonGameautoscalerCapacity               EventReason = "GameautoscalerCapacity"
+
+	ReasonCapacityPoolLimited EventReason = "CapacityPoolLimited"
+	ReasonCapacityPoolFailed  EventReason = "CapacityPoolFailed"
 )
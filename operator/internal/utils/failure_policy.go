@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"errors"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	defaultInitialBackoff   = 5 * time.Second
+	defaultMaxBackoff       = 5 * time.Minute
+	defaultFailureThreshold = 3
+)
+
+// FailureThreshold returns the failures in a row before the autoscaler is degraded
+func FailureThreshold(policy *networkv1alpha1.FailurePolicy) int32 {
+	if policy.FailureThreshold <= 0 {
+		return defaultFailureThreshold
+	}
+	return policy.FailureThreshold
+}
+
+// FailureBackoff returns the delay before retrying after the given amount of failures in a row.
+// The delay doubles on every failure up to the max, and a random half of it is cut off so autoscalers do not retry in sync.
+func FailureBackoff(policy *networkv1alpha1.FailurePolicy, failures int32) time.Duration {
+	initial := defaultInitialBackoff
+	if policy.InitialBackoff != nil {
+		initial = policy.InitialBackoff.Duration
+	}
+	maxBackoff := defaultMaxBackoff
+	if policy.MaxBackoff != nil {
+		maxBackoff = policy.MaxBackoff.Duration
+	}
+
+	backoff := initial
+	for i := int32(1); i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, maxBackoff)
+	return backoff/2 + time.Duration(rand.Int64N(int64(backoff/2)+1))
+}
+
+// FallbackReplicas returns the replica count to fall back to at the given time.
+// The fallback schedule is used first, then the fallback replicas. Without either, the current count is kept.
+func FallbackReplicas(policy *networkv1alpha1.FailurePolicy, current int32, now time.Time) (int32, error) {
+	if policy.FallbackSchedule != nil {
+		desired, _, active, err := ScheduleReplicas(policy.FallbackSchedule, now)
+		if err != nil {
+			return 0, err
+		}
+		if active {
+			return desired, nil
+		}
+		if policy.FallbackSchedule.DefaultReplicas != nil {
+			return *policy.FallbackSchedule.DefaultReplicas, nil
+		}
+	}
+	if policy.FallbackReplicas != nil {
+		return *policy.FallbackReplicas, nil
+	}
+	if policy.FallbackSchedule == nil {
+		return 0, errors.New("no fallback replicas configured")
+	}
+	return current, nil
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+var _ = Describe("Failure Policy Testing", func() {
+	Context("When backing off", func() {
+		It("Doubles the backoff up to the max", func() {
+			policy := &networkv1alpha1.FailurePolicy{
+				InitialBackoff: &metav1.Duration{Duration: 10 * time.Second},
+				MaxBackoff:     &metav1.Duration{Duration: time.Minute},
+			}
+			Expect(FailureBackoff(policy, 1)).To(BeNumerically("~", 7500*time.Millisecond, 2500*time.Millisecond))
+			Expect(FailureBackoff(policy, 3)).To(BeNumerically("~", 30*time.Second, 10*time.Second))
+			Expect(FailureBackoff(policy, 4)).To(BeNumerically("~", 45*time.Second, 15*time.Second))
+			Expect(FailureBackoff(policy, 100)).To(BeNumerically("~", 45*time.Second, 15*time.Second))
+		})
+
+		It("Uses the defaults", func() {
+			policy := &networkv1alpha1.FailurePolicy{}
+			Expect(FailureThreshold(policy)).To(BeEquivalentTo(3))
+			Expect(FailureBackoff(policy, 1)).To(BeNumerically("~", 3750*time.Millisecond, 1250*time.Millisecond))
+		})
+	})
+
+	Context("When falling back", func() {
+		now := time.Date(2024, 10, 19, 18, 30, 0, 0, time.UTC)
+		fallbackReplicas := int32(4)
+
+		It("Uses the fallback replicas", func() {
+			policy := &networkv1alpha1.FailurePolicy{FallbackReplicas: &fallbackReplicas}
+			replicas, err := FallbackReplicas(policy, 10, now)
+			Expect(err).To(BeNil())
+			Expect(replicas).To(BeEquivalentTo(4))
+		})
+
+		It("Prefers the active schedule window", func() {
+			policy := &networkv1alpha1.FailurePolicy{
+				FallbackReplicas: &fallbackReplicas,
+				FallbackSchedule: &networkv1alpha1.ScheduleAutoscalerSpec{
+					Windows: []networkv1alpha1.ScheduleWindow{
+						{
+							Name:        "evening",
+							Start:       "0 18 * * *",
+							Duration:    metav1.Duration{Duration: 4 * time.Hour},
+							MinReplicas: 20,
+						},
+					},
+				},
+			}
+			replicas, err := FallbackReplicas(policy, 10, now)
+			Expect(err).To(BeNil())
+			Expect(replicas).To(BeEquivalentTo(20))
+
+			By("Using the fallback replicas outside the window")
+			replicas, err = FallbackReplicas(policy, 10, now.Add(-12*time.Hour))
+			Expect(err).To(BeNil())
+			Expect(replicas).To(BeEquivalentTo(4))
+
+			By("Keeping the current replicas without fallback replicas")
+			policy.FallbackReplicas = nil
+			replicas, err = FallbackReplicas(policy, 10, now.Add(-12*time.Hour))
+			Expect(err).To(BeNil())
+			Expect(replicas).To(BeEquivalentTo(10))
+		})
+
+		It("Fails without anything to fall back to", func() {
+			_, err := FallbackReplicas(&networkv1alpha1.FailurePolicy{}, 10, now)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
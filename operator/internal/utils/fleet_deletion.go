@@ -130,8 +130,12 @@ func getNewestServer(ctx context.Context, servers *networkv1alpha1.ServerList, d
 	return newestServer, nil
 }
 
-// isDeleteAllowed is a utility for a server object, to communicate with the sidecar to see if deletion is allowed
+// isDeleteAllowed is a utility for a server object, to communicate with the sidecar to see if deletion is allowed.
+// The state pushed by the sidecar is used when there is one.
 func (ProdDeletionChecker) isDeleteAllowed(ctx context.Context, server *networkv1alpha1.Server, c *client.Client) (bool, error) {
+	if server.Status.Sidecar != nil {
+		return server.Status.Sidecar.DeleteAllowed, nil
+	}
 	podName := server.Name + "-pod"
 	pod := &v1.Pod{}
 	err := (*c).Get(ctx, types.NamespacedName{Namespace: server.Namespace, Name: podName}, pod)
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"context"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AllocatedLabel is set to "true" on the pod of a server that is in use, for example through the service API.
+// Game servers with pushState can instead report it through the sidecar, see IsServerAllocated.
+const AllocatedLabel = "allocated"
+
+// FleetState is the summary of the servers of a single fleet sent to the webhook
+type FleetState struct {
+	Name            string `json:"name"`
+	DesiredReplicas int32  `json:"desired_replicas"`
+	ServerCounts
+}
+
+// ServerCounts counts the servers by their state, along with the totals reported by them.
+// Players and counters are only reported by servers with pushState, so Players is nil when none of the servers pushed its state.
+type ServerCounts struct {
+	Servers      int              `json:"servers"`
+	Ready        int              `json:"ready"`
+	Allocated    int              `json:"allocated"`
+	ShuttingDown int              `json:"shutting_down"`
+	Players      *int64           `json:"players,omitempty"`
+	Counters     map[string]int64 `json:"counters,omitempty"`
+}
+
+// Add adds the counts of other to the counts
+func (c *ServerCounts) Add(other ServerCounts) {
+	c.Servers += other.Servers
+	c.Ready += other.Ready
+	c.Allocated += other.Allocated
+	c.ShuttingDown += other.ShuttingDown
+	if other.Players != nil {
+		c.addPlayers(*other.Players)
+	}
+	for name, value := range other.Counters {
+		if c.Counters == nil {
+			c.Counters = make(map[string]int64)
+		}
+		c.Counters[name] += value
+	}
+}
+
+func (c *ServerCounts) addPlayers(players int64) {
+	if c.Players == nil {
+		c.Players = new(int64)
+	}
+	*c.Players += players
+}
+
+// ScaleTarget is the resource scaled by an autoscaler, either a GameType or a Fleet
+type ScaleTarget interface {
+	client.Object
+	GetReplicas() int32
+	SetReplicas(replicas int32)
+}
+
+// CollectFleetStates summarizes every fleet of the target, along with the totals over all of them
+func CollectFleetStates(ctx context.Context, c client.Reader, target ScaleTarget) ([]FleetState, ServerCounts, error) {
+	fleets, err := targetFleets(ctx, c, target)
+	if err != nil {
+		return nil, ServerCounts{}, err
+	}
+
+	states := make([]FleetState, 0, len(fleets))
+	var totals ServerCounts
+	for _, fleet := range fleets {
+		servers := &networkv1alpha1.ServerList{}
+		if err := c.List(ctx, servers, client.InNamespace(fleet.Namespace), client.MatchingLabels{"fleet": fleet.Name}); err != nil {
+			return nil, ServerCounts{}, err
+		}
+		pods := &corev1.PodList{}
+		if err := c.List(ctx, pods, client.InNamespace(fleet.Namespace), client.MatchingLabels{"fleet": fleet.Name}); err != nil {
+			return nil, ServerCounts{}, err
+		}
+
+		state := SummarizeFleet(fleet, servers.Items, pods.Items)
+		totals.Add(state.ServerCounts)
+		states = append(states, state)
+	}
+	return states, totals, nil
+}
+
+// targetFleets returns the fleet itself for fleet targets, and the fleets of the gametype otherwise
+func targetFleets(ctx context.Context, c client.Reader, target ScaleTarget) ([]networkv1alpha1.Fleet, error) {
+	if fleet, ok := target.(*networkv1alpha1.Fleet); ok {
+		return []networkv1alpha1.Fleet{*fleet}, nil
+	}
+	fleets := &networkv1alpha1.FleetList{}
+	if err := c.List(ctx, fleets, client.InNamespace(target.GetNamespace()), client.MatchingLabels{"type": target.GetName()}); err != nil {
+		return nil, err
+	}
+	return fleets.Items, nil
+}
+
+// SummarizeFleet counts the servers of the fleet by the state of their pods.
+// A server is shutting down if either it or its pod is being deleted, or if it is a completed OneShot server.
+// Players and counters are only summed from the servers whose sidecar pushed its state, as no one else writes them.
+func SummarizeFleet(fleet networkv1alpha1.Fleet, servers []networkv1alpha1.Server, pods []corev1.Pod) FleetState {
+	podsByServer := make(map[string]corev1.Pod, len(pods))
+	for _, pod := range pods {
+		podsByServer[pod.Labels["server"]] = pod
+	}
+
+	state := FleetState{
+		Name:            fleet.Name,
+		DesiredReplicas: fleet.Spec.Scaling.Replicas,
+	}
+	for _, server := range servers {
+		state.Servers++
+		if server.Status.Sidecar != nil {
+			state.addPlayers(server.Status.Players)
+			for name, value := range server.Status.Counters {
+				if state.Counters == nil {
+					state.Counters = make(map[string]int64)
+				}
+				state.Counters[name] += value
+			}
+		}
+
+		pod, hasPod := podsByServer[server.Name]
+		if server.DeletionTimestamp != nil || (hasPod && pod.DeletionTimestamp != nil) || IsServerCompleted(&server) {
+			state.ShuttingDown++
+			continue
+		}
+		if !hasPod {
+			continue
+		}
+		if IsServerAllocated(&server, pod) {
+			state.Allocated++
+		}
+		if isPodReady(pod) {
+			state.Ready++
+		}
+	}
+	return state
+}
+
+// IsServerAllocated returns whether the server is in use, either as reported by its sidecar or through the AllocatedLabel on its pod
+func IsServerAllocated(server *networkv1alpha1.Server, pod corev1.Pod) bool {
+	if server.Status.Sidecar != nil && server.Status.Sidecar.Allocated {
+		return true
+	}
+	return pod.Labels[AllocatedLabel] == "true"
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoMetricData is returned when the query succeeded, but there was nothing to scale on
+var ErrNoMetricData = errors.New("metrics query returned no data")
+
+type Metrics interface {
+	QueryDesiredReplicas(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler, target ScaleTarget) (AutoscaleResponse, error)
+}
+
+type ProductionMetricsQuery struct{}
+
+// QueryDesiredReplicas runs the query of the metrics policy and turns the result into a replica count
+func (m ProductionMetricsQuery) QueryDesiredReplicas(ctx context.Context, autoscaler *networkv1alpha1.GameAutoscaler,
+	target ScaleTarget) (AutoscaleResponse, error) {
+	spec := autoscaler.Spec.AutoscalePolicy.MetricsAutoscalerSpec
+	if spec == nil {
+		return AutoscaleResponse{}, errors.New("missing metrics spec")
+	}
+
+	value, err := QueryMetric(ctx, spec.Url, spec.Query)
+	if err != nil {
+		return AutoscaleResponse{}, err
+	}
+
+	desired, err := CalculateMetricsReplicas(value, spec.TargetValue.AsApproximateFloat64())
+	if err != nil {
+		return AutoscaleResponse{}, err
+	}
+
+	return AutoscaleResponse{
+		Scale:           desired != int(target.GetReplicas()),
+		DesiredReplicas: desired,
+	}, nil
+}
+
+// CalculateMetricsReplicas divides the queried value by the per server target and rounds it up.
+// Values that cannot be scaled on, such as NaN, infinities or values of zero and below, are returned as errors,
+// so the failure policy applies instead of a gap in the metrics scaling the fleet to zero.
+func CalculateMetricsReplicas(value float64, target float64) (int, error) {
+	if target <= 0 || math.IsNaN(target) || math.IsInf(target, 0) {
+		return 0, fmt.Errorf("invalid target value: %v", target)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("metrics query returned a non-finite value: %v", value)
+	}
+	if value <= 0 {
+		return 0, fmt.Errorf("metrics query returned %v, expected a value above zero", value)
+	}
+
+	desired := math.Ceil(value / target)
+	if desired > math.MaxInt32 {
+		return 0, fmt.Errorf("desired replica count %v is too large", desired)
+	}
+	return int(desired), nil
+}
+
+// QueryMetric sends an instant query to a Prometheus compatible API and returns the single resulting value
+func QueryMetric(ctx context.Context, address string, query string) (float64, error) {
+	httpClient := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	queryUrl := strings.TrimSuffix(address, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, queryUrl, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	var response metricsQueryResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w\nRaw response: %s\n", err, string(bodyBytes))
+	}
+	if resp.StatusCode != http.StatusOK || response.Status != "success" {
+		return 0, fmt.Errorf("invalid query response: %d. Error: %s", resp.StatusCode, response.Error)
+	}
+
+	switch response.Data.ResultType {
+	case "scalar":
+		var sample metricsSampleValue
+		if err := json.Unmarshal(response.Data.Result, &sample); err != nil {
+			return 0, fmt.Errorf("failed to decode scalar: %w", err)
+		}
+		return sample.float()
+	case "vector":
+		var samples []struct {
+			Value metricsSampleValue `json:"value"`
+		}
+		if err := json.Unmarshal(response.Data.Result, &samples); err != nil {
+			return 0, fmt.Errorf("failed to decode vector: %w", err)
+		}
+		if len(samples) == 0 {
+			return 0, ErrNoMetricData
+		}
+		if len(samples) > 1 {
+			return 0, fmt.Errorf("metrics query returned %d series, expected 1", len(samples))
+		}
+		return samples[0].Value.float()
+	default:
+		return 0, fmt.Errorf("unsupported result type %s", response.Data.ResultType)
+	}
+}
+
+type metricsQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// metricsSampleValue is the [timestamp, "value"] pair used by the query API
+type metricsSampleValue [2]json.RawMessage
+
+func (v metricsSampleValue) float() (float64, error) {
+	var raw string
+	if err := json.Unmarshal(v[1], &raw); err != nil {
+		return 0, fmt.Errorf("failed to decode sample value: %w", err)
+	}
+	return strconv.ParseFloat(raw, 64)
+}
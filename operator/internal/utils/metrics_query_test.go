@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"math"
+	"net/http"
+	"net/http/httptest"
+)
+
+// newFakeQueryServer starts a server that answers every instant query with the given body
+func newFakeQueryServer(status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" || r.URL.Query().Get("query") == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = fmt.Fprint(w, body)
+	}))
+}
+
+func vectorBody(values ...string) string {
+	result := ""
+	for i, value := range values {
+		if i > 0 {
+			result += ","
+		}
+		result += fmt.Sprintf(`{"metric":{"game":"test"},"value":[1700000000.123,"%s"]}`, value)
+	}
+	return fmt.Sprintf(`{"status":"success","data":{"resultType":"vector","result":[%s]}}`, result)
+}
+
+var _ = Describe("Metrics Query Testing", func() {
+	ctx := context.Background()
+
+	Context("When querying the metrics API", func() {
+		It("Reads a single vector value", func() {
+			server := newFakeQueryServer(http.StatusOK, vectorBody("42.5"))
+			defer server.Close()
+
+			value, err := QueryMetric(ctx, server.URL+"/", "sum(players)")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(42.5))
+		})
+
+		It("Reads a scalar value", func() {
+			server := newFakeQueryServer(http.StatusOK, `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"7"]}}`)
+			defer server.Close()
+
+			value, err := QueryMetric(ctx, server.URL, "scalar(sum(players))")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(7.0))
+		})
+
+		It("Fails on missing data", func() {
+			server := newFakeQueryServer(http.StatusOK, vectorBody())
+			defer server.Close()
+
+			_, err := QueryMetric(ctx, server.URL, "sum(players)")
+			Expect(err).To(MatchError(ErrNoMetricData))
+		})
+
+		It("Fails on multiple series", func() {
+			server := newFakeQueryServer(http.StatusOK, vectorBody("1", "2"))
+			defer server.Close()
+
+			_, err := QueryMetric(ctx, server.URL, "players")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Fails on API errors", func() {
+			server := newFakeQueryServer(http.StatusBadRequest, `{"status":"error","errorType":"bad_data","error":"parse error"}`)
+			defer server.Close()
+
+			_, err := QueryMetric(ctx, server.URL, "sum(")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("parse error"))
+		})
+	})
+
+	Context("When calculating the replica count", func() {
+		It("Rounds up", func() {
+			replicas, err := CalculateMetricsReplicas(41, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(replicas).To(Equal(5))
+		})
+
+		It("Rejects values that cannot be scaled on", func() {
+			By("Rejecting NaN, for example from an empty ratio")
+			_, err := CalculateMetricsReplicas(math.NaN(), 10)
+			Expect(err).To(HaveOccurred())
+			By("Rejecting infinities")
+			_, err = CalculateMetricsReplicas(math.Inf(1), 10)
+			Expect(err).To(HaveOccurred())
+			_, err = CalculateMetricsReplicas(math.Inf(-1), 10)
+			Expect(err).To(HaveOccurred())
+			By("Rejecting zero, so a gap in the metrics does not scale to zero")
+			_, err = CalculateMetricsReplicas(0, 10)
+			Expect(err).To(HaveOccurred())
+			By("Rejecting negative values")
+			_, err = CalculateMetricsReplicas(-3, 10)
+			Expect(err).To(HaveOccurred())
+			By("Rejecting an invalid target")
+			_, err = CalculateMetricsReplicas(10, 0)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When using the production query", func() {
+		It("Returns the scaling response", func() {
+			body := vectorBody("NaN")
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprint(w, body)
+			}))
+			defer server.Close()
+
+			autoscaler := &networkv1alpha1.GameAutoscaler{
+				Spec: networkv1alpha1.GameAutoscalerSpec{
+					AutoscalePolicy: networkv1alpha1.AutoscalePolicy{
+						Type: networkv1alpha1.Metrics,
+						MetricsAutoscalerSpec: &networkv1alpha1.MetricsAutoscalerSpec{
+							Url:         server.URL,
+							Query:       "sum(players)",
+							TargetValue: resource.MustParse("10"),
+						},
+					},
+				},
+			}
+			gametype := &networkv1alpha1.GameType{}
+			gametype.Spec.FleetSpec.Scaling.Replicas = 3
+
+			By("Not scaling when the value is NaN")
+			_, err := ProductionMetricsQuery{}.QueryDesiredReplicas(ctx, autoscaler, gametype)
+			Expect(err).To(HaveOccurred())
+
+			By("Scaling when the value changes")
+			body = vectorBody("55")
+			response, err := ProductionMetricsQuery{}.QueryDesiredReplicas(ctx, autoscaler, gametype)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Scale).To(BeTrue())
+			Expect(response.DesiredReplicas).To(Equal(6))
+
+			By("Not scaling when the count is already correct")
+			gametype.Spec.FleetSpec.Scaling.Replicas = 6
+			response, err = ProductionMetricsQuery{}.QueryDesiredReplicas(ctx, autoscaler, gametype)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Scale).To(BeFalse())
+		})
+	})
+})
@@ -8,6 +8,20 @@ import (
 	"os"
 )
 
+const (
+	// SidecarContainerName is the name of the sidecar container added to every server pod
+	SidecarContainerName = "loputoo-sidecar"
+	// sidecarStateVolume is the emptyDir the sidecar saves its state to, so the state survives restarts of the sidecar container
+	sidecarStateVolume = "sidecar-state"
+	sidecarStatePath   = "/var/lib/sidecar"
+	// gameSharedVolume is the emptyDir the sidecar and the game exchange the files of the shutdown delivery in
+	gameSharedVolume = "game-shared"
+	gameSharedPath   = "/var/run/game"
+	// sidecarTokenVolumeName holds the token the sidecar pushes its state with
+	sidecarTokenVolumeName = "sidecar-token"
+	sidecarTokenPath       = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
 func addContainer(spec *corev1.PodSpec, container corev1.Container) *corev1.PodSpec {
 	spec.Containers = append(spec.Containers, container)
 	return spec
@@ -23,16 +37,95 @@ func getPodSpec(server *networkv1alpha1.Server) (*corev1.PodSpec, bool) {
 		defaultImage = true
 	}
 	pod := addContainer(&spec.Pod, corev1.Container{
-		Name:  "loputoo-sidecar",
+		Name:  SidecarContainerName,
 		Image: sidecarImage,
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          "http",
 				ContainerPort: 8080,
 			},
+			{
+				Name:          "grpc",
+				ContainerPort: 8081,
+			},
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "SIDECAR_STATE_FILE",
+				Value: sidecarStatePath + "/state.json",
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      sidecarStateVolume,
+				MountPath: sidecarStatePath,
+			},
 		},
 		ImagePullPolicy: corev1.PullIfNotPresent,
 	})
+	if spec.PushState {
+		sidecar := &pod.Containers[len(pod.Containers)-1]
+		sidecar.Env = append(sidecar.Env, corev1.EnvVar{
+			Name:  "SIDECAR_PUSH_STATE",
+			Value: "true",
+		}, corev1.EnvVar{
+			Name: "POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.namespace",
+				},
+			},
+		})
+		if UsesSidecarAccount(server) {
+			automount := false
+			pod.ServiceAccountName = SidecarAccountName(server)
+			pod.AutomountServiceAccountToken = &automount
+			pod.Volumes = append(pod.Volumes, sidecarTokenVolume())
+			sidecar.VolumeMounts = append(sidecar.VolumeMounts, corev1.VolumeMount{
+				Name:      sidecarTokenVolumeName,
+				MountPath: sidecarTokenPath,
+				ReadOnly:  true,
+			})
+		}
+	}
+	if spec.Lifecycle == networkv1alpha1.LifecycleOneShot && pod.RestartPolicy != corev1.RestartPolicyOnFailure {
+		// A OneShot game exits after its match, which must not restart it. OnFailure is kept, so crashed matches are retried.
+		pod.RestartPolicy = corev1.RestartPolicyNever
+	}
+	pod.Volumes = append(pod.Volumes, corev1.Volume{
+		Name: sidecarStateVolume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	if delivery := spec.ShutdownDelivery; delivery != nil {
+		sidecar := &pod.Containers[len(pod.Containers)-1]
+		sidecar.Env = append(sidecar.Env, shutdownDeliveryEnv(delivery)...)
+		if delivery.SharesProcessNamespace() {
+			// The sidecar has to see the game process to signal it, or to notice it exiting
+			share := true
+			pod.ShareProcessNamespace = &share
+		}
+		if delivery.UsesSharedDir() {
+			pod.Volumes = append(pod.Volumes, corev1.Volume{
+				Name: gameSharedVolume,
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			})
+			for i := range pod.Containers {
+				container := &pod.Containers[i]
+				container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+					Name:      gameSharedVolume,
+					MountPath: gameSharedPath,
+				})
+				container.Env = append(container.Env, corev1.EnvVar{
+					Name:  "GAME_SHARED_DIR",
+					Value: gameSharedPath,
+				})
+			}
+		}
+	}
 	for i := range pod.Containers {
 		container := &pod.Containers[i]
 		container.Env = append(container.Env, corev1.EnvVar{
@@ -75,6 +168,16 @@ func getPodSpec(server *networkv1alpha1.Server) (*corev1.PodSpec, bool) {
 		})
 	}
 
+	if spec.Lifecycle == networkv1alpha1.LifecycleOneShot {
+		// The restart policy of the pod would also keep a crashed sidecar down, so the sidecar runs as a native sidecar,
+		// an init container that is always restarted and is stopped once the game containers have exited.
+		always := corev1.ContainerRestartPolicyAlways
+		sidecar := pod.Containers[len(pod.Containers)-1]
+		sidecar.RestartPolicy = &always
+		pod.Containers = pod.Containers[:len(pod.Containers)-1]
+		pod.InitContainers = append(pod.InitContainers, sidecar)
+	}
+
 	pod.ImagePullSecrets = append(pod.ImagePullSecrets, corev1.LocalObjectReference{
 		Name: os.Getenv("IMAGE_PULL_SECRET_NAME"),
 	})
@@ -82,6 +185,28 @@ func getPodSpec(server *networkv1alpha1.Server) (*corev1.PodSpec, bool) {
 	return pod, defaultImage
 }
 
+// shutdownDeliveryEnv configures the sidecar to deliver the shutdown request to the game as set in the delivery
+func shutdownDeliveryEnv(delivery *networkv1alpha1.ShutdownDelivery) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	add := func(name string, value string) {
+		if value != "" {
+			env = append(env, corev1.EnvVar{Name: name, Value: value})
+		}
+	}
+	add("SIDECAR_SHUTDOWN_SIGNAL", delivery.Signal)
+	add("SIDECAR_GAME_PROCESS", delivery.Process)
+	if delivery.File != "" {
+		add("SIDECAR_SHUTDOWN_FILE", gameSharedPath+"/"+delivery.File)
+	}
+	if delivery.AllowDeleteFile != "" {
+		add("SIDECAR_ALLOW_DELETE_FILE", gameSharedPath+"/"+delivery.AllowDeleteFile)
+	}
+	if delivery.AllowDeleteOnExit {
+		add("SIDECAR_ALLOW_DELETE_ON_EXIT", "true")
+	}
+	return env
+}
+
 func GetNewPod(server *networkv1alpha1.Server, namespace string) (*corev1.Pod, bool) {
 	labels := server.GetLabels()
 	if labels == nil {
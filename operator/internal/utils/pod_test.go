@@ -0,0 +1,129 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Pod Testing", func() {
+	Context("When creating the pod of a server", func() {
+		It("Mounts the state volume in the sidecar", func() {
+			server := &networkv1alpha1.Server{
+				ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "default"},
+			}
+			server.Spec.Pod.Containers = []corev1.Container{{Name: "game", Image: "game:latest"}}
+
+			pod, _ := GetNewPod(server, "default")
+			Expect(pod.Spec.Containers).To(HaveLen(2))
+			Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+				Name:         sidecarStateVolume,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}))
+
+			sidecar := pod.Spec.Containers[1]
+			Expect(sidecar.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: sidecarStateVolume, MountPath: sidecarStatePath}))
+			Expect(sidecar.Env).To(ContainElement(corev1.EnvVar{Name: "SIDECAR_STATE_FILE", Value: "/var/lib/sidecar/state.json"}))
+
+			By("Keeping the state out of the game container")
+			Expect(pod.Spec.Containers[0].VolumeMounts).To(BeEmpty())
+		})
+
+		It("Shares the process namespace and a directory for the shutdown delivery", func() {
+			server := &networkv1alpha1.Server{
+				ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "default"},
+			}
+			server.Spec.Pod.Containers = []corev1.Container{{Name: "game", Image: "game:latest"}}
+			server.Spec.ShutdownDelivery = &networkv1alpha1.ShutdownDelivery{
+				Signal:          "SIGUSR1",
+				Process:         "game",
+				File:            "shutdown",
+				AllowDeleteFile: "done",
+			}
+
+			pod, _ := GetNewPod(server, "default")
+			Expect(pod.Spec.ShareProcessNamespace).To(HaveValue(BeTrue()))
+			Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+				Name:         gameSharedVolume,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}))
+
+			sidecar := pod.Spec.Containers[1]
+			Expect(sidecar.Env).To(ContainElements(
+				corev1.EnvVar{Name: "SIDECAR_SHUTDOWN_SIGNAL", Value: "SIGUSR1"},
+				corev1.EnvVar{Name: "SIDECAR_GAME_PROCESS", Value: "game"},
+				corev1.EnvVar{Name: "SIDECAR_SHUTDOWN_FILE", Value: "/var/run/game/shutdown"},
+				corev1.EnvVar{Name: "SIDECAR_ALLOW_DELETE_FILE", Value: "/var/run/game/done"},
+			))
+			for _, container := range pod.Spec.Containers {
+				Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: gameSharedVolume, MountPath: gameSharedPath}))
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "GAME_SHARED_DIR", Value: gameSharedPath}))
+			}
+		})
+
+		It("Runs the sidecar as a native sidecar for OneShot servers", func() {
+			server := &networkv1alpha1.Server{
+				ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "default"},
+			}
+			server.Spec.Pod.Containers = []corev1.Container{{Name: "game", Image: "game:latest"}}
+			server.Spec.Pod.InitContainers = []corev1.Container{{Name: "setup", Image: "setup:latest"}}
+			server.Spec.Lifecycle = networkv1alpha1.LifecycleOneShot
+
+			pod, _ := GetNewPod(server, "default")
+			Expect(pod.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+			Expect(pod.Spec.Containers).To(ConsistOf(HaveField("Name", "game")))
+			Expect(pod.Spec.InitContainers).To(HaveLen(2))
+			Expect(pod.Spec.InitContainers[0].Name).To(Equal("setup"))
+			Expect(pod.Spec.InitContainers[0].RestartPolicy).To(BeNil())
+			sidecar := pod.Spec.InitContainers[1]
+			Expect(sidecar.Name).To(Equal(SidecarContainerName))
+			Expect(sidecar.RestartPolicy).To(HaveValue(Equal(corev1.ContainerRestartPolicyAlways)))
+			Expect(sidecar.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: sidecarStateVolume, MountPath: sidecarStatePath}))
+			Expect(sidecar.Env).To(ContainElement(corev1.EnvVar{Name: "SERVER_NAME", Value: "server"}))
+
+			By("Keeping the sidecar a regular container for Persistent servers")
+			server.Spec.Lifecycle = networkv1alpha1.LifecyclePersistent
+			pod, _ = GetNewPod(server, "default")
+			Expect(pod.Spec.Containers).To(HaveLen(2))
+			Expect(pod.Spec.InitContainers).To(ConsistOf(HaveField("Name", "setup")))
+		})
+
+		It("Keeps the process namespace of the pod without a shutdown delivery", func() {
+			server := &networkv1alpha1.Server{
+				ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "default"},
+			}
+			server.Spec.Pod.Containers = []corev1.Container{{Name: "game", Image: "game:latest"}}
+
+			pod, _ := GetNewPod(server, "default")
+			Expect(pod.Spec.ShareProcessNamespace).To(BeNil())
+			Expect(pod.Spec.Volumes).To(HaveLen(1))
+		})
+	})
+
+	Context("When checking if the game exited", func() {
+		It("Ignores the sidecar and waits for every game container", func() {
+			pod := &corev1.Pod{Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "game", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 3}}},
+					{Name: "helper", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+					{Name: SidecarContainerName, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				},
+			}}
+			exited, _ := GameExited(pod)
+			Expect(exited).To(BeFalse())
+
+			pod.Status.ContainerStatuses[1].State = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+			exited, message := GameExited(pod)
+			Expect(exited).To(BeTrue())
+			Expect(message).To(Equal("container game exited with code 3, container helper exited with code 0"))
+		})
+
+		It("Does not count a pod without container statuses", func() {
+			exited, _ := GameExited(&corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending}})
+			Expect(exited).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"math"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"slices"
+	"time"
+)
+
+const (
+	defaultSampleInterval      = 5 * time.Minute
+	defaultHistorySize         = 4032
+	defaultLeadTime            = 10 * time.Minute
+	defaultSafetyMarginPercent = 10
+
+	// Smoothing factors of the level, trend and seasonal components of the forecast
+	levelSmoothing    = 0.5
+	trendSmoothing    = 0.1
+	seasonalSmoothing = 0.3
+)
+
+var defaultSeasons = []time.Duration{24 * time.Hour, 7 * 24 * time.Hour}
+
+// PredictiveResponse records the allocated servers of the target to the history and scales the target to
+// the highest forecast within the lead time, plus the safety margin. It never scales below the allocated servers.
+func PredictiveResponse(ctx context.Context, c client.Reader, spec *networkv1alpha1.PredictiveAutoscalerSpec,
+	status *networkv1alpha1.GameAutoscalerStatus, target ScaleTarget, now time.Time) (AutoscaleResponse, error) {
+	if spec == nil {
+		return AutoscaleResponse{}, errors.New("predictive policy requires the predictive field")
+	}
+	_, totals, err := CollectFleetStates(ctx, c, target)
+	if err != nil {
+		return AutoscaleResponse{}, fmt.Errorf("failed to collect the fleets: %w", err)
+	}
+	RecordDemand(spec, status, int32(totals.Allocated), now)
+
+	interval := sampleInterval(spec)
+	periods := make([]int, 0, len(defaultSeasons))
+	for _, season := range seasons(spec) {
+		periods = append(periods, int(season/interval))
+	}
+	leadTime := defaultLeadTime
+	if spec.LeadTime != nil {
+		leadTime = spec.LeadTime.Duration
+	}
+	steps := max(int(math.Ceil(float64(leadTime)/float64(interval))), 1)
+	forecast := slices.Max(ForecastDemand(status.DemandHistory.Samples, periods, steps))
+	peak := int32(math.Ceil(forecast))
+	status.DemandHistory.Forecast = &peak
+
+	margin := int32(defaultSafetyMarginPercent)
+	if spec.SafetyMarginPercent > 0 {
+		margin = spec.SafetyMarginPercent
+	}
+	desired := max(int(math.Ceil(forecast*float64(100+margin)/100)), totals.Allocated)
+	return AutoscaleResponse{
+		Scale:           desired != int(target.GetReplicas()),
+		DesiredReplicas: desired,
+	}, nil
+}
+
+// RecordDemand adds the allocated servers to the history of the status.
+// Each sample keeps the highest count within its interval. Intervals without an evaluation are filled with
+// the new count, and once the history is full the oldest samples are dropped.
+func RecordDemand(spec *networkv1alpha1.PredictiveAutoscalerSpec, status *networkv1alpha1.GameAutoscalerStatus,
+	allocated int32, now time.Time) {
+	interval := sampleInterval(spec)
+	history := status.DemandHistory
+	if history == nil || history.Interval.Duration != interval || len(history.Samples) == 0 || now.Before(history.LastSampleTime.Time) {
+		status.DemandHistory = &networkv1alpha1.DemandHistory{
+			Interval:       metav1.Duration{Duration: interval},
+			LastSampleTime: metav1.Time{Time: now},
+			Samples:        []int32{allocated},
+		}
+		return
+	}
+
+	size := int(defaultHistorySize)
+	if spec.HistorySize > 0 {
+		size = int(spec.HistorySize)
+	}
+	elapsed := int(now.Sub(history.LastSampleTime.Time) / interval)
+	if elapsed == 0 {
+		last := len(history.Samples) - 1
+		history.Samples[last] = max(history.Samples[last], allocated)
+	}
+	for i := 0; i < min(elapsed, size); i++ {
+		history.Samples = append(history.Samples, allocated)
+	}
+	history.LastSampleTime = metav1.Time{Time: history.LastSampleTime.Add(time.Duration(elapsed) * interval)}
+	if len(history.Samples) > size {
+		history.Samples = slices.Clone(history.Samples[len(history.Samples)-size:])
+	}
+}
+
+// ForecastDemand forecasts the next steps of the samples with additive Holt-Winters smoothing.
+// Every period, in samples, adds a seasonal component once the samples cover it, so daily and weekly patterns
+// are both followed. The shortest period is initialized from its first cycle, the others are learned as they go.
+func ForecastDemand(samples []int32, periods []int, steps int) []float64 {
+	forecast := make([]float64, steps)
+	if len(samples) == 0 {
+		return forecast
+	}
+
+	usable := make([]int, 0, len(periods))
+	for _, period := range periods {
+		if period >= 2 && period <= len(samples) && !slices.Contains(usable, period) {
+			usable = append(usable, period)
+		}
+	}
+	slices.Sort(usable)
+	start := 1
+	if len(usable) > 0 {
+		start = usable[0]
+	}
+
+	var level, trend float64
+	for _, sample := range samples[:start] {
+		level += float64(sample)
+	}
+	level /= float64(start)
+	seasons := make([][]float64, len(usable))
+	for i := range usable {
+		seasons[i] = make([]float64, len(samples))
+	}
+	if len(usable) > 0 {
+		for t := 0; t < start; t++ {
+			seasons[0][t] = float64(samples[t]) - level
+		}
+	}
+	//The seasonal component of the same phase one period earlier
+	previousSeason := func(i int, t int) float64 {
+		if t < usable[i] {
+			return 0
+		}
+		return seasons[i][t-usable[i]]
+	}
+
+	for t := start; t < len(samples); t++ {
+		sample := float64(samples[t])
+		var seasonal float64
+		for i := range usable {
+			seasonal += previousSeason(i, t)
+		}
+		previousLevel := level
+		level = levelSmoothing*(sample-seasonal) + (1-levelSmoothing)*(level+trend)
+		trend = trendSmoothing*(level-previousLevel) + (1-trendSmoothing)*trend
+		for i := range usable {
+			others := seasonal - previousSeason(i, t)
+			seasons[i][t] = seasonalSmoothing*(sample-level-others) + (1-seasonalSmoothing)*previousSeason(i, t)
+		}
+	}
+
+	last := len(samples) - 1
+	for step := 1; step <= steps; step++ {
+		value := level + float64(step)*trend
+		for i, period := range usable {
+			//The newest seasonal component of the same phase
+			value += seasons[i][last+step-period*((step-1)/period+1)]
+		}
+		forecast[step-1] = max(value, 0)
+	}
+	return forecast
+}
+
+func sampleInterval(spec *networkv1alpha1.PredictiveAutoscalerSpec) time.Duration {
+	if spec.SampleInterval == nil || spec.SampleInterval.Duration <= 0 {
+		return defaultSampleInterval
+	}
+	return spec.SampleInterval.Duration
+}
+
+func seasons(spec *networkv1alpha1.PredictiveAutoscalerSpec) []time.Duration {
+	if len(spec.Seasons) == 0 {
+		return defaultSeasons
+	}
+	durations := make([]time.Duration, 0, len(spec.Seasons))
+	for _, season := range spec.Seasons {
+		durations = append(durations, season.Duration)
+	}
+	return durations
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"math"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"time"
+)
+
+var _ = Describe("Predictive Policy Testing", func() {
+	// demand peaks at 30 allocated servers in the evening and drops to 10 at night, one sample per hour
+	dailyDemand := func(hour int) int32 {
+		return int32(math.Round(20 + 10*math.Sin(2*math.Pi*float64(hour%24-12)/24)))
+	}
+
+	Context("When forecasting the demand", func() {
+		It("Follows a flat demand", func() {
+			samples := []int32{8, 8, 8, 8, 8, 8}
+			forecast := ForecastDemand(samples, []int{24}, 3)
+			Expect(forecast).To(HaveLen(3))
+			for _, value := range forecast {
+				Expect(value).To(BeNumerically("~", 8, 0.01))
+			}
+		})
+
+		It("Follows the daily pattern", func() {
+			samples := make([]int32, 0, 24*4)
+			for hour := 0; hour < 24*4; hour++ {
+				samples = append(samples, dailyDemand(hour))
+			}
+			forecast := ForecastDemand(samples, []int{24, 24 * 7}, 6)
+			for step, value := range forecast {
+				Expect(value).To(BeNumerically("~", dailyDemand(24*4+step), 2))
+			}
+		})
+
+		It("Uses the last sample without a covered period", func() {
+			forecast := ForecastDemand([]int32{4}, []int{24}, 2)
+			Expect(forecast).To(Equal([]float64{4, 4}))
+			Expect(ForecastDemand(nil, nil, 1)).To(Equal([]float64{0}))
+		})
+	})
+
+	Context("When recording the demand", func() {
+		spec := &networkv1alpha1.PredictiveAutoscalerSpec{
+			SampleInterval: &metav1.Duration{Duration: time.Minute},
+			HistorySize:    4,
+		}
+		start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		It("Keeps one bounded sample per interval", func() {
+			status := &networkv1alpha1.GameAutoscalerStatus{}
+			RecordDemand(spec, status, 3, start)
+			Expect(status.DemandHistory.Samples).To(Equal([]int32{3}))
+
+			By("Keeping the highest count within the interval")
+			RecordDemand(spec, status, 5, start.Add(20*time.Second))
+			RecordDemand(spec, status, 4, start.Add(40*time.Second))
+			Expect(status.DemandHistory.Samples).To(Equal([]int32{5}))
+
+			By("Filling the missed intervals")
+			RecordDemand(spec, status, 2, start.Add(3*time.Minute+10*time.Second))
+			Expect(status.DemandHistory.Samples).To(Equal([]int32{5, 2, 2, 2}))
+			Expect(status.DemandHistory.LastSampleTime.Time).To(Equal(start.Add(3 * time.Minute)))
+
+			By("Dropping the oldest samples")
+			RecordDemand(spec, status, 7, start.Add(4*time.Minute))
+			Expect(status.DemandHistory.Samples).To(Equal([]int32{2, 2, 2, 7}))
+
+			By("Resetting the history when the interval changes")
+			changed := spec.DeepCopy()
+			changed.SampleInterval = &metav1.Duration{Duration: 2 * time.Minute}
+			RecordDemand(changed, status, 1, start.Add(5*time.Minute))
+			Expect(status.DemandHistory.Samples).To(Equal([]int32{1}))
+		})
+
+		It("Scales ahead of the forecast with the safety margin", func() {
+			scheme := runtime.NewScheme()
+			Expect(networkv1alpha1.AddToScheme(scheme)).To(Succeed())
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			fleet := &networkv1alpha1.Fleet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "game-fleet",
+					Namespace: "default",
+					Labels:    map[string]string{"type": "game"},
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				fleet,
+				gameServer("server-1", 10, false), gamePod("server-1", true, true),
+				gameServer("server-2", 0, false), gamePod("server-2", true, false),
+			).Build()
+			gametype := &networkv1alpha1.GameType{
+				ObjectMeta: metav1.ObjectMeta{Name: "game", Namespace: "default"},
+			}
+			gametype.Spec.FleetSpec.Scaling.Replicas = 2
+
+			status := &networkv1alpha1.GameAutoscalerStatus{
+				DemandHistory: &networkv1alpha1.DemandHistory{
+					Interval:       metav1.Duration{Duration: time.Minute},
+					LastSampleTime: metav1.Time{Time: start},
+					Samples:        []int32{10, 10, 10},
+				},
+			}
+			predictive := &networkv1alpha1.PredictiveAutoscalerSpec{
+				SampleInterval:      &metav1.Duration{Duration: time.Minute},
+				SafetyMarginPercent: 20,
+			}
+			result, err := PredictiveResponse(context.Background(), c, predictive, status, gametype, start)
+			Expect(err).To(BeNil())
+			Expect(status.DemandHistory.Samples).To(Equal([]int32{10, 10, 10}))
+			Expect(status.DemandHistory.Forecast).To(HaveValue(BeEquivalentTo(10)))
+			Expect(result).To(Equal(AutoscaleResponse{Scale: true, DesiredReplicas: 12}))
+
+			By("Starting the history from the allocated servers")
+			status.DemandHistory = nil
+			result, err = PredictiveResponse(context.Background(), c, predictive, status, gametype, start)
+			Expect(err).To(BeNil())
+			Expect(status.DemandHistory.Samples).To(Equal([]int32{1}))
+			Expect(result).To(Equal(AutoscaleResponse{Scale: false, DesiredReplicas: 2}))
+
+			By("Failing without the predictive field")
+			_, err = PredictiveResponse(context.Background(), c, nil, status, gametype, start)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
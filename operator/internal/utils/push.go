@@ -0,0 +1,26 @@
+package utils
+
+import (
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"time"
+)
+
+const defaultMaxPushTTL = time.Hour
+
+// PushExpiration returns when the pushed replicas stop being applied. A TTL longer than the max TTL of the policy is cut off.
+func PushExpiration(spec *networkv1alpha1.PushAutoscalerSpec, pushed *networkv1alpha1.PushedReplicas) time.Time {
+	maxTTL := defaultMaxPushTTL
+	if spec.MaxTTL != nil {
+		maxTTL = spec.MaxTTL.Duration
+	}
+	latest := pushed.Time.Add(maxTTL)
+	if pushed.ExpirationTime.After(latest) {
+		return latest
+	}
+	return pushed.ExpirationTime.Time
+}
+
+// PushActive returns whether the pushed replicas should be applied at the given time
+func PushActive(spec *networkv1alpha1.PushAutoscalerSpec, pushed *networkv1alpha1.PushedReplicas, now time.Time) bool {
+	return !now.Before(pushed.Time.Time) && now.Before(PushExpiration(spec, pushed))
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+var _ = Describe("Push Policy Testing", func() {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	pushed := &networkv1alpha1.PushedReplicas{
+		Replicas:       5,
+		Time:           metav1.Time{Time: now},
+		ExpirationTime: metav1.Time{Time: now.Add(3 * time.Hour)},
+	}
+
+	It("Cuts the TTL off at the max TTL", func() {
+		Expect(PushExpiration(&networkv1alpha1.PushAutoscalerSpec{}, pushed)).To(Equal(now.Add(time.Hour)))
+		spec := &networkv1alpha1.PushAutoscalerSpec{MaxTTL: &metav1.Duration{Duration: 24 * time.Hour}}
+		Expect(PushExpiration(spec, pushed)).To(Equal(now.Add(3 * time.Hour)))
+	})
+
+	It("Is only active between the push and its expiration", func() {
+		spec := &networkv1alpha1.PushAutoscalerSpec{}
+		Expect(PushActive(spec, pushed, now.Add(-time.Second))).To(BeFalse())
+		Expect(PushActive(spec, pushed, now)).To(BeTrue())
+		Expect(PushActive(spec, pushed, now.Add(59*time.Minute))).To(BeTrue())
+		Expect(PushActive(spec, pushed, now.Add(time.Hour))).To(BeFalse())
+	})
+})
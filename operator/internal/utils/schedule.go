@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	"time"
+)
+
+// ScheduleResponse is used by the schedule policy type to get the replica count for the given time.
+// Outside the windows it uses the default replicas, or keeps the current count if those are not set.
+func ScheduleResponse(spec *networkv1alpha1.ScheduleAutoscalerSpec, current int32, now time.Time) (AutoscaleResponse, error) {
+	if spec == nil {
+		return AutoscaleResponse{}, errors.New("missing schedule spec")
+	}
+	desired, _, active, err := ScheduleReplicas(spec, now)
+	if err != nil {
+		return AutoscaleResponse{}, err
+	}
+	if !active {
+		if spec.DefaultReplicas == nil {
+			return AutoscaleResponse{Scale: false, DesiredReplicas: int(current)}, nil
+		}
+		desired = *spec.DefaultReplicas
+	}
+	return AutoscaleResponse{
+		Scale:           desired != current,
+		DesiredReplicas: int(desired),
+	}, nil
+}
+
+// ApplyScheduleFloor makes sure the response of another policy does not go below the minimum of the active windows
+func ApplyScheduleFloor(response AutoscaleResponse, spec *networkv1alpha1.ScheduleAutoscalerSpec, current int32, now time.Time) (AutoscaleResponse, error) {
+	_, floor, active, err := ScheduleReplicas(spec, now)
+	if err != nil {
+		return AutoscaleResponse{}, err
+	}
+	if !active {
+		return response, nil
+	}
+
+	target := int(current)
+	if response.Scale {
+		target = response.DesiredReplicas
+	}
+	if target < int(floor) {
+		target = int(floor)
+	}
+	return AutoscaleResponse{
+		Scale:           target != int(current),
+		DesiredReplicas: target,
+	}, nil
+}
+
+// ScheduleReplicas goes over the windows active at the given time.
+// It returns the highest desired replica count and the highest minimum out of those windows.
+func ScheduleReplicas(spec *networkv1alpha1.ScheduleAutoscalerSpec, now time.Time) (int32, int32, bool, error) {
+	var desired, floor int32
+	active := false
+	for _, window := range spec.Windows {
+		start, err := activeWindowStart(spec, window, now)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if start == nil {
+			continue
+		}
+		active = true
+
+		windowDesired := window.MinReplicas
+		if window.DesiredReplicas != nil {
+			windowDesired = *window.DesiredReplicas
+		}
+		desired = max(desired, windowDesired)
+		floor = max(floor, window.MinReplicas)
+	}
+	return desired, floor, active, nil
+}
+
+// NextScheduleChange returns the next time after now when any of the windows starts or ends
+func NextScheduleChange(spec *networkv1alpha1.ScheduleAutoscalerSpec, now time.Time) (time.Time, error) {
+	var next time.Time
+	for _, window := range spec.Windows {
+		schedule, location, err := parseWindow(spec, window)
+		if err != nil {
+			return time.Time{}, err
+		}
+		candidate := schedule.Next(now.In(location))
+
+		start, err := activeWindowStart(spec, window, now)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if start != nil {
+			end := start.Add(window.Duration.Duration)
+			if candidate.IsZero() || end.Before(candidate) {
+				candidate = end
+			}
+		}
+
+		//Schedules that never fire again do not change anything
+		if candidate.IsZero() {
+			continue
+		}
+		if next.IsZero() || candidate.Before(next) {
+			next = candidate
+		}
+	}
+	return next, nil
+}
+
+// activeWindowStart returns when the window was started, if it is active at the given time
+func activeWindowStart(spec *networkv1alpha1.ScheduleAutoscalerSpec, window networkv1alpha1.ScheduleWindow, now time.Time) (*time.Time, error) {
+	schedule, location, err := parseWindow(spec, window)
+	if err != nil {
+		return nil, err
+	}
+	localNow := now.In(location)
+	//The first start after the earliest time the window could have started, that is still active now
+	start := schedule.Next(localNow.Add(-window.Duration.Duration))
+	if start.IsZero() || start.After(localNow) {
+		return nil, nil
+	}
+	return &start, nil
+}
+
+// parseWindow parses the cron expression of the window and finds the timezone it should be evaluated in
+func parseWindow(spec *networkv1alpha1.ScheduleAutoscalerSpec, window networkv1alpha1.ScheduleWindow) (cron.Schedule, *time.Location, error) {
+	timezone := window.Timezone
+	if timezone == "" {
+		timezone = spec.Timezone
+	}
+	location := time.UTC
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid timezone %s for window %s: %w", timezone, window.Name, err)
+		}
+		location = loaded
+	}
+
+	schedule, err := cron.ParseStandard(window.Start)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid start %s for window %s: %w", window.Start, window.Name, err)
+	}
+	return schedule, location, nil
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+var _ = Describe("Schedule Testing", func() {
+	desired := int32(10)
+	defaultReplicas := int32(2)
+	spec := &networkv1alpha1.ScheduleAutoscalerSpec{
+		Timezone:        "Europe/Tallinn",
+		DefaultReplicas: &defaultReplicas,
+		Windows: []networkv1alpha1.ScheduleWindow{
+			{
+				Name:            "evening",
+				Start:           "0 18 * * *",
+				Duration:        metav1.Duration{Duration: 4 * time.Hour},
+				MinReplicas:     5,
+				DesiredReplicas: &desired,
+			},
+			{
+				Name:        "weekend",
+				Start:       "0 12 * * 6",
+				Duration:    metav1.Duration{Duration: 12 * time.Hour},
+				MinReplicas: 7,
+			},
+		},
+	}
+	tallinn, err := time.LoadLocation("Europe/Tallinn")
+	Expect(err).ToNot(HaveOccurred())
+
+	Context("When evaluating the windows", func() {
+		It("Uses the default outside of the windows", func() {
+			//Wednesday morning
+			now := time.Date(2024, 10, 16, 9, 0, 0, 0, tallinn)
+			response, err := ScheduleResponse(spec, 4, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Scale).To(BeTrue())
+			Expect(response.DesiredReplicas).To(Equal(2))
+		})
+
+		It("Uses the desired replicas inside a window", func() {
+			now := time.Date(2024, 10, 16, 21, 59, 0, 0, tallinn)
+			response, err := ScheduleResponse(spec, 4, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Scale).To(BeTrue())
+			Expect(response.DesiredReplicas).To(Equal(10))
+		})
+
+		It("Evaluates the window in its timezone", func() {
+			//18:30 in Tallinn is 15:30 in UTC
+			now := time.Date(2024, 10, 16, 15, 30, 0, 0, time.UTC)
+			response, err := ScheduleResponse(spec, 10, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Scale).To(BeFalse())
+		})
+
+		It("Uses the highest of overlapping windows", func() {
+			//Saturday evening, both windows are active
+			now := time.Date(2024, 10, 19, 19, 0, 0, 0, tallinn)
+			desired, floor, active, err := ScheduleReplicas(spec, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(active).To(BeTrue())
+			Expect(desired).To(BeEquivalentTo(10))
+			Expect(floor).To(BeEquivalentTo(7))
+		})
+
+		It("Keeps the current replicas without a default", func() {
+			noDefault := spec.DeepCopy()
+			noDefault.DefaultReplicas = nil
+			now := time.Date(2024, 10, 16, 9, 0, 0, 0, tallinn)
+			response, err := ScheduleResponse(noDefault, 4, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Scale).To(BeFalse())
+		})
+	})
+
+	Context("When used as a floor", func() {
+		It("Raises the response of another policy", func() {
+			now := time.Date(2024, 10, 16, 19, 0, 0, 0, tallinn)
+			response, err := ApplyScheduleFloor(AutoscaleResponse{Scale: true, DesiredReplicas: 3}, spec, 4, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Scale).To(BeTrue())
+			Expect(response.DesiredReplicas).To(Equal(5))
+
+			response, err = ApplyScheduleFloor(AutoscaleResponse{Scale: true, DesiredReplicas: 9}, spec, 4, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.DesiredReplicas).To(Equal(9))
+		})
+
+		It("Does nothing outside of the windows", func() {
+			now := time.Date(2024, 10, 16, 9, 0, 0, 0, tallinn)
+			response, err := ApplyScheduleFloor(AutoscaleResponse{Scale: true, DesiredReplicas: 1}, spec, 4, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.DesiredReplicas).To(Equal(1))
+		})
+	})
+
+	Context("When finding the next change", func() {
+		It("Returns the next start", func() {
+			now := time.Date(2024, 10, 16, 9, 0, 0, 0, tallinn)
+			next, err := NextScheduleChange(spec, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(next.Equal(time.Date(2024, 10, 16, 18, 0, 0, 0, tallinn))).To(BeTrue())
+		})
+
+		It("Returns the end of an active window", func() {
+			now := time.Date(2024, 10, 16, 20, 0, 0, 0, tallinn)
+			next, err := NextScheduleChange(spec, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(next.Equal(time.Date(2024, 10, 16, 22, 0, 0, 0, tallinn))).To(BeTrue())
+		})
+	})
+})
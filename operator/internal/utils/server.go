@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"fmt"
 	"github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
 )
 
 func CreateServerForFleet(fleet v1alpha1.Fleet, namespace string) *v1alpha1.Server {
@@ -25,3 +29,28 @@ func CreateServerForFleet(fleet v1alpha1.Fleet, namespace string) *v1alpha1.Serv
 
 	return &server
 }
+
+// IsServerCompleted returns whether the game of a OneShot server has exited
+func IsServerCompleted(server *v1alpha1.Server) bool {
+	return meta.IsStatusConditionTrue(server.Status.Conditions, v1alpha1.ServerCompleted)
+}
+
+// GameExited returns whether every container of the pod other than the sidecar has terminated, with a message describing how.
+// Containers that are restarted after a failure are not terminated, so a crashed match that is retried does not count as exited.
+// The sidecar of a OneShot server is an init container, so it is not part of the container statuses at all.
+func GameExited(pod *corev1.Pod) (bool, string) {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return true, fmt.Sprintf("pod %s", pod.Status.Phase)
+	}
+	var exits []string
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == SidecarContainerName {
+			continue
+		}
+		if status.State.Terminated == nil {
+			return false, ""
+		}
+		exits = append(exits, fmt.Sprintf("container %s exited with code %d", status.Name, status.State.Terminated.ExitCode))
+	}
+	return len(exits) > 0, strings.Join(exits, ", ")
+}
@@ -19,6 +19,10 @@ func (p ProdDeletionChecker) IsDeletionAllowed(server *networkv1alpha1.Server, p
 	if server.Spec.AllowForceDelete {
 		return true, nil
 	}
+	// The game of a completed OneShot server has exited, so there is nothing left to ask
+	if IsServerCompleted(server) {
+		return true, nil
+	}
 
 	if server.Spec.TimeOut != nil {
 		timeWhenAllowDelete := server.GetDeletionTimestamp().Time.Add(server.Spec.TimeOut.Duration)
@@ -26,10 +30,26 @@ func (p ProdDeletionChecker) IsDeletionAllowed(server *networkv1alpha1.Server, p
 			return true, nil
 		}
 	}
-	err := RequestShutdown(pod)
+	// Servers pushing their state are read from the status, falling back to asking the sidecar until the first push arrives
+	if pushed := server.Status.Sidecar; pushed != nil {
+		if !pushed.ShutdownRequested {
+			if err := RequestShutdown(pod); err != nil {
+				return false, err
+			}
+		}
+		return pushed.DeleteAllowed, nil
+	}
+	// The shutdown is requested again whenever the sidecar does not know about it,
+	// which happens on the first check and when the sidecar came back without its state
+	requested, err := IsShutdownRequested(pod)
 	if err != nil {
 		return false, err
 	}
+	if !requested {
+		if err := RequestShutdown(pod); err != nil {
+			return false, err
+		}
+	}
 	allowed, err := IsDeleteAllowed(pod)
 	return allowed, err
 }
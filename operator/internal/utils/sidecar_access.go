@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"context"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SidecarAccountName returns the name of the ServiceAccount the pod of a server pushing its state runs as.
+// Every server has its own account, so the sidecar can only patch the status of its own server.
+func SidecarAccountName(server *networkv1alpha1.Server) string {
+	return server.Name + "-sidecar"
+}
+
+// EnsureSidecarAccess creates the ServiceAccount, Role and RoleBinding the sidecar needs to push its state, if they are missing.
+// They are owned by the server, so they are removed along with it.
+func EnsureSidecarAccess(ctx context.Context, c client.Client, server *networkv1alpha1.Server) error {
+	name := SidecarAccountName(server)
+	objectMeta := func() metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       server.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(server, networkv1alpha1.GroupVersion.WithKind("Server"))},
+		}
+	}
+	objects := []client.Object{
+		&corev1.ServiceAccount{ObjectMeta: objectMeta()},
+		&rbacv1.Role{
+			ObjectMeta: objectMeta(),
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups:     []string{networkv1alpha1.GroupVersion.Group},
+				Resources:     []string{"servers/status"},
+				ResourceNames: []string{server.Name},
+				Verbs:         []string{"patch"},
+			}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: objectMeta(),
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     name,
+			},
+			Subjects: []rbacv1.Subject{{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      name,
+				Namespace: server.Namespace,
+			}},
+		},
+	}
+	for _, object := range objects {
+		if err := c.Create(ctx, object); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// UsesSidecarAccount returns whether the pod of the server runs as the account from SidecarAccountName
+func UsesSidecarAccount(server *networkv1alpha1.Server) bool {
+	return server.Spec.PushState && server.Spec.Pod.ServiceAccountName == ""
+}
+
+// sidecarTokenVolume mounts the token of the sidecar account into the sidecar only, at the path the token is usually mounted to.
+// The token is not mounted into the game containers, as the game should not be able to change the status of the server.
+func sidecarTokenVolume() corev1.Volume {
+	expiration := int64(3607)
+	return corev1.Volume{
+		Name: sidecarTokenVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Path: "token", ExpirationSeconds: &expiration}},
+					{ConfigMap: &corev1.ConfigMapProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "kube-root-ca.crt"},
+						Items:                []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+					}},
+					{DownwardAPI: &corev1.DownwardAPIProjection{
+						Items: []corev1.DownwardAPIVolumeFile{{Path: "namespace", FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}}},
+					}},
+				},
+			},
+		},
+	}
+}
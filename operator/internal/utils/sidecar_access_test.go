@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkv1alpha1 "github.com/unfamousthomas/thesis-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"time"
+)
+
+var _ = Describe("Sidecar Push Testing", func() {
+	Context("When servers push their state", func() {
+		pushingServer := func(pushed *networkv1alpha1.SidecarStatus) *networkv1alpha1.Server {
+			server := &networkv1alpha1.Server{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "server",
+					Namespace:         "default",
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec:   networkv1alpha1.ServerSpec{PushState: true},
+				Status: networkv1alpha1.ServerStatus{Sidecar: pushed},
+			}
+			server.Spec.Pod.Containers = []corev1.Container{{Name: "game", Image: "game:latest"}}
+			return server
+		}
+		runningPod := &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+
+		It("Reads the deletion permission from the status", func() {
+			server := pushingServer(&networkv1alpha1.SidecarStatus{ShutdownRequested: true, DeleteAllowed: true})
+			allowed, err := ProdDeletionChecker{}.IsDeletionAllowed(server, runningPod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(allowed).To(BeTrue())
+
+			server.Status.Sidecar.DeleteAllowed = false
+			allowed, err = ProdDeletionChecker{}.IsDeletionAllowed(server, runningPod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(allowed).To(BeFalse())
+		})
+
+		It("Runs the pod as the sidecar service account", func() {
+			pod, _ := GetNewPod(pushingServer(nil), "default")
+			Expect(pod.Spec.ServiceAccountName).To(Equal("server-sidecar"))
+			sidecar := pod.Spec.Containers[1]
+			Expect(sidecar.Env).To(ContainElement(corev1.EnvVar{Name: "SIDECAR_PUSH_STATE", Value: "true"}))
+			Expect(pod.Spec.Containers[0].Env).ToNot(ContainElement(corev1.EnvVar{Name: "SIDECAR_PUSH_STATE", Value: "true"}))
+
+			By("Only mounting the token into the sidecar")
+			Expect(pod.Spec.AutomountServiceAccountToken).To(HaveValue(BeFalse()))
+			tokenMount := corev1.VolumeMount{Name: sidecarTokenVolumeName, MountPath: sidecarTokenPath, ReadOnly: true}
+			Expect(sidecar.VolumeMounts).To(ContainElement(tokenMount))
+			Expect(pod.Spec.Containers[0].VolumeMounts).ToNot(ContainElement(tokenMount))
+			Expect(pod.Spec.Volumes).To(ContainElement(HaveField("Name", sidecarTokenVolumeName)))
+
+			By("Keeping the service account set in the spec")
+			server := pushingServer(nil)
+			server.Spec.Pod.ServiceAccountName = "game"
+			Expect(UsesSidecarAccount(server)).To(BeFalse())
+			pod, _ = GetNewPod(server, "default")
+			Expect(pod.Spec.ServiceAccountName).To(Equal("game"))
+			Expect(pod.Spec.AutomountServiceAccountToken).To(BeNil())
+		})
+
+		It("Creates the access of the sidecar once", func() {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(rbacv1.AddToScheme(scheme)).To(Succeed())
+			c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+			server := pushingServer(nil)
+			Expect(EnsureSidecarAccess(ctx, c, server)).To(Succeed())
+			Expect(EnsureSidecarAccess(ctx, c, server)).To(Succeed())
+
+			name := types.NamespacedName{Name: "server-sidecar", Namespace: "default"}
+			account := &corev1.ServiceAccount{}
+			Expect(c.Get(ctx, name, account)).To(Succeed())
+			Expect(account.OwnerReferences).To(ConsistOf(HaveField("Name", "server")))
+			role := &rbacv1.Role{}
+			Expect(c.Get(ctx, name, role)).To(Succeed())
+			Expect(role.Rules).To(HaveLen(1))
+			Expect(role.Rules[0].Resources).To(Equal([]string{"servers/status"}))
+			Expect(role.Rules[0].ResourceNames).To(Equal([]string{"server"}))
+			Expect(role.Rules[0].Verbs).To(Equal([]string{"patch"}))
+			binding := &rbacv1.RoleBinding{}
+			Expect(c.Get(ctx, name, binding)).To(Succeed())
+			Expect(binding.Subjects).To(ConsistOf(HaveField("Name", "server-sidecar")))
+		})
+	})
+})
@@ -42,6 +42,30 @@ func IsDeleteAllowed(pod *v1.Pod) (bool, error) {
 	return request.Allowed, nil
 }
 
+// IsShutdownRequested sends a request to API/shutdown to check if the sidecar knows about the requested shutdown
+func IsShutdownRequested(pod *v1.Pod) (bool, error) {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	resp, err := client.Get(buildPodBaseAddress(pod) + "shutdown")
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, errors.New("GET request returned: " + resp.Status)
+	}
+
+	var request shutdownRequest
+	err = json.NewDecoder(resp.Body).Decode(&request)
+	if err != nil {
+		return false, err
+	}
+	return request.Shutdown, nil
+}
+
 // RequestShutdown sends a request to API/shutdown to tell the server that operator has requested its shutdown
 func RequestShutdown(pod *v1.Pod) error {
 	client := &http.Client{
@@ -0,0 +1,3 @@
+module github.com/unfamousthomas/thesis-sdk
+
+go 1.23.0
@@ -0,0 +1,385 @@
+// Package sdk is the Go client of the sidecar API, used by game servers running in a Server pod.
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// APIVersion is the newest version of the sidecar API the SDK speaks
+	APIVersion = 4
+	// DefaultAddress is the address of the sidecar within the pod
+	DefaultAddress = "http://localhost:8080"
+	// versionHeader is the header carrying the version of the state returned by the sidecar
+	versionHeader = "X-State-Version"
+	// watchWait is how long a single long-poll request waits for a change
+	watchWait = 30 * time.Second
+	// legacyPollInterval is how often the shutdown is polled from sidecars without long-poll support
+	legacyPollInterval = time.Second
+)
+
+// ErrUnsupported is returned when the sidecar is too old for the requested operation
+var ErrUnsupported = errors.New("operation not supported by the sidecar")
+
+// State is the state of the sidecar
+type State struct {
+	DeleteAllowed     bool              `json:"deleteAllowed"`
+	ShutdownRequested bool              `json:"shutdownRequested"`
+	Ready             bool              `json:"ready"`
+	Allocated         bool              `json:"allocated"`
+	Players           int64             `json:"players"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	Counters          map[string]int64  `json:"counters,omitempty"`
+	// Version increases on every change of the state
+	Version uint64 `json:"version"`
+}
+
+// StatusError is returned when the sidecar responds with an unexpected status code
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("sidecar returned status %d", e.StatusCode)
+}
+
+// Client talks to the sidecar of the pod.
+// It is safe for concurrent use.
+type Client struct {
+	address    string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+
+	negotiate  sync.Mutex
+	apiVersion *int
+	// versioned is set once the sidecar is known to serve the routes under the /v1 prefix
+	versioned atomic.Bool
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithAddress sets the address of the sidecar, for example the URL of a fake sidecar in tests
+func WithAddress(address string) Option {
+	return func(c *Client) {
+		c.address = address
+	}
+}
+
+// WithHTTPClient sets the http client used for the requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times failed requests are retried, and the backoff before the first retry.
+// The backoff doubles with every retry.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New creates a Client for the sidecar.
+// The address defaults to the SIDECAR_ADDRESS env var, or DefaultAddress when that is not set.
+func New(options ...Option) *Client {
+	address := os.Getenv("SIDECAR_ADDRESS")
+	if address == "" {
+		address = DefaultAddress
+	}
+	c := &Client{
+		address:    address,
+		httpClient: &http.Client{Timeout: watchWait + 10*time.Second},
+		retries:    3,
+		backoff:    200 * time.Millisecond,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// APIVersion negotiates the version of the API used with the sidecar, which is the newest version both sides speak.
+// Sidecars without the version route speak version 0, which only has the allow_delete and shutdown routes.
+// The result is cached after the first successful negotiation.
+func (c *Client) APIVersion(ctx context.Context) (int, error) {
+	c.negotiate.Lock()
+	defer c.negotiate.Unlock()
+	if c.apiVersion != nil {
+		return *c.apiVersion, nil
+	}
+	var response struct {
+		API int `json:"api"`
+	}
+	_, err := c.do(ctx, http.MethodGet, "/version", nil, &response)
+	var statusError *StatusError
+	if errors.As(err, &statusError) && statusError.StatusCode == http.StatusNotFound {
+		response.API, err = 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	version := min(response.API, APIVersion)
+	c.apiVersion = &version
+	c.versioned.Store(version >= 3)
+	return version, nil
+}
+
+// require returns ErrUnsupported when the sidecar does not speak the given version of the API
+func (c *Client) require(ctx context.Context, version int) error {
+	negotiated, err := c.APIVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if negotiated < version {
+		return fmt.Errorf("%w: needs API version %d, sidecar speaks %d", ErrUnsupported, version, negotiated)
+	}
+	return nil
+}
+
+// Ready tells the operator the game server can accept players
+func (c *Client) Ready(ctx context.Context) error {
+	if err := c.require(ctx, 1); err != nil {
+		return err
+	}
+	_, err := c.do(ctx, http.MethodPost, "/ready", map[string]bool{"ready": true}, nil)
+	return err
+}
+
+// AllowDelete sets whether the server is safe to delete.
+// Once the operator sees the server is allowed to be deleted, it may delete it at any time.
+func (c *Client) AllowDelete(ctx context.Context, allowed bool) error {
+	_, err := c.do(ctx, http.MethodPost, "/allow_delete", map[string]bool{"allowed": allowed}, nil)
+	return err
+}
+
+// ShutdownRequested returns whether the operator has requested the shutdown of the server
+func (c *Client) ShutdownRequested(ctx context.Context) (bool, error) {
+	var response struct {
+		Shutdown bool `json:"shutdown"`
+	}
+	_, err := c.do(ctx, http.MethodGet, "/shutdown", nil, &response)
+	return response.Shutdown, err
+}
+
+// WatchShutdown blocks until the operator requests the shutdown of the server, and returns nil then.
+// It returns the error of the context once it is done, and keeps retrying other errors.
+// Sidecars without long-poll support are polled instead.
+func (c *Client) WatchShutdown(ctx context.Context) error {
+	// The first request returns the current state right away, the following ones wait for it to change
+	var since *uint64
+	for {
+		var response struct {
+			Shutdown bool `json:"shutdown"`
+		}
+		path := "/shutdown"
+		if since != nil {
+			path += "?wait=" + watchWait.String() + "&since=" + strconv.FormatUint(*since, 10)
+		}
+		header, err := c.do(ctx, http.MethodGet, path, nil, &response)
+		if err == nil && response.Shutdown {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		since = nil
+		if err == nil && c.require(ctx, 1) == nil {
+			if version, err := strconv.ParseUint(header.Get(versionHeader), 10, 64); err == nil {
+				since = &version
+				continue
+			}
+		}
+		if err := sleep(ctx, legacyPollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// State returns the whole state of the sidecar
+func (c *Client) State(ctx context.Context) (State, error) {
+	if err := c.require(ctx, 1); err != nil {
+		return State{}, err
+	}
+	var state State
+	_, err := c.do(ctx, http.MethodGet, "/state", nil, &state)
+	return state, err
+}
+
+// Heartbeat tells the sidecar the game server is still alive
+func (c *Client) Heartbeat(ctx context.Context) error {
+	if err := c.require(ctx, 1); err != nil {
+		return err
+	}
+	_, err := c.do(ctx, http.MethodPost, "/heartbeat", nil, nil)
+	return err
+}
+
+// HeartbeatEvery sends a heartbeat at the given interval until the context is done.
+// Failed heartbeats are skipped, as the next one follows soon.
+func (c *Client) HeartbeatEvery(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Heartbeat(ctx); errors.Is(err, ErrUnsupported) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetAllocated tells the operator whether the server is in use, for example while a match is running on it.
+// The allocated servers are counted by the autoscalers, for example to keep a buffer of free servers. It requires pushState on the server.
+func (c *Client) SetAllocated(ctx context.Context, allocated bool) error {
+	if err := c.require(ctx, 4); err != nil {
+		return err
+	}
+	_, err := c.do(ctx, http.MethodPost, "/allocated", map[string]bool{"allocated": allocated}, nil)
+	return err
+}
+
+// SetPlayers reports the number of players on the server
+func (c *Client) SetPlayers(ctx context.Context, players int64) error {
+	if err := c.require(ctx, 1); err != nil {
+		return err
+	}
+	_, err := c.do(ctx, http.MethodPost, "/players", map[string]int64{"players": players}, nil)
+	return err
+}
+
+// Players returns the number of players last reported
+func (c *Client) Players(ctx context.Context) (int64, error) {
+	if err := c.require(ctx, 1); err != nil {
+		return 0, err
+	}
+	var response struct {
+		Players int64 `json:"players"`
+	}
+	_, err := c.do(ctx, http.MethodGet, "/players", nil, &response)
+	return response.Players, err
+}
+
+// SetMetadata merges the given keys into the metadata of the server. Keys with an empty value are removed.
+func (c *Client) SetMetadata(ctx context.Context, metadata map[string]string) error {
+	if err := c.require(ctx, 1); err != nil {
+		return err
+	}
+	_, err := c.do(ctx, http.MethodPost, "/metadata", map[string]map[string]string{"metadata": metadata}, nil)
+	return err
+}
+
+// Metadata returns the metadata of the server
+func (c *Client) Metadata(ctx context.Context) (map[string]string, error) {
+	if err := c.require(ctx, 1); err != nil {
+		return nil, err
+	}
+	var response struct {
+		Metadata map[string]string `json:"metadata"`
+	}
+	_, err := c.do(ctx, http.MethodGet, "/metadata", nil, &response)
+	return response.Metadata, err
+}
+
+// SetCounters sets the given custom counters of the server, leaving the others as they are.
+// The counters are exposed in the metrics of the sidecar.
+func (c *Client) SetCounters(ctx context.Context, counters map[string]int64) error {
+	if err := c.require(ctx, 2); err != nil {
+		return err
+	}
+	_, err := c.do(ctx, http.MethodPost, "/counters", map[string]map[string]int64{"counters": counters}, nil)
+	return err
+}
+
+// Counters returns the custom counters of the server
+func (c *Client) Counters(ctx context.Context) (map[string]int64, error) {
+	if err := c.require(ctx, 2); err != nil {
+		return nil, err
+	}
+	var response struct {
+		Counters map[string]int64 `json:"counters"`
+	}
+	_, err := c.do(ctx, http.MethodGet, "/counters", nil, &response)
+	return response.Counters, err
+}
+
+// do sends the request to the sidecar, retrying connection errors and server errors with a backoff.
+// Every route of the sidecar is idempotent, so all requests are safe to retry.
+func (c *Client) do(ctx context.Context, method string, path string, body any, response any) (http.Header, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		header, retry, err := c.send(ctx, method, path, data, response)
+		if !retry || attempt >= c.retries || ctx.Err() != nil {
+			return header, err
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// send sends a single request, and returns whether it is worth retrying
+func (c *Client) send(ctx context.Context, method string, path string, data []byte, response any) (http.Header, bool, error) {
+	if c.versioned.Load() {
+		path = "/v1" + path
+	}
+	request, err := http.NewRequestWithContext(ctx, method, c.address+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, false, err
+	}
+	if data != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return resp.Header, resp.StatusCode >= http.StatusInternalServerError, &StatusError{StatusCode: resp.StatusCode}
+	}
+	if response != nil {
+		if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+			return resp.Header, false, err
+		}
+	}
+	return resp.Header, false, nil
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,159 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	sdk "github.com/unfamousthomas/thesis-sdk"
+	"github.com/unfamousthomas/thesis-sdk/sdktest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReportState(t *testing.T) {
+	sidecar := sdktest.NewFakeSidecar(t)
+	client := sidecar.Client()
+	ctx := context.Background()
+
+	if err := client.Ready(ctx); err != nil {
+		t.Fatalf("Error setting ready: %v", err)
+	}
+	if err := client.SetAllocated(ctx, true); err != nil {
+		t.Fatalf("Error setting allocated: %v", err)
+	}
+	if err := client.SetPlayers(ctx, 7); err != nil {
+		t.Fatalf("Error setting players: %v", err)
+	}
+	if err := client.SetMetadata(ctx, map[string]string{"map": "dust"}); err != nil {
+		t.Fatalf("Error setting metadata: %v", err)
+	}
+	if err := client.SetCounters(ctx, map[string]int64{"matches": 2}); err != nil {
+		t.Fatalf("Error setting counters: %v", err)
+	}
+	if err := client.AllowDelete(ctx, true); err != nil {
+		t.Fatalf("Error allowing delete: %v", err)
+	}
+	if err := client.Heartbeat(ctx); err != nil {
+		t.Fatalf("Error sending heartbeat: %v", err)
+	}
+
+	state := sidecar.State()
+	if !state.Ready || !state.Allocated || state.Players != 7 || state.Metadata["map"] != "dust" || !state.DeleteAllowed {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+	if sidecar.Heartbeats() != 1 {
+		t.Fatalf("expected 1 heartbeat, got %d", sidecar.Heartbeats())
+	}
+
+	players, err := client.Players(ctx)
+	if err != nil || players != 7 {
+		t.Fatalf("expected 7 players, got %d (%v)", players, err)
+	}
+	metadata, err := client.Metadata(ctx)
+	if err != nil || metadata["map"] != "dust" {
+		t.Fatalf("expected the metadata to be returned, got %v (%v)", metadata, err)
+	}
+	counters, err := client.Counters(ctx)
+	if err != nil || counters["matches"] != 2 {
+		t.Fatalf("expected the counters to be returned, got %v (%v)", counters, err)
+	}
+}
+
+func TestWatchShutdown(t *testing.T) {
+	sidecar := sdktest.NewFakeSidecar(t)
+	client := sidecar.Client()
+
+	done := make(chan error)
+	go func() {
+		done <- client.WatchShutdown(context.Background())
+	}()
+	time.Sleep(10 * time.Millisecond)
+	sidecar.RequestShutdown()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Error watching shutdown: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the shutdown to be noticed")
+	}
+}
+
+func TestWatchShutdownCancelled(t *testing.T) {
+	sidecar := sdktest.NewFakeSidecar(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := sidecar.Client().WatchShutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	sidecar := sdktest.NewFakeSidecar(t)
+	sidecar.FailRequests(2)
+	if err := sidecar.Client().AllowDelete(context.Background(), true); err != nil {
+		t.Fatalf("expected the request to succeed after retries, got %v", err)
+	}
+
+	sidecar.FailRequests(5)
+	err := sidecar.Client(sdk.WithRetries(1, time.Millisecond)).AllowDelete(context.Background(), false)
+	var statusError *sdk.StatusError
+	if !errors.As(err, &statusError) {
+		t.Fatalf("expected a status error once the retries run out, got %v", err)
+	}
+}
+
+func TestVersionNegotiation(t *testing.T) {
+	sidecar := sdktest.NewFakeSidecar(t)
+	sidecar.SetAPIVersion(0)
+	client := sidecar.Client()
+	ctx := context.Background()
+
+	version, err := client.APIVersion(ctx)
+	if err != nil || version != 0 {
+		t.Fatalf("expected version 0, got %d (%v)", version, err)
+	}
+	if err := client.Ready(ctx); !errors.Is(err, sdk.ErrUnsupported) {
+		t.Fatalf("expected ready to be unsupported, got %v", err)
+	}
+	if err := client.AllowDelete(ctx, true); err != nil {
+		t.Fatalf("expected the original routes to keep working, got %v", err)
+	}
+
+	sidecar.SetAPIVersion(1)
+	if err := sidecar.Client().SetCounters(ctx, map[string]int64{"matches": 2}); !errors.Is(err, sdk.ErrUnsupported) {
+		t.Fatalf("expected counters to be unsupported by version 1, got %v", err)
+	}
+	sidecar.SetAPIVersion(3)
+	if err := sidecar.Client().SetAllocated(ctx, true); !errors.Is(err, sdk.ErrUnsupported) {
+		t.Fatalf("expected allocated to be unsupported by version 3, got %v", err)
+	}
+
+	sidecar.SetAPIVersion(sdk.APIVersion + 1)
+	version, err = sidecar.Client().APIVersion(ctx)
+	if err != nil || version != sdk.APIVersion {
+		t.Fatalf("expected the version of the SDK, got %d (%v)", version, err)
+	}
+}
+
+func TestVersionedRoutes(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/version" {
+			w.Write([]byte(`{"api": 3}`))
+		}
+	}))
+	defer server.Close()
+	client := sdk.New(sdk.WithAddress(server.URL))
+
+	if err := client.SetPlayers(context.Background(), 4); err != nil {
+		t.Fatalf("Error setting players: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "/version" || paths[1] != "/v1/players" {
+		t.Fatalf("expected the versioned routes to be used after negotiating, got %v", paths)
+	}
+}
@@ -0,0 +1,269 @@
+// Package sdktest provides a fake sidecar, so game code using the SDK can be tested without a cluster.
+package sdktest
+
+import (
+	"context"
+	"encoding/json"
+	sdk "github.com/unfamousthomas/thesis-sdk"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeSidecar is an in-memory sidecar served over HTTP.
+// The game code talks to it through the Client method, while tests play the operator with the other methods.
+type FakeSidecar struct {
+	server *httptest.Server
+
+	mu         sync.Mutex
+	state      sdk.State
+	changed    chan struct{}
+	heartbeats int
+	failures   int
+	apiVersion int
+}
+
+// NewFakeSidecar starts a fake sidecar. It is closed once the test finishes.
+func NewFakeSidecar(t testing.TB) *FakeSidecar {
+	f := &FakeSidecar{changed: make(chan struct{}), apiVersion: sdk.APIVersion}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /version", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		version := f.apiVersion
+		f.mu.Unlock()
+		if version == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, 0, map[string]int{"api": version})
+	})
+	mux.HandleFunc("GET /state", f.get(func(state sdk.State) any { return state }))
+	mux.HandleFunc("GET /allow_delete", f.get(func(state sdk.State) any {
+		return map[string]bool{"allowed": state.DeleteAllowed}
+	}))
+	mux.HandleFunc("POST /allow_delete", set(f, func(request struct {
+		Allowed bool `json:"allowed"`
+	}, state *sdk.State) {
+		state.DeleteAllowed = request.Allowed
+	}))
+	mux.HandleFunc("GET /shutdown", f.get(func(state sdk.State) any {
+		return map[string]bool{"shutdown": state.ShutdownRequested}
+	}))
+	mux.HandleFunc("GET /ready", f.get(func(state sdk.State) any {
+		return map[string]bool{"ready": state.Ready}
+	}))
+	mux.HandleFunc("POST /ready", set(f, func(request struct {
+		Ready bool `json:"ready"`
+	}, state *sdk.State) {
+		state.Ready = request.Ready
+	}))
+	mux.HandleFunc("GET /allocated", f.get(func(state sdk.State) any {
+		return map[string]bool{"allocated": state.Allocated}
+	}))
+	mux.HandleFunc("POST /allocated", set(f, func(request struct {
+		Allocated bool `json:"allocated"`
+	}, state *sdk.State) {
+		state.Allocated = request.Allocated
+	}))
+	mux.HandleFunc("GET /players", f.get(func(state sdk.State) any {
+		return map[string]int64{"players": state.Players}
+	}))
+	mux.HandleFunc("POST /players", set(f, func(request struct {
+		Players int64 `json:"players"`
+	}, state *sdk.State) {
+		state.Players = request.Players
+	}))
+	mux.HandleFunc("GET /metadata", f.get(func(state sdk.State) any {
+		return map[string]map[string]string{"metadata": state.Metadata}
+	}))
+	mux.HandleFunc("POST /metadata", set(f, func(request struct {
+		Metadata map[string]string `json:"metadata"`
+	}, state *sdk.State) {
+		for key, value := range request.Metadata {
+			if value == "" {
+				delete(state.Metadata, key)
+				continue
+			}
+			if state.Metadata == nil {
+				state.Metadata = make(map[string]string)
+			}
+			state.Metadata[key] = value
+		}
+	}))
+	mux.HandleFunc("GET /counters", f.get(func(state sdk.State) any {
+		return map[string]map[string]int64{"counters": state.Counters}
+	}))
+	mux.HandleFunc("POST /counters", set(f, func(request struct {
+		Counters map[string]int64 `json:"counters"`
+	}, state *sdk.State) {
+		for key, value := range request.Counters {
+			if state.Counters == nil {
+				state.Counters = make(map[string]int64)
+			}
+			state.Counters[key] = value
+		}
+	}))
+	mux.HandleFunc("POST /heartbeat", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.heartbeats++
+		f.mu.Unlock()
+	})
+	f.server = httptest.NewServer(f.failing(f.versioned(mux)))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// URL returns the address of the fake sidecar
+func (f *FakeSidecar) URL() string {
+	return f.server.URL
+}
+
+// Client returns a Client talking to the fake sidecar
+func (f *FakeSidecar) Client(options ...sdk.Option) *sdk.Client {
+	return sdk.New(append([]sdk.Option{sdk.WithAddress(f.URL()), sdk.WithRetries(3, time.Millisecond)}, options...)...)
+}
+
+// RequestShutdown requests the shutdown of the server, like the operator does when the Server is deleted
+func (f *FakeSidecar) RequestShutdown() {
+	f.update(func(state *sdk.State) {
+		state.ShutdownRequested = true
+	})
+}
+
+// State returns the state reported to the fake sidecar
+func (f *FakeSidecar) State() sdk.State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state := f.state
+	state.Metadata = maps.Clone(state.Metadata)
+	state.Counters = maps.Clone(state.Counters)
+	return state
+}
+
+// Heartbeats returns the number of heartbeats received
+func (f *FakeSidecar) Heartbeats() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.heartbeats
+}
+
+// FailRequests makes the next requests fail with 503 Service Unavailable, for testing retries
+func (f *FakeSidecar) FailRequests(requests int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = requests
+}
+
+// SetAPIVersion sets the version of the API the fake sidecar reports, for testing older sidecars.
+// Version 0 behaves like a sidecar without the version route.
+func (f *FakeSidecar) SetAPIVersion(version int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.apiVersion = version
+}
+
+func (f *FakeSidecar) update(change func(*sdk.State)) uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	change(&f.state)
+	f.state.Version++
+	close(f.changed)
+	f.changed = make(chan struct{})
+	return f.state.Version
+}
+
+func (f *FakeSidecar) failing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		fail := f.failures > 0
+		if fail {
+			f.failures--
+		}
+		f.mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// versioned serves the routes under the /v1 prefix as well, like sidecars speaking API version 3 and newer
+func (f *FakeSidecar) versioned(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path, ok := strings.CutPrefix(r.URL.Path, "/v1/"); ok {
+			f.mu.Lock()
+			version := f.apiVersion
+			f.mu.Unlock()
+			if version < 3 {
+				http.NotFound(w, r)
+				return
+			}
+			r = r.Clone(r.Context())
+			r.URL.Path = "/" + path
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// get builds a GET handler supporting the long-poll of the sidecar
+func (f *FakeSidecar) get(response func(sdk.State) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Has("wait") {
+			wait, err := time.ParseDuration(query.Get("wait"))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), wait)
+			defer cancel()
+			f.wait(ctx, query.Get("since"))
+		}
+		state := f.State()
+		writeJSON(w, state.Version, response(state))
+	}
+}
+
+func (f *FakeSidecar) wait(ctx context.Context, since string) {
+	for {
+		f.mu.Lock()
+		version, changed := strconv.FormatUint(f.state.Version, 10), f.changed
+		f.mu.Unlock()
+		if since != "" && version != since {
+			return
+		}
+		since = version
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// set builds a POST handler applying the decoded request to the state
+func set[T any](f *FakeSidecar, change func(T, *sdk.State)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request T
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		version := f.update(func(state *sdk.State) {
+			change(request, state)
+		})
+		writeJSON(w, version, request)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, version uint64, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-State-Version", strconv.FormatUint(version, 10))
+	json.NewEncoder(w).Encode(response)
+}
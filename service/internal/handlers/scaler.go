@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"github.com/unfamousthomas/thesis-service/internal/app"
 	"github.com/unfamousthomas/thesis-service/internal/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"log"
 	"net/http"
+	"time"
 )
 
 type CreateScalerRequest struct {
@@ -105,3 +107,114 @@ func DeleteScaler(a *app.App) func(http.ResponseWriter, *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 }
+
+type PushScalerRequest struct {
+	Metadata *kube.Metadata `json:"metadata"`
+	Replicas *int32         `json:"replicas"`
+	Reason   string         `json:"reason"`
+	// How long the replicas are applied for, as a duration like "30m"
+	TTL string `json:"ttl"`
+}
+
+// PushScaler is used to push a desired replica count to a scaler with the push policy, until the TTL expires
+func PushScaler(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var request PushScalerRequest
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			log.Printf("Error decoding request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if request.Metadata == nil || request.Replicas == nil || *request.Replicas < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		ttl, err := time.ParseDuration(request.TTL)
+		if err != nil || ttl <= 0 {
+			log.Printf("Invalid ttl %q: %v", request.TTL, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		now := time.Now()
+		pushed := kube.PushedReplicas{
+			Replicas:       *request.Replicas,
+			Reason:         request.Reason,
+			Time:           metav1.NewTime(now),
+			ExpirationTime: metav1.NewTime(now.Add(ttl)),
+		}
+		err = kube.PushReplicas(context.WithValue(context.Background(), "kube", "push-scaler"), *request.Metadata, pushed, a.DynamicClient)
+		if err != nil {
+			log.Printf("Error pushing replicas: %v", err)
+			e := map[string]string{
+				"message": "Error pushing replicas",
+				"error":   err.Error(),
+			}
+			jsonData, err := json.Marshal(e)
+			if err != nil {
+				log.Println("Error marshaling json:", err)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err = w.Write(jsonData)
+			if err != nil {
+				log.Println("Error writing response:", err)
+			}
+			return
+		}
+		jsonData, err := json.Marshal(pushed)
+		if err != nil {
+			log.Println("Error marshaling json:", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, err = w.Write(jsonData)
+		if err != nil {
+			log.Println("Error writing response:", err)
+			return
+		}
+	})
+}
+
+// ClearScalerPush is used to remove the pushed replica count, so the scaler reverts to its base policy right away
+func ClearScalerPush(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var request DeleteObjectRequest
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			log.Printf("Error decoding request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if request.Metadata == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = kube.ClearPushedReplicas(context.WithValue(context.Background(), "kube", "clear-scaler-push"), *request.Metadata, a.DynamicClient)
+		if err != nil {
+			log.Printf("Error clearing pushed replicas: %v\n", err)
+			e := map[string]string{
+				"message": "Error clearing pushed replicas",
+				"error":   err.Error(),
+			}
+			jsonData, err := json.Marshal(e)
+			if err != nil {
+				log.Println("Error marshaling json:", err)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err = w.Write(jsonData)
+			if err != nil {
+				log.Println("Error writing response:", err)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -17,7 +17,14 @@ var GameGCR = schema.GroupVersionResource{
 }
 
 type GameTypeSpec struct {
-	FleetSpec FleetSpec `json:"fleetSpec"`
+	FleetSpec FleetSpec         `json:"fleetSpec"`
+	Capacity  *GameTypeCapacity `json:"capacity,omitempty"`
+}
+
+// GameTypeCapacity sets how the game shares the capacity pools selecting it
+type GameTypeCapacity struct {
+	Priority           int32 `json:"priority,omitempty"`
+	GuaranteedReplicas int32 `json:"guaranteedReplicas,omitempty"`
 }
 
 type GameType struct {
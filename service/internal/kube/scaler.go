@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 )
 
@@ -14,44 +16,180 @@ type PolicyStrategy string
 type SyncStrategy string
 
 var validPolicyStrategies = map[PolicyStrategy]struct{}{
-	Webhook: {},
+	Webhook:    {},
+	Metrics:    {},
+	Schedule:   {},
+	Buffer:     {},
+	Composite:  {},
+	Predictive: {},
+	Push:       {},
 	// Add new strategies here as needed
 }
 var validSyncStrategy = map[SyncStrategy]struct{}{
 	FixedInterval: {},
+	Event:         {},
 	// Add new strategies here as needed
 }
 
 var (
 	Webhook       PolicyStrategy = "webhook"
+	Metrics       PolicyStrategy = "metrics"
+	Schedule      PolicyStrategy = "schedule"
+	Buffer        PolicyStrategy = "buffer"
+	Composite     PolicyStrategy = "composite"
+	Predictive    PolicyStrategy = "predictive"
+	Push          PolicyStrategy = "push"
 	FixedInterval SyncStrategy   = "fixedinterval"
+	Event         SyncStrategy   = "event"
 )
 
 type GameAutoscalerSpec struct {
-	GameName        string          `json:"gameName"`
-	AutoscalePolicy AutoscalePolicy `json:"policy"`
-	Sync            Sync            `json:"sync"`
+	GameName        string           `json:"gameName,omitempty"`
+	TargetRef       *TargetRef       `json:"targetRef,omitempty"`
+	AutoscalePolicy AutoscalePolicy  `json:"policy"`
+	Sync            Sync             `json:"sync"`
+	MinReplicas     *int32           `json:"minReplicas,omitempty"`
+	MaxReplicas     *int32           `json:"maxReplicas,omitempty"`
+	Behavior        *ScalingBehavior `json:"behavior,omitempty"`
+	FailurePolicy   *FailurePolicy   `json:"failurePolicy,omitempty"`
+	DryRun          bool             `json:"dryRun,omitempty"`
+}
+
+type TargetRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type FailurePolicy struct {
+	Type             string                  `json:"type,omitempty"`
+	FailureThreshold int32                   `json:"failureThreshold,omitempty"`
+	FallbackReplicas *int32                  `json:"fallbackReplicas,omitempty"`
+	FallbackSchedule *ScheduleAutoscalerSpec `json:"fallbackSchedule,omitempty"`
+	InitialBackoff   string                  `json:"initialBackoff,omitempty"`
+	MaxBackoff       string                  `json:"maxBackoff,omitempty"`
+}
+
+type ScalingBehavior struct {
+	ScaleUp   *ScalingRules `json:"scaleUp,omitempty"`
+	ScaleDown *ScalingRules `json:"scaleDown,omitempty"`
+}
+
+type ScalingRules struct {
+	StabilizationWindow string          `json:"stabilizationWindow,omitempty"`
+	SelectPolicy        string          `json:"selectPolicy,omitempty"`
+	Policies            []ScalingPolicy `json:"policies,omitempty"`
+	Cooldown            string          `json:"cooldown,omitempty"`
+}
+
+type ScalingPolicy struct {
+	Type   string `json:"type"`
+	Value  int32  `json:"value"`
+	Period string `json:"period"`
 }
 
 type AutoscalePolicy struct {
-	Type                  PolicyStrategy        `json:"type"`
-	WebhookAutoscalerSpec WebhookAutoscalerSpec `json:"webhook"`
+	Type                     PolicyStrategy            `json:"type"`
+	WebhookAutoscalerSpec    WebhookAutoscalerSpec     `json:"webhook"`
+	MetricsAutoscalerSpec    *MetricsAutoscalerSpec    `json:"metrics,omitempty"`
+	ScheduleAutoscalerSpec   *ScheduleAutoscalerSpec   `json:"schedule,omitempty"`
+	BufferAutoscalerSpec     *BufferAutoscalerSpec     `json:"buffer,omitempty"`
+	CompositeAutoscalerSpec  *CompositeAutoscalerSpec  `json:"composite,omitempty"`
+	PredictiveAutoscalerSpec *PredictiveAutoscalerSpec `json:"predictive,omitempty"`
+	PushAutoscalerSpec       *PushAutoscalerSpec       `json:"push,omitempty"`
+}
+
+type PushAutoscalerSpec struct {
+	Base   *CompositePolicy `json:"base,omitempty"`
+	MaxTTL string           `json:"maxTTL,omitempty"`
+}
+
+// PushAnnotation holds the PushedReplicas as JSON, the operator applies them with the push policy
+const PushAnnotation = "gameautoscalers.unfamousthomas.me/push"
+
+type PushedReplicas struct {
+	Replicas       int32       `json:"replicas"`
+	Reason         string      `json:"reason,omitempty"`
+	Time           metav1.Time `json:"time"`
+	ExpirationTime metav1.Time `json:"expirationTime"`
+}
+
+type CompositeAutoscalerSpec struct {
+	Combinator string            `json:"combinator,omitempty"`
+	Policies   []CompositePolicy `json:"policies"`
+}
+
+type CompositePolicy struct {
+	Name                     string                    `json:"name"`
+	Type                     PolicyStrategy            `json:"type"`
+	WebhookAutoscalerSpec    *WebhookAutoscalerSpec    `json:"webhook,omitempty"`
+	MetricsAutoscalerSpec    *MetricsAutoscalerSpec    `json:"metrics,omitempty"`
+	ScheduleAutoscalerSpec   *ScheduleAutoscalerSpec   `json:"schedule,omitempty"`
+	BufferAutoscalerSpec     *BufferAutoscalerSpec     `json:"buffer,omitempty"`
+	PredictiveAutoscalerSpec *PredictiveAutoscalerSpec `json:"predictive,omitempty"`
+}
+
+type BufferAutoscalerSpec struct {
+	Size int32 `json:"size"`
+}
+
+type PredictiveAutoscalerSpec struct {
+	SampleInterval      string   `json:"sampleInterval,omitempty"`
+	HistorySize         int32    `json:"historySize,omitempty"`
+	Seasons             []string `json:"seasons,omitempty"`
+	LeadTime            string   `json:"leadTime,omitempty"`
+	SafetyMarginPercent int32    `json:"safetyMarginPercent,omitempty"`
 }
 
 type WebhookAutoscalerSpec struct {
-	Url     *string `json:"url"`
-	Path    string  `json:"path"`
-	Service Service `json:"service"`
+	Url             *string           `json:"url"`
+	Path            string            `json:"path"`
+	Service         Service           `json:"service"`
+	ProtocolVersion *int32            `json:"protocolVersion,omitempty"`
+	CABundle        []byte            `json:"caBundle,omitempty"`
+	SecretRef       *WebhookSecretRef `json:"secretRef,omitempty"`
+	Transport       string            `json:"transport,omitempty"`
+	Timeout         string            `json:"timeout,omitempty"`
+}
+
+type WebhookSecretRef struct {
+	Name       string            `json:"name"`
+	TokenKey   string            `json:"tokenKey,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	SigningKey string            `json:"signingKey,omitempty"`
+}
+
+type MetricsAutoscalerSpec struct {
+	Url         string `json:"url"`
+	Query       string `json:"query"`
+	TargetValue string `json:"targetValue"`
+}
+
+type ScheduleAutoscalerSpec struct {
+	Timezone        string           `json:"timezone,omitempty"`
+	DefaultReplicas *int32           `json:"defaultReplicas,omitempty"`
+	Windows         []ScheduleWindow `json:"windows"`
+}
+
+type ScheduleWindow struct {
+	Name            string `json:"name"`
+	Start           string `json:"start"`
+	Duration        string `json:"duration"`
+	Timezone        string `json:"timezone,omitempty"`
+	MinReplicas     int32  `json:"minReplicas"`
+	DesiredReplicas *int32 `json:"desiredReplicas,omitempty"`
 }
 
 type Service struct {
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
 	Port      int    `json:"port"`
+	Scheme    string `json:"scheme,omitempty"`
 }
 type Sync struct {
 	Type          SyncStrategy `json:"type"`
 	FixedInterval int          `json:"fixedInterval"`
+	Debounce      string       `json:"debounce,omitempty"`
+	MinInterval   string       `json:"minInterval,omitempty"`
 }
 
 type GameAutoscaler struct {
@@ -61,9 +199,15 @@ type GameAutoscaler struct {
 	Spec       GameAutoscalerSpec `json:"spec"`
 }
 
+var ScalerGCR = schema.GroupVersionResource{
+	Group:    crdGroup,
+	Version:  crdVersion,
+	Resource: scalerResourceName,
+}
+
 // CreateScaler is used to create a new gameautoscaler using the dynamic client
 func CreateScaler(ctx context.Context, scaler *GameAutoscaler, client *dynamic.DynamicClient) error {
-	resource := client.Resource(ServerGCR).Namespace(scaler.Metadata.Namespace)
+	resource := client.Resource(ScalerGCR).Namespace(scaler.Metadata.Namespace)
 	scalerStruct, err := scalerToUnstructured(scaler)
 	if err != nil {
 		return err
@@ -77,7 +221,7 @@ func CreateScaler(ctx context.Context, scaler *GameAutoscaler, client *dynamic.D
 
 // DeleteScaler is used to delete a gameautoscaler, based on the namespace and name passed to the metadata
 func DeleteScaler(ctx context.Context, metadata Metadata, client *dynamic.DynamicClient) error {
-	resource := client.Resource(ServerGCR).Namespace(metadata.Namespace)
+	resource := client.Resource(ScalerGCR).Namespace(metadata.Namespace)
 	err := resource.Delete(ctx, metadata.Name, metav1.DeleteOptions{})
 	if err != nil {
 		return err
@@ -85,6 +229,35 @@ func DeleteScaler(ctx context.Context, metadata Metadata, client *dynamic.Dynami
 	return nil
 }
 
+// PushReplicas is used to push a replica count to a gameautoscaler with the push policy, by setting its push annotation
+func PushReplicas(ctx context.Context, metadata Metadata, pushed PushedReplicas, client *dynamic.DynamicClient) error {
+	value, err := json.Marshal(pushed)
+	if err != nil {
+		return err
+	}
+	return patchPushAnnotation(ctx, metadata, string(value), client)
+}
+
+// ClearPushedReplicas is used to remove the pushed replica count, so the gameautoscaler reverts to its base policy
+func ClearPushedReplicas(ctx context.Context, metadata Metadata, client *dynamic.DynamicClient) error {
+	return patchPushAnnotation(ctx, metadata, nil, client)
+}
+
+// patchPushAnnotation merges the push annotation into the gameautoscaler, a nil value removes it
+func patchPushAnnotation(ctx context.Context, metadata Metadata, value interface{}, client *dynamic.DynamicClient) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{PushAnnotation: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	resource := client.Resource(ScalerGCR).Namespace(metadata.Namespace)
+	_, err = resource.Patch(ctx, metadata.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
 // serverToUnstructured is used to make a GameAutoscaler object into an unstructured object which can interact with dynamic client
 func scalerToUnstructured(autoscaler *GameAutoscaler) (*unstructured.Unstructured, error) {
 	autoscaler.ApiVersion = crdGroup + "/" + crdVersion
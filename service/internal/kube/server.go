@@ -23,9 +23,20 @@ var ServerGCR = schema.GroupVersionResource{
 }
 
 type ServerSpec struct {
-	Pod              v1.PodSpec       `json:"pod,omitempty"`
-	TimeOut          *metav1.Duration `json:"timeout"`
-	AllowForceDelete bool             `json:"allowForceDelete,omitempty"`
+	Pod              v1.PodSpec        `json:"pod,omitempty"`
+	TimeOut          *metav1.Duration  `json:"timeout"`
+	AllowForceDelete bool              `json:"allowForceDelete,omitempty"`
+	PushState        bool              `json:"pushState,omitempty"`
+	ShutdownDelivery *ShutdownDelivery `json:"shutdownDelivery,omitempty"`
+	Lifecycle        string            `json:"lifecycle,omitempty"`
+}
+
+type ShutdownDelivery struct {
+	Signal            string `json:"signal,omitempty"`
+	Process           string `json:"process,omitempty"`
+	File              string `json:"file,omitempty"`
+	AllowDeleteFile   string `json:"allowDeleteFile,omitempty"`
+	AllowDeleteOnExit bool   `json:"allowDeleteOnExit,omitempty"`
 }
 
 type Server struct {
@@ -23,6 +23,8 @@ func SetupRoutes(a *app.App) {
 
 	a.Mux.HandleFunc("POST /scaler", handlers.CreateScaler(a))
 	a.Mux.HandleFunc("DELETE /scaler", handlers.DeleteScaler(a))
+	a.Mux.HandleFunc("POST /scaler/push", handlers.PushScaler(a))
+	a.Mux.HandleFunc("DELETE /scaler/push", handlers.ClearScalerPush(a))
 
 	a.Mux.HandleFunc("/health", handlers.Health(a))
 	err := http.ListenAndServe(":8080", a.Mux)
@@ -0,0 +1,584 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.5.1-go
+// source: sidecar/v1/sidecar.proto
+
+// The gRPC version of the sidecar API. It offers the same operations as the REST routes,
+// and both are backed by the same state, so a game server can use either transport.
+
+package sidecarv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Sidecar_GetState_FullMethodName         = "/sidecar.v1.Sidecar/GetState"
+	Sidecar_SetDeleteAllowed_FullMethodName = "/sidecar.v1.Sidecar/SetDeleteAllowed"
+	Sidecar_SetShutdown_FullMethodName      = "/sidecar.v1.Sidecar/SetShutdown"
+	Sidecar_SetReady_FullMethodName         = "/sidecar.v1.Sidecar/SetReady"
+	Sidecar_SetAllocated_FullMethodName     = "/sidecar.v1.Sidecar/SetAllocated"
+	Sidecar_SetPlayers_FullMethodName       = "/sidecar.v1.Sidecar/SetPlayers"
+	Sidecar_SetMetadata_FullMethodName      = "/sidecar.v1.Sidecar/SetMetadata"
+	Sidecar_SetCounters_FullMethodName      = "/sidecar.v1.Sidecar/SetCounters"
+	Sidecar_Heartbeat_FullMethodName        = "/sidecar.v1.Sidecar/Heartbeat"
+	Sidecar_GetVersion_FullMethodName       = "/sidecar.v1.Sidecar/GetVersion"
+	Sidecar_WatchState_FullMethodName       = "/sidecar.v1.Sidecar/WatchState"
+	Sidecar_WatchShutdown_FullMethodName    = "/sidecar.v1.Sidecar/WatchShutdown"
+)
+
+// SidecarClient is the client API for Sidecar service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SidecarClient interface {
+	// GetState returns the whole state, covering the GET routes of the REST API
+	GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*State, error)
+	SetDeleteAllowed(ctx context.Context, in *SetDeleteAllowedRequest, opts ...grpc.CallOption) (*State, error)
+	SetShutdown(ctx context.Context, in *SetShutdownRequest, opts ...grpc.CallOption) (*State, error)
+	SetReady(ctx context.Context, in *SetReadyRequest, opts ...grpc.CallOption) (*State, error)
+	SetAllocated(ctx context.Context, in *SetAllocatedRequest, opts ...grpc.CallOption) (*State, error)
+	SetPlayers(ctx context.Context, in *SetPlayersRequest, opts ...grpc.CallOption) (*State, error)
+	// SetMetadata merges the given keys into the metadata, keys with an empty value are removed
+	SetMetadata(ctx context.Context, in *SetMetadataRequest, opts ...grpc.CallOption) (*State, error)
+	// SetCounters sets the given counters, leaving the others as they are
+	SetCounters(ctx context.Context, in *SetCountersRequest, opts ...grpc.CallOption) (*State, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error)
+	// WatchState streams the state on every change, starting with the current state
+	WatchState(ctx context.Context, in *WatchStateRequest, opts ...grpc.CallOption) (Sidecar_WatchStateClient, error)
+	// WatchShutdown streams whether the shutdown is requested whenever that changes, starting with the current value
+	WatchShutdown(ctx context.Context, in *WatchShutdownRequest, opts ...grpc.CallOption) (Sidecar_WatchShutdownClient, error)
+}
+
+type sidecarClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSidecarClient(cc grpc.ClientConnInterface) SidecarClient {
+	return &sidecarClient{cc}
+}
+
+func (c *sidecarClient) GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, Sidecar_GetState_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sidecarClient) SetDeleteAllowed(ctx context.Context, in *SetDeleteAllowedRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, Sidecar_SetDeleteAllowed_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sidecarClient) SetShutdown(ctx context.Context, in *SetShutdownRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, Sidecar_SetShutdown_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sidecarClient) SetReady(ctx context.Context, in *SetReadyRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, Sidecar_SetReady_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sidecarClient) SetAllocated(ctx context.Context, in *SetAllocatedRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, Sidecar_SetAllocated_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sidecarClient) SetPlayers(ctx context.Context, in *SetPlayersRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, Sidecar_SetPlayers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sidecarClient) SetMetadata(ctx context.Context, in *SetMetadataRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, Sidecar_SetMetadata_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sidecarClient) SetCounters(ctx context.Context, in *SetCountersRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, Sidecar_SetCounters_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sidecarClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, Sidecar_Heartbeat_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sidecarClient) GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error) {
+	out := new(GetVersionResponse)
+	err := c.cc.Invoke(ctx, Sidecar_GetVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sidecarClient) WatchState(ctx context.Context, in *WatchStateRequest, opts ...grpc.CallOption) (Sidecar_WatchStateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sidecar_ServiceDesc.Streams[0], Sidecar_WatchState_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sidecarWatchStateClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sidecar_WatchStateClient interface {
+	Recv() (*State, error)
+	grpc.ClientStream
+}
+
+type sidecarWatchStateClient struct {
+	grpc.ClientStream
+}
+
+func (x *sidecarWatchStateClient) Recv() (*State, error) {
+	m := new(State)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sidecarClient) WatchShutdown(ctx context.Context, in *WatchShutdownRequest, opts ...grpc.CallOption) (Sidecar_WatchShutdownClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sidecar_ServiceDesc.Streams[1], Sidecar_WatchShutdown_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sidecarWatchShutdownClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sidecar_WatchShutdownClient interface {
+	Recv() (*ShutdownState, error)
+	grpc.ClientStream
+}
+
+type sidecarWatchShutdownClient struct {
+	grpc.ClientStream
+}
+
+func (x *sidecarWatchShutdownClient) Recv() (*ShutdownState, error) {
+	m := new(ShutdownState)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SidecarServer is the server API for Sidecar service.
+// All implementations must embed UnimplementedSidecarServer
+// for forward compatibility
+type SidecarServer interface {
+	// GetState returns the whole state, covering the GET routes of the REST API
+	GetState(context.Context, *GetStateRequest) (*State, error)
+	SetDeleteAllowed(context.Context, *SetDeleteAllowedRequest) (*State, error)
+	SetShutdown(context.Context, *SetShutdownRequest) (*State, error)
+	SetReady(context.Context, *SetReadyRequest) (*State, error)
+	SetAllocated(context.Context, *SetAllocatedRequest) (*State, error)
+	SetPlayers(context.Context, *SetPlayersRequest) (*State, error)
+	// SetMetadata merges the given keys into the metadata, keys with an empty value are removed
+	SetMetadata(context.Context, *SetMetadataRequest) (*State, error)
+	// SetCounters sets the given counters, leaving the others as they are
+	SetCounters(context.Context, *SetCountersRequest) (*State, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	GetVersion(context.Context, *GetVersionRequest) (*GetVersionResponse, error)
+	// WatchState streams the state on every change, starting with the current state
+	WatchState(*WatchStateRequest, Sidecar_WatchStateServer) error
+	// WatchShutdown streams whether the shutdown is requested whenever that changes, starting with the current value
+	WatchShutdown(*WatchShutdownRequest, Sidecar_WatchShutdownServer) error
+	mustEmbedUnimplementedSidecarServer()
+}
+
+// UnimplementedSidecarServer must be embedded to have forward compatible implementations.
+type UnimplementedSidecarServer struct {
+}
+
+func (UnimplementedSidecarServer) GetState(context.Context, *GetStateRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetState not implemented")
+}
+func (UnimplementedSidecarServer) SetDeleteAllowed(context.Context, *SetDeleteAllowedRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDeleteAllowed not implemented")
+}
+func (UnimplementedSidecarServer) SetShutdown(context.Context, *SetShutdownRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetShutdown not implemented")
+}
+func (UnimplementedSidecarServer) SetReady(context.Context, *SetReadyRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetReady not implemented")
+}
+func (UnimplementedSidecarServer) SetAllocated(context.Context, *SetAllocatedRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAllocated not implemented")
+}
+func (UnimplementedSidecarServer) SetPlayers(context.Context, *SetPlayersRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPlayers not implemented")
+}
+func (UnimplementedSidecarServer) SetMetadata(context.Context, *SetMetadataRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMetadata not implemented")
+}
+func (UnimplementedSidecarServer) SetCounters(context.Context, *SetCountersRequest) (*State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCounters not implemented")
+}
+func (UnimplementedSidecarServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedSidecarServer) GetVersion(context.Context, *GetVersionRequest) (*GetVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
+}
+func (UnimplementedSidecarServer) WatchState(*WatchStateRequest, Sidecar_WatchStateServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchState not implemented")
+}
+func (UnimplementedSidecarServer) WatchShutdown(*WatchShutdownRequest, Sidecar_WatchShutdownServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchShutdown not implemented")
+}
+func (UnimplementedSidecarServer) mustEmbedUnimplementedSidecarServer() {}
+
+// UnsafeSidecarServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SidecarServer will
+// result in compilation errors.
+type UnsafeSidecarServer interface {
+	mustEmbedUnimplementedSidecarServer()
+}
+
+func RegisterSidecarServer(s grpc.ServiceRegistrar, srv SidecarServer) {
+	s.RegisterService(&Sidecar_ServiceDesc, srv)
+}
+
+func _Sidecar_GetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SidecarServer).GetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sidecar_GetState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SidecarServer).GetState(ctx, req.(*GetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sidecar_SetDeleteAllowed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDeleteAllowedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SidecarServer).SetDeleteAllowed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sidecar_SetDeleteAllowed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SidecarServer).SetDeleteAllowed(ctx, req.(*SetDeleteAllowedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sidecar_SetShutdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetShutdownRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SidecarServer).SetShutdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sidecar_SetShutdown_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SidecarServer).SetShutdown(ctx, req.(*SetShutdownRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sidecar_SetReady_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetReadyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SidecarServer).SetReady(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sidecar_SetReady_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SidecarServer).SetReady(ctx, req.(*SetReadyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sidecar_SetAllocated_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAllocatedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SidecarServer).SetAllocated(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sidecar_SetAllocated_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SidecarServer).SetAllocated(ctx, req.(*SetAllocatedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sidecar_SetPlayers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPlayersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SidecarServer).SetPlayers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sidecar_SetPlayers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SidecarServer).SetPlayers(ctx, req.(*SetPlayersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sidecar_SetMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SidecarServer).SetMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sidecar_SetMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SidecarServer).SetMetadata(ctx, req.(*SetMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sidecar_SetCounters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCountersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SidecarServer).SetCounters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sidecar_SetCounters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SidecarServer).SetCounters(ctx, req.(*SetCountersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sidecar_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SidecarServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sidecar_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SidecarServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sidecar_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SidecarServer).GetVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sidecar_GetVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SidecarServer).GetVersion(ctx, req.(*GetVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sidecar_WatchState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SidecarServer).WatchState(m, &sidecarWatchStateServer{stream})
+}
+
+type Sidecar_WatchStateServer interface {
+	Send(*State) error
+	grpc.ServerStream
+}
+
+type sidecarWatchStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *sidecarWatchStateServer) Send(m *State) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Sidecar_WatchShutdown_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchShutdownRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SidecarServer).WatchShutdown(m, &sidecarWatchShutdownServer{stream})
+}
+
+type Sidecar_WatchShutdownServer interface {
+	Send(*ShutdownState) error
+	grpc.ServerStream
+}
+
+type sidecarWatchShutdownServer struct {
+	grpc.ServerStream
+}
+
+func (x *sidecarWatchShutdownServer) Send(m *ShutdownState) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Sidecar_ServiceDesc is the grpc.ServiceDesc for Sidecar service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Sidecar_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sidecar.v1.Sidecar",
+	HandlerType: (*SidecarServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetState",
+			Handler:    _Sidecar_GetState_Handler,
+		},
+		{
+			MethodName: "SetDeleteAllowed",
+			Handler:    _Sidecar_SetDeleteAllowed_Handler,
+		},
+		{
+			MethodName: "SetShutdown",
+			Handler:    _Sidecar_SetShutdown_Handler,
+		},
+		{
+			MethodName: "SetReady",
+			Handler:    _Sidecar_SetReady_Handler,
+		},
+		{
+			MethodName: "SetAllocated",
+			Handler:    _Sidecar_SetAllocated_Handler,
+		},
+		{
+			MethodName: "SetPlayers",
+			Handler:    _Sidecar_SetPlayers_Handler,
+		},
+		{
+			MethodName: "SetMetadata",
+			Handler:    _Sidecar_SetMetadata_Handler,
+		},
+		{
+			MethodName: "SetCounters",
+			Handler:    _Sidecar_SetCounters_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Sidecar_Heartbeat_Handler,
+		},
+		{
+			MethodName: "GetVersion",
+			Handler:    _Sidecar_GetVersion_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchState",
+			Handler:       _Sidecar_WatchState_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchShutdown",
+			Handler:       _Sidecar_WatchShutdown_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sidecar/v1/sidecar.proto",
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/unfamousthomas/thesis-sidecar/internal/local"
+	"github.com/unfamousthomas/thesis-sidecar/internal/routes"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ctlActions maps the actions of the ctl subcommand to the method, path and body of their request
+var ctlActions = map[string][3]string{
+	"shutdown":        {http.MethodPost, local.Prefix + "/shutdown", `{"shutdown": true}`},
+	"cancel-shutdown": {http.MethodPost, local.Prefix + "/shutdown", `{"shutdown": false}`},
+	"delete":          {http.MethodPost, local.Prefix + "/delete", ""},
+	"force-delete":    {http.MethodPost, local.Prefix + "/force_delete", ""},
+	"state":           {http.MethodGet, routes.VersionPrefix + "/state", ""},
+}
+
+// runCtl sends an operator action to a sidecar running in local mode, for example `sidecar ctl shutdown`
+func runCtl(args []string) error {
+	flags := flag.NewFlagSet("sidecar ctl", flag.ContinueOnError)
+	address := flags.String("address", "http://localhost:8080", "address of the local sidecar")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: sidecar ctl [-address url] shutdown|cancel-shutdown|delete|force-delete|state")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	action, ok := ctlActions[flags.Arg(0)]
+	if flags.NArg() != 1 || !ok {
+		flags.Usage()
+		return fmt.Errorf("unknown action %q", strings.Join(flags.Args(), " "))
+	}
+	request, err := http.NewRequest(action[0], strings.TrimSuffix(*address, "/")+action[1], bytes.NewBufferString(action[2]))
+	if err != nil {
+		return err
+	}
+	client := http.Client{Timeout: 10 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("sidecar responded with %s", response.Status)
+	}
+	_, err = io.Copy(os.Stdout, response.Body)
+	return err
+}
@@ -1,17 +1,144 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"github.com/unfamousthomas/thesis-sidecar/internal/config"
+	"github.com/unfamousthomas/thesis-sidecar/internal/delivery"
+	"github.com/unfamousthomas/thesis-sidecar/internal/local"
+	"github.com/unfamousthomas/thesis-sidecar/internal/push"
 	"github.com/unfamousthomas/thesis-sidecar/internal/routes"
+	"github.com/unfamousthomas/thesis-sidecar/internal/rpc"
+	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "ctl" {
+		if err := runCtl(os.Args[2:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Error reading configuration: %v", err)
+	}
+	if cfg.LogFormat == config.LogFormatJSON {
+		// The log package writes through the default slog logger once it is set
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
 	a := app.App{
-		Mux:               http.NewServeMux(),
-		ShutdownRequested: false,
-		DeleteAllowed:     false,
+		Mux:   http.NewServeMux(),
+		State: app.NewStore(os.Getenv("SIDECAR_STATE_FILE")),
+	}
+	if err := a.State.Restore(); err != nil {
+		log.Printf("Error restoring state: %v", err)
+	}
+
+	var operator *local.Operator
+	if cfg.Local {
+		// Without a cluster there is no operator, so the sidecar plays its part and exits once the server is deleted
+		operator = local.NewOperator(ctx, a.State, os.Stdout)
+		go operator.Watch(ctx)
+	}
+
+	if os.Getenv("SIDECAR_PUSH_STATE") == "true" && !cfg.Local {
+		pusher, err := push.NewInClusterPusher(os.Getenv("POD_NAMESPACE"), os.Getenv("SERVER_NAME"))
+		if err != nil {
+			log.Printf("Error setting up state pushing, the operator falls back to polling: %v", err)
+		} else {
+			go pusher.Run(ctx, a.State)
+		}
+	}
+
+	deliver := delivery.New()
+	// The signal was already checked when loading the configuration, and an empty one sends none
+	deliver.Signal, _ = delivery.ParseSignal(cfg.ShutdownSignal)
+	deliver.Process = cfg.GameProcess
+	deliver.ShutdownFile = cfg.ShutdownFile
+	deliver.AllowDeleteFile = cfg.AllowDeleteFile
+	deliver.AllowDeleteOnExit = cfg.AllowDeleteOnExit
+	if deliver.Enabled() {
+		go deliver.Run(ctx, a.State)
+	}
+
+	// Long-polls, event streams and gRPC streams are ended as soon as the shutdown starts, instead of holding up the drain
+	streams, endStreams := context.WithCancel(context.Background())
+	defer endStreams()
+
+	grpcServer := rpc.NewServer(streams, &a)
+	listener, err := net.Listen("tcp", cfg.GRPCAddress)
+	if err != nil {
+		log.Fatalf("Error starting gRPC server: %v", err)
 	}
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatalf("Error starting gRPC server: %v", err)
+		}
+	}()
 
 	routes.SetupRoutes(&a)
+	if operator != nil {
+		operator.SetupRoutes(a.Mux)
+		host, port, _ := net.SplitHostPort(cfg.Address)
+		if host == "" {
+			host = "localhost"
+		}
+		log.Printf("Running locally, control the server on http://%s%s or with `sidecar ctl`", net.JoinHostPort(host, port), local.Prefix)
+	}
+	server := &http.Server{
+		Addr:         cfg.Address,
+		Handler:      a.Mux,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+		BaseContext:  func(net.Listener) context.Context { return streams },
+	}
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Error starting server: %v", err)
+		}
+	}()
+
+	var deleted <-chan struct{}
+	if operator != nil {
+		deleted = operator.Deleted()
+	}
+	select {
+	case <-ctx.Done():
+	case <-deleted:
+	}
+	log.Printf("Shutting down, draining requests for at most %v", cfg.ShutdownTimeout)
+	endStreams()
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	if err := server.Shutdown(drainCtx); err != nil {
+		log.Printf("Error draining requests: %v", err)
+	}
+	select {
+	case <-grpcStopped:
+	case <-drainCtx.Done():
+		grpcServer.Stop()
+	}
 }
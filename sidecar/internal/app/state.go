@@ -0,0 +1,234 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// State is the state of the sidecar, shared between the game server and the operator
+type State struct {
+	DeleteAllowed     bool `json:"deleteAllowed"`
+	ShutdownRequested bool `json:"shutdownRequested"`
+	// Ready is set by the game server once it can accept players
+	Ready bool `json:"ready"`
+	// Allocated is set by the game server while it is in use, for example while a match is running on it
+	Allocated bool `json:"allocated"`
+	// Players is the number of players reported by the game server
+	Players int64 `json:"players"`
+	// Metadata is free-form information reported by the game server, for example the current map
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Counters are custom values reported by the game server, for example the number of matches in progress
+	Counters map[string]int64 `json:"counters,omitempty"`
+}
+
+// MergeMetadata merges the keys into the metadata, and removes the keys with an empty value
+func (s *State) MergeMetadata(metadata map[string]string) {
+	for key, value := range metadata {
+		if value == "" {
+			delete(s.Metadata, key)
+			continue
+		}
+		if s.Metadata == nil {
+			s.Metadata = make(map[string]string)
+		}
+		s.Metadata[key] = value
+	}
+}
+
+// MergeCounters sets the given counters, leaving the others as they are
+func (s *State) MergeCounters(counters map[string]int64) {
+	for key, value := range counters {
+		if s.Counters == nil {
+			s.Counters = make(map[string]int64)
+		}
+		s.Counters[key] = value
+	}
+}
+
+// clone returns a copy of the state that does not share the metadata or counters
+func (s State) clone() State {
+	s.Metadata = maps.Clone(s.Metadata)
+	s.Counters = maps.Clone(s.Counters)
+	return s
+}
+
+func (s State) equal(other State) bool {
+	return s.DeleteAllowed == other.DeleteAllowed &&
+		s.ShutdownRequested == other.ShutdownRequested &&
+		s.Ready == other.Ready &&
+		s.Allocated == other.Allocated &&
+		s.Players == other.Players &&
+		maps.Equal(s.Metadata, other.Metadata) &&
+		maps.Equal(s.Counters, other.Counters)
+}
+
+// savedState is what is written to the state file.
+// The version is saved too, so clients waiting for changes do not miss any after a restart.
+type savedState struct {
+	State
+	Version             uint64    `json:"version"`
+	ShutdownRequestedAt time.Time `json:"shutdownRequestedAt,omitempty"`
+}
+
+// Store holds the State of the sidecar, guarded by a lock so the handlers can use it concurrently.
+// Every change increases the version of the state and wakes up everyone waiting for a change.
+type Store struct {
+	mu      sync.Mutex
+	state   State
+	version uint64
+	// changed is closed and replaced on every change
+	changed chan struct{}
+	// lastHeartbeat is not part of the state, as heartbeats would otherwise wake up everyone waiting for changes
+	lastHeartbeat time.Time
+	// shutdownRequestedAt is when the shutdown was requested, or the zero time if it is not
+	shutdownRequestedAt time.Time
+	// file is the file the state is saved to, so it survives restarts of the sidecar container.
+	// When empty, the state is only kept in memory.
+	file string
+}
+
+// NewStore creates an empty Store, saving its state to the given file
+func NewStore(file string) *Store {
+	return &Store{
+		changed: make(chan struct{}),
+		file:    file,
+	}
+}
+
+// Get returns the current state and its version
+func (s *Store) Get() (State, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.clone(), s.version
+}
+
+// Heartbeat records a heartbeat of the game server
+func (s *Store) Heartbeat() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastHeartbeat = time.Now()
+}
+
+// LastHeartbeat returns the time of the last heartbeat, or the zero time if there was none
+func (s *Store) LastHeartbeat() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastHeartbeat
+}
+
+// ShutdownRequestedAt returns when the shutdown was requested, or the zero time if it is not
+func (s *Store) ShutdownRequestedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shutdownRequestedAt
+}
+
+// Update applies the change to the state and saves it.
+// When saving fails, the state is left unchanged and the error is returned.
+// Changes that do not change the state do not increase the version.
+func (s *Store) Update(change func(*State)) (State, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state.clone()
+	change(&state)
+	if state.equal(s.state) {
+		return s.state.clone(), s.version, nil
+	}
+	shutdownRequestedAt := s.shutdownRequestedAt
+	if state.ShutdownRequested != s.state.ShutdownRequested {
+		shutdownRequestedAt = time.Time{}
+		if state.ShutdownRequested {
+			shutdownRequestedAt = time.Now()
+		}
+	}
+	if err := s.save(state, s.version+1, shutdownRequestedAt); err != nil {
+		return s.state.clone(), s.version, err
+	}
+	s.state = state
+	s.shutdownRequestedAt = shutdownRequestedAt
+	s.version++
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return s.state.clone(), s.version, nil
+}
+
+// Wait blocks until the version of the state differs from since, or the context is done, and returns the state then.
+// A version newer than the current one also counts as different, as the state might have been lost with a restart.
+func (s *Store) Wait(ctx context.Context, since uint64) (State, uint64) {
+	for {
+		s.mu.Lock()
+		state, version, changed := s.state.clone(), s.version, s.changed
+		s.mu.Unlock()
+		if version != since {
+			return state, version
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return state, version
+		}
+	}
+}
+
+// save writes the state to the state file.
+// The state is first written to a temporary file next to it, which is then renamed over the old file,
+// so a crash in the middle of the write never leaves a partial state behind.
+func (s *Store) save(state State, version uint64, shutdownRequestedAt time.Time) error {
+	if s.file == "" {
+		return nil
+	}
+	data, err := json.Marshal(savedState{State: state, Version: version, ShutdownRequestedAt: shutdownRequestedAt})
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(s.file), filepath.Base(s.file)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), s.file)
+}
+
+// Restore reads the state saved by an earlier run of the sidecar from the state file.
+// A missing state file means there is nothing to restore, and leaves the state as is.
+func (s *Store) Restore() error {
+	if s.file == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var saved savedState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = saved.State
+	s.version = saved.Version
+	s.shutdownRequestedAt = saved.ShutdownRequestedAt
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return nil
+}
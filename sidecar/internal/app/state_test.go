@@ -0,0 +1,181 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSaveAndRestore(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	store := NewStore(stateFile)
+	_, version, err := store.Update(func(state *State) {
+		state.DeleteAllowed = true
+		state.ShutdownRequested = true
+	})
+	if err != nil {
+		t.Fatalf("Error saving state: %v", err)
+	}
+
+	restored := NewStore(stateFile)
+	if err := restored.Restore(); err != nil {
+		t.Fatalf("Error restoring state: %v", err)
+	}
+	state, restoredVersion := restored.Get()
+	if !state.DeleteAllowed || !state.ShutdownRequested {
+		t.Fatalf("expected the restored state to be true, got DeleteAllowed=%v ShutdownRequested=%v",
+			state.DeleteAllowed, state.ShutdownRequested)
+	}
+	if restoredVersion != version {
+		t.Fatalf("expected the restored version to be %d, got %d", version, restoredVersion)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(stateFile))
+	if err != nil {
+		t.Fatalf("Error reading state directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the state file to be left behind, got %d files", len(entries))
+	}
+}
+
+func TestRestoreMissingFile(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "state.json"))
+	if err := store.Restore(); err != nil {
+		t.Fatalf("Error restoring state: %v", err)
+	}
+	if state, version := store.Get(); !state.equal(State{}) || version != 0 {
+		t.Errorf("expected the state to be left as is, got %+v with version %d", state, version)
+	}
+}
+
+func TestRestoreInvalidFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(stateFile, []byte("{invalid_json}"), 0o600); err != nil {
+		t.Fatalf("Error writing state file: %v", err)
+	}
+	if err := NewStore(stateFile).Restore(); err == nil {
+		t.Fatalf("expected an error restoring an invalid state file")
+	}
+}
+
+func TestUpdateSaveFailed(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "missing", "state.json"))
+	_, version, err := store.Update(func(state *State) { state.DeleteAllowed = true })
+	if err == nil {
+		t.Fatalf("expected an error saving to a missing directory")
+	}
+	if state, _ := store.Get(); state.DeleteAllowed || version != 0 {
+		t.Errorf("expected the state to be unchanged, got %+v with version %d", state, version)
+	}
+}
+
+func TestUpdateVersion(t *testing.T) {
+	store := NewStore("")
+	_, version, _ := store.Update(func(state *State) { state.ShutdownRequested = true })
+	if version != 1 {
+		t.Fatalf("expected version 1 after a change, got %d", version)
+	}
+	_, version, _ = store.Update(func(state *State) { state.ShutdownRequested = true })
+	if version != 1 {
+		t.Fatalf("expected the version to stay 1 without a change, got %d", version)
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	store := NewStore("")
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.Update(func(state *State) { state.DeleteAllowed = i%2 == 0 })
+			store.Get()
+		}()
+	}
+	wg.Wait()
+	if _, version := store.Get(); version == 0 || version > 100 {
+		t.Fatalf("expected between 1 and 100 versions, got %d", version)
+	}
+}
+
+func TestWait(t *testing.T) {
+	store := NewStore("")
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		store.Update(func(state *State) { state.ShutdownRequested = true })
+	}()
+	state, version := store.Wait(context.Background(), 0)
+	if !state.ShutdownRequested || version != 1 {
+		t.Fatalf("expected the shutdown with version 1, got %+v with version %d", state, version)
+	}
+
+	if _, version := store.Wait(context.Background(), 0); version != 1 {
+		t.Fatalf("expected to return right away for an older version, got version %d", version)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, version := store.Wait(ctx, 1); version != 1 {
+		t.Fatalf("expected the current version once the context is done, got %d", version)
+	}
+}
+
+func TestMetadataIsCopied(t *testing.T) {
+	store := NewStore("")
+	store.Update(func(state *State) { state.Metadata = map[string]string{"map": "dust"} })
+	state, _ := store.Get()
+	state.Metadata["map"] = "mirage"
+
+	_, version, _ := store.Update(func(state *State) { state.Metadata["map"] = "dust" })
+	if version != 1 {
+		t.Fatalf("expected the metadata of the store to be unchanged, got version %d", version)
+	}
+	if state, _ := store.Get(); state.Metadata["map"] != "dust" {
+		t.Fatalf("expected the metadata of the store to be unchanged, got %v", state.Metadata)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	store := NewStore("")
+	if !store.LastHeartbeat().IsZero() {
+		t.Fatalf("expected no heartbeat yet")
+	}
+	store.Heartbeat()
+	if time.Since(store.LastHeartbeat()) > time.Second {
+		t.Fatalf("expected a recent heartbeat, got %v", store.LastHeartbeat())
+	}
+	if _, version := store.Get(); version != 0 {
+		t.Fatalf("expected heartbeats not to change the version, got %d", version)
+	}
+}
+
+func TestShutdownRequestedAt(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	store := NewStore(stateFile)
+	store.Update(func(state *State) { state.ShutdownRequested = true })
+	requestedAt := store.ShutdownRequestedAt()
+	if time.Since(requestedAt) > time.Second {
+		t.Fatalf("expected the shutdown to be requested just now, got %v", requestedAt)
+	}
+	store.Update(func(state *State) { state.DeleteAllowed = true })
+	if !store.ShutdownRequestedAt().Equal(requestedAt) {
+		t.Fatalf("expected other changes to keep the time of the request")
+	}
+
+	restored := NewStore(stateFile)
+	if err := restored.Restore(); err != nil {
+		t.Fatalf("Error restoring state: %v", err)
+	}
+	if !restored.ShutdownRequestedAt().Equal(requestedAt) {
+		t.Fatalf("expected the time of the request to be restored, got %v", restored.ShutdownRequestedAt())
+	}
+
+	store.Update(func(state *State) { state.ShutdownRequested = false })
+	if !store.ShutdownRequestedAt().IsZero() {
+		t.Fatalf("expected the time to be cleared with the request")
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/unfamousthomas/thesis-sidecar/internal/delivery"
+	"strconv"
+	"time"
+)
+
+const (
+	// LogFormatText keeps the plain log lines of the standard library
+	LogFormatText = "text"
+	// LogFormatJSON writes every log line as a JSON object, for log collectors
+	LogFormatJSON = "json"
+)
+
+// Config is the configuration of the sidecar.
+// Every setting can be given as a flag, or as an environment variable, with the flag taking precedence.
+type Config struct {
+	// Address is the address the REST API is served on
+	Address string
+	// GRPCAddress is the address the gRPC API is served on
+	GRPCAddress string
+	// ReadTimeout limits how long reading a request may take
+	ReadTimeout time.Duration
+	// WriteTimeout limits how long writing a response may take. Long-polls and event streams are exempt.
+	WriteTimeout time.Duration
+	// IdleTimeout is how long idle keep-alive connections are kept open
+	IdleTimeout time.Duration
+	// ShutdownTimeout is how long in-flight requests are drained for after SIGTERM
+	ShutdownTimeout time.Duration
+	// LogFormat is either LogFormatText or LogFormatJSON
+	LogFormat string
+	// Local runs the sidecar without a cluster, printing every change of the state and serving a control page in place of the operator
+	Local bool
+	// ShutdownSignal is sent to the game process when the shutdown is requested, for games that cannot call the sidecar
+	ShutdownSignal string
+	// GameProcess is the name of the game process to signal and watch, required with ShutdownSignal and AllowDeleteOnExit
+	GameProcess string
+	// ShutdownFile is written when the shutdown is requested, for games that cannot call the sidecar
+	ShutdownFile string
+	// AllowDeleteFile allows the deletion once the game creates it
+	AllowDeleteFile string
+	// AllowDeleteOnExit allows the deletion once the game process exits
+	AllowDeleteOnExit bool
+}
+
+// Load reads the configuration from the arguments, falling back to the environment and then the defaults.
+func Load(args []string, getenv func(string) string) (Config, error) {
+	var c Config
+	// envErr collects the environment variables that could not be parsed
+	var envErr error
+	flags := flag.NewFlagSet("sidecar", flag.ContinueOnError)
+	stringVar := func(value *string, name string, env string, fallback string, usage string) {
+		if fromEnv := getenv(env); fromEnv != "" {
+			fallback = fromEnv
+		}
+		flags.StringVar(value, name, fallback, usage+" (env "+env+")")
+	}
+	durationVar := func(value *time.Duration, name string, env string, fallback time.Duration, usage string) {
+		if fromEnv := getenv(env); fromEnv != "" {
+			parsed, parseErr := time.ParseDuration(fromEnv)
+			if parseErr != nil {
+				envErr = errors.Join(envErr, fmt.Errorf("invalid %s: %w", env, parseErr))
+			}
+			fallback = parsed
+		}
+		flags.DurationVar(value, name, fallback, usage+" (env "+env+")")
+	}
+	boolVar := func(value *bool, name string, env string, usage string) {
+		fallback := false
+		if fromEnv := getenv(env); fromEnv != "" {
+			parsed, parseErr := strconv.ParseBool(fromEnv)
+			if parseErr != nil {
+				envErr = errors.Join(envErr, fmt.Errorf("invalid %s: %w", env, parseErr))
+			}
+			fallback = parsed
+		}
+		flags.BoolVar(value, name, fallback, usage+" (env "+env+")")
+	}
+	stringVar(&c.Address, "address", "SIDECAR_LISTEN_ADDRESS", ":8080", "address of the REST API")
+	stringVar(&c.GRPCAddress, "grpc-address", "SIDECAR_GRPC_ADDRESS", ":8081", "address of the gRPC API")
+	durationVar(&c.ReadTimeout, "read-timeout", "SIDECAR_READ_TIMEOUT", 10*time.Second, "maximum duration for reading a request")
+	durationVar(&c.WriteTimeout, "write-timeout", "SIDECAR_WRITE_TIMEOUT", 10*time.Second, "maximum duration for writing a response, long-polls and event streams excluded")
+	durationVar(&c.IdleTimeout, "idle-timeout", "SIDECAR_IDLE_TIMEOUT", 60*time.Second, "how long idle connections are kept open")
+	durationVar(&c.ShutdownTimeout, "shutdown-timeout", "SIDECAR_SHUTDOWN_TIMEOUT", 10*time.Second, "how long in-flight requests are drained for on SIGTERM")
+	stringVar(&c.LogFormat, "log-format", "SIDECAR_LOG_FORMAT", LogFormatText, "format of the logs, text or json")
+	stringVar(&c.ShutdownSignal, "shutdown-signal", "SIDECAR_SHUTDOWN_SIGNAL", "", "signal sent to the game process when the shutdown is requested, for example SIGTERM")
+	stringVar(&c.GameProcess, "game-process", "SIDECAR_GAME_PROCESS", "", "name of the game process, required with the shutdown signal and allow delete on exit")
+	stringVar(&c.ShutdownFile, "shutdown-file", "SIDECAR_SHUTDOWN_FILE", "", "file written when the shutdown is requested")
+	stringVar(&c.AllowDeleteFile, "allow-delete-file", "SIDECAR_ALLOW_DELETE_FILE", "", "file that allows the deletion once it exists")
+	boolVar(&c.AllowDeleteOnExit, "allow-delete-on-exit", "SIDECAR_ALLOW_DELETE_ON_EXIT", "allow the deletion once the game process exits")
+	boolVar(&c.Local, "local", "SIDECAR_LOCAL", "run without a cluster, printing state changes and serving a control page on /local")
+	if envErr != nil {
+		return c, envErr
+	}
+	if err := flags.Parse(args); err != nil {
+		return c, err
+	}
+	if c.LogFormat != LogFormatText && c.LogFormat != LogFormatJSON {
+		return c, fmt.Errorf("unknown log format %q, expected %s or %s", c.LogFormat, LogFormatText, LogFormatJSON)
+	}
+	if c.ShutdownSignal != "" {
+		if _, err := delivery.ParseSignal(c.ShutdownSignal); err != nil {
+			return c, err
+		}
+	}
+	if (c.ShutdownSignal != "" || c.AllowDeleteOnExit) && c.GameProcess == "" {
+		return c, errors.New("the game process is required with the shutdown signal and allow delete on exit")
+	}
+	return c, nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func env(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	c, err := Load(nil, env(nil))
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if c.Address != ":8080" || c.GRPCAddress != ":8081" || c.LogFormat != LogFormatText || c.ShutdownTimeout != 10*time.Second {
+		t.Fatalf("unexpected defaults: %+v", c)
+	}
+}
+
+func TestLoadFlagsOverrideEnv(t *testing.T) {
+	c, err := Load([]string{"-address", ":9090", "-write-timeout", "30s"}, env(map[string]string{
+		"SIDECAR_LISTEN_ADDRESS": ":7070",
+		"SIDECAR_LOG_FORMAT":     "json",
+		"SIDECAR_WRITE_TIMEOUT":  "5s",
+		"SIDECAR_LOCAL":          "true",
+	}))
+	if err != nil {
+		t.Fatalf("Error loading configuration: %v", err)
+	}
+	if c.Address != ":9090" || c.WriteTimeout != 30*time.Second {
+		t.Fatalf("expected the flags to take precedence, got %+v", c)
+	}
+	if c.LogFormat != LogFormatJSON {
+		t.Fatalf("expected the log format from the environment, got %q", c.LogFormat)
+	}
+	if !c.Local {
+		t.Fatalf("expected local mode from the environment")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	if _, err := Load(nil, env(map[string]string{"SIDECAR_READ_TIMEOUT": "soon"})); err == nil {
+		t.Fatalf("expected an error for an invalid duration")
+	}
+	if _, err := Load(nil, env(map[string]string{"SIDECAR_LOCAL": "maybe"})); err == nil {
+		t.Fatalf("expected an error for an invalid boolean")
+	}
+	if _, err := Load([]string{"-log-format", "xml"}, env(nil)); err == nil {
+		t.Fatalf("expected an error for an unknown log format")
+	}
+	if _, err := Load([]string{"-shutdown-signal", "SIGSTOP"}, env(nil)); err == nil {
+		t.Fatalf("expected an error for an unknown signal")
+	}
+	if _, err := Load([]string{"-shutdown-signal", "SIGTERM"}, env(nil)); err == nil {
+		t.Fatalf("expected an error for a signal without the game process")
+	}
+	if _, err := Load([]string{"-allow-delete-on-exit"}, env(nil)); err == nil {
+		t.Fatalf("expected an error for allow delete on exit without the game process")
+	}
+	if _, err := Load([]string{"-shutdown-signal", "SIGTERM", "-game-process", "game"}, env(nil)); err != nil {
+		t.Fatalf("expected the signal to be allowed with the game process, got %v", err)
+	}
+}
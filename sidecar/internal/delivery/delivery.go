@@ -0,0 +1,224 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// signals are the signals the shutdown can be delivered with, by name
+var signals = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
+// ParseSignal returns the signal with the name, for example SIGTERM
+func ParseSignal(name string) (syscall.Signal, error) {
+	signal, ok := signals[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown signal %q", name)
+	}
+	return signal, nil
+}
+
+// Delivery translates the state of the sidecar for game servers that cannot call it.
+// The shutdown request is delivered as a signal to the game process or as a file,
+// and the deletion is allowed once the game process exits or creates a file.
+type Delivery struct {
+	// Signal is sent to the game process when the shutdown is requested, or 0 to send none
+	Signal syscall.Signal
+	// Process is the name of the game process. It has to be set for the signal and AllowDeleteOnExit, no process matches without it.
+	Process string
+	// ShutdownFile is written when the shutdown is requested, and removed if the request is withdrawn
+	ShutdownFile string
+	// AllowDeleteFile allows the deletion once it exists
+	AllowDeleteFile string
+	// AllowDeleteOnExit allows the deletion once the game process exits, after it was seen running
+	AllowDeleteOnExit bool
+	// ProcDir is where the processes of the pod are listed
+	ProcDir string
+	// PollInterval is how often the allow delete file and the game process are checked
+	PollInterval time.Duration
+
+	// kill sends the signal to a process, replaced in tests
+	kill func(pid int, signal syscall.Signal) error
+	// gameSeen is set once the game process was found, so a game that has not started yet is not taken for one that exited
+	gameSeen bool
+}
+
+// New creates a Delivery with the defaults for running in a pod
+func New() *Delivery {
+	return &Delivery{
+		ProcDir:      "/proc",
+		PollInterval: time.Second,
+		kill:         syscall.Kill,
+	}
+}
+
+// Enabled returns whether any translation is configured
+func (d *Delivery) Enabled() bool {
+	return d.Signal != 0 || d.ShutdownFile != "" || d.AllowDeleteFile != "" || d.AllowDeleteOnExit
+}
+
+// Run delivers the shutdown requests and watches for the game allowing its deletion, until the context is done
+func (d *Delivery) Run(ctx context.Context, store *app.Store) {
+	if d.Signal != 0 || d.ShutdownFile != "" {
+		go d.deliverShutdowns(ctx, store)
+	}
+	if d.AllowDeleteFile == "" && !d.AllowDeleteOnExit {
+		return
+	}
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+	for {
+		if reason := d.deleteAllowedBy(); reason != "" {
+			d.allowDelete(store, reason)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverShutdowns delivers the shutdown whenever it is requested, including a request restored from an earlier run of the sidecar
+func (d *Delivery) deliverShutdowns(ctx context.Context, store *app.Store) {
+	requested := false
+	state, version := store.Get()
+	for {
+		if state.ShutdownRequested != requested {
+			requested = state.ShutdownRequested
+			if requested {
+				d.deliverShutdown()
+			} else {
+				d.withdrawShutdown()
+			}
+		}
+		state, version = store.Wait(ctx, version)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (d *Delivery) deliverShutdown() {
+	if d.ShutdownFile != "" {
+		err := os.WriteFile(d.ShutdownFile, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0o644)
+		if err != nil {
+			log.Printf("Error writing shutdown file: %v", err)
+		}
+	}
+	if d.Signal == 0 {
+		return
+	}
+	pids, err := d.gamePids()
+	if err != nil {
+		log.Printf("Error finding the game process: %v", err)
+		return
+	}
+	if len(pids) == 0 {
+		log.Printf("No game process found to send %v to", d.Signal)
+	}
+	for _, pid := range pids {
+		if err := d.kill(pid, d.Signal); err != nil {
+			log.Printf("Error sending %v to process %d: %v", d.Signal, pid, err)
+		}
+	}
+}
+
+func (d *Delivery) withdrawShutdown() {
+	if d.ShutdownFile == "" {
+		return
+	}
+	if err := os.Remove(d.ShutdownFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Error removing shutdown file: %v", err)
+	}
+}
+
+// deleteAllowedBy returns why the game allows its deletion, or an empty string if it does not
+func (d *Delivery) deleteAllowedBy() string {
+	if d.AllowDeleteFile != "" {
+		if _, err := os.Stat(d.AllowDeleteFile); err == nil {
+			return "the allow delete file exists"
+		}
+	}
+	if d.AllowDeleteOnExit {
+		pids, err := d.gamePids()
+		if err != nil {
+			log.Printf("Error finding the game process: %v", err)
+			return ""
+		}
+		if len(pids) > 0 {
+			d.gameSeen = true
+		} else if d.gameSeen {
+			return "the game process exited"
+		}
+	}
+	return ""
+}
+
+func (d *Delivery) allowDelete(store *app.Store, reason string) {
+	if state, _ := store.Get(); state.DeleteAllowed {
+		return
+	}
+	_, _, err := store.Update(func(state *app.State) {
+		state.DeleteAllowed = true
+	})
+	if err != nil {
+		log.Printf("Error saving state: %v", err)
+		return
+	}
+	log.Printf("Allowed the deletion, as %s", reason)
+}
+
+// gamePids lists the processes of the game. Only processes matching Process count, as a shared namespace also holds
+// the pause process, the sidecar and any other sidecars or shells of the pod.
+func (d *Delivery) gamePids() ([]int, error) {
+	if d.Process == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(d.ProcDir)
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == 1 || pid == os.Getpid() {
+			continue
+		}
+		if !d.isGame(pid) {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+// isGame matches the process against Process, by its command name or the file name of its executable.
+// The command name is cut to 15 characters by the kernel, so longer names only match the executable.
+func (d *Delivery) isGame(pid int) bool {
+	dir := filepath.Join(d.ProcDir, strconv.Itoa(pid))
+	if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil && strings.TrimSpace(string(comm)) == d.Process {
+		return true
+	}
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return false
+	}
+	executable, _, _ := strings.Cut(string(cmdline), "\x00")
+	return executable != "" && filepath.Base(executable) == d.Process
+}
@@ -0,0 +1,167 @@
+package delivery
+
+import (
+	"context"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// addProcess adds a fake process to the proc directory
+func addProcess(t *testing.T, procDir string, pid int, comm string, cmdline string) {
+	dir := filepath.Join(procDir, strconv.Itoa(pid))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("Error creating process: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "comm"), []byte(comm+"\n"), 0o644); err != nil {
+		t.Fatalf("Error creating process: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline+"\x00"), 0o644); err != nil {
+		t.Fatalf("Error creating process: %v", err)
+	}
+}
+
+func newTestDelivery(t *testing.T) *Delivery {
+	d := New()
+	d.ProcDir = t.TempDir()
+	d.PollInterval = 10 * time.Millisecond
+	addProcess(t, d.ProcDir, 1, "pause", "/pause")
+	addProcess(t, d.ProcDir, os.Getpid(), "sidecar", "/sidecar")
+	return d
+}
+
+func TestParseSignal(t *testing.T) {
+	if signal, err := ParseSignal("sigusr1"); err != nil || signal != syscall.SIGUSR1 {
+		t.Fatalf("expected SIGUSR1, got %v with error %v", signal, err)
+	}
+	if _, err := ParseSignal("SIGKILL"); err == nil {
+		t.Fatalf("expected an error for a signal that cannot be handled")
+	}
+}
+
+func TestGamePids(t *testing.T) {
+	d := newTestDelivery(t)
+	addProcess(t, d.ProcDir, 40, "game", "/bin/game")
+	addProcess(t, d.ProcDir, 41, "a-very-long-gam", "/opt/a-very-long-game-server")
+	addProcess(t, d.ProcDir, 42, "sh", "/bin/sh")
+
+	pids, err := d.gamePids()
+	if err != nil {
+		t.Fatalf("Error listing processes: %v", err)
+	}
+	if len(pids) != 0 {
+		t.Fatalf("expected no process to count as the game without its name, got %v", pids)
+	}
+
+	d.Process = "a-very-long-game-server"
+	if pids, _ := d.gamePids(); !slices.Equal(pids, []int{41}) {
+		t.Fatalf("expected the process to be matched by its executable, got %v", pids)
+	}
+	d.Process = "game"
+	if pids, _ := d.gamePids(); !slices.Equal(pids, []int{40}) {
+		t.Fatalf("expected the process to be matched by its command name, got %v", pids)
+	}
+}
+
+func TestDeliverShutdown(t *testing.T) {
+	d := newTestDelivery(t)
+	addProcess(t, d.ProcDir, 40, "game", "/bin/game")
+	addProcess(t, d.ProcDir, 41, "envoy", "/usr/local/bin/envoy")
+	d.Signal = syscall.SIGUSR1
+	d.Process = "game"
+	d.ShutdownFile = filepath.Join(t.TempDir(), "shutdown")
+	var mu sync.Mutex
+	var signalled []int
+	d.kill = func(pid int, signal syscall.Signal) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if signal != syscall.SIGUSR1 {
+			t.Errorf("unexpected signal %v", signal)
+		}
+		signalled = append(signalled, pid)
+		return nil
+	}
+	store := app.NewStore("")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx, store)
+
+	if _, _, err := store.Update(func(state *app.State) { state.ShutdownRequested = true }); err != nil {
+		t.Fatalf("Error updating state: %v", err)
+	}
+	waitFor(t, "the shutdown file to be written", func() bool {
+		_, err := os.Stat(d.ShutdownFile)
+		return err == nil
+	})
+	waitFor(t, "the game to be signalled", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Equal(signalled, []int{40})
+	})
+
+	if _, _, err := store.Update(func(state *app.State) { state.ShutdownRequested = false }); err != nil {
+		t.Fatalf("Error updating state: %v", err)
+	}
+	waitFor(t, "the shutdown file to be removed", func() bool {
+		_, err := os.Stat(d.ShutdownFile)
+		return os.IsNotExist(err)
+	})
+}
+
+func TestAllowDeleteOnExit(t *testing.T) {
+	d := newTestDelivery(t)
+	d.AllowDeleteOnExit = true
+	d.Process = "game"
+	addProcess(t, d.ProcDir, 41, "sh", "/bin/sh")
+	store := app.NewStore("")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx, store)
+
+	time.Sleep(50 * time.Millisecond)
+	if state, _ := store.Get(); state.DeleteAllowed {
+		t.Fatalf("expected a game that has not started yet to not allow the deletion")
+	}
+	addProcess(t, d.ProcDir, 40, "game", "/bin/game")
+	time.Sleep(50 * time.Millisecond)
+	if err := os.RemoveAll(filepath.Join(d.ProcDir, "40")); err != nil {
+		t.Fatalf("Error removing process: %v", err)
+	}
+	waitFor(t, "the deletion to be allowed", func() bool {
+		state, _ := store.Get()
+		return state.DeleteAllowed
+	})
+}
+
+func TestAllowDeleteFile(t *testing.T) {
+	d := newTestDelivery(t)
+	d.AllowDeleteFile = filepath.Join(t.TempDir(), "done")
+	store := app.NewStore("")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx, store)
+
+	if err := os.WriteFile(d.AllowDeleteFile, nil, 0o644); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	waitFor(t, "the deletion to be allowed", func() bool {
+		state, _ := store.Get()
+		return state.DeleteAllowed
+	})
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/unfamousthomas/thesis-sidecar/internal/app"
 	"log"
 	"net/http"
+	"strconv"
 )
 
 type DeleteRequest struct {
@@ -14,8 +15,15 @@ type DeleteRequest struct {
 // IsDeleteAllowed is used by the operator to check if this can be deleted
 func IsDeleteAllowed(a *app.App) func(http.ResponseWriter, *http.Request) {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, version, err := waitForState(a, w, r)
+		if err != nil {
+			log.Printf("Error reading request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
-		err := json.NewEncoder(w).Encode(DeleteRequest{Allowed: a.DeleteAllowed})
+		w.Header().Set(VersionHeader, strconv.FormatUint(version, 10))
+		err = json.NewEncoder(w).Encode(DeleteRequest{Allowed: state.DeleteAllowed})
 		if err != nil {
 			log.Printf("Error encoding response: %v", err)
 			w.WriteHeader(http.StatusBadRequest)
@@ -35,8 +43,16 @@ func SetDeleteAllowed(a *app.App) func(http.ResponseWriter, *http.Request) {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		a.DeleteAllowed = request.Allowed
+		_, version, err := a.State.Update(func(state *app.State) {
+			state.DeleteAllowed = request.Allowed
+		})
+		if err != nil {
+			log.Printf("Error saving state: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(VersionHeader, strconv.FormatUint(version, 10))
 		err = json.NewEncoder(w).Encode(request)
 		if err != nil {
 			log.Printf("Error encoding response: %v", err)
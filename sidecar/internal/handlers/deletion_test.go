@@ -6,11 +6,12 @@ import (
 	"github.com/unfamousthomas/thesis-sidecar/internal/app"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 )
 
 func TestIsDeleteAllowed(t *testing.T) {
-	a := &app.App{DeleteAllowed: true}
+	a := newTestApp(t, "", app.State{DeleteAllowed: true})
 	req := httptest.NewRequest(http.MethodGet, "/allow_delete", nil)
 	rec := httptest.NewRecorder()
 
@@ -35,7 +36,7 @@ func TestIsDeleteAllowed(t *testing.T) {
 }
 
 func TestSetDeleteAllowed(t *testing.T) {
-	a := &app.App{DeleteAllowed: false}
+	a := newTestApp(t, "", app.State{DeleteAllowed: false})
 	requestBody, err := json.Marshal(DeleteRequest{Allowed: true})
 	if err != nil {
 		t.Fatalf("Error encoding request body: %v", err)
@@ -64,7 +65,7 @@ func TestSetDeleteAllowed(t *testing.T) {
 }
 
 func TestSetDeleteAllowedInvalid(t *testing.T) {
-	a := &app.App{DeleteAllowed: false}
+	a := newTestApp(t, "", app.State{DeleteAllowed: false})
 
 	invalidBody := bytes.NewBufferString("{invalid_json}")
 
@@ -80,7 +81,54 @@ func TestSetDeleteAllowedInvalid(t *testing.T) {
 		t.Fatalf("expected status 400 Bad Request Error, got %v", resp.StatusCode)
 	}
 
-	if a.DeleteAllowed != false {
-		t.Errorf("expected DeleteAllowed=false, got %v", a.DeleteAllowed)
+	if state, _ := a.State.Get(); state.DeleteAllowed != false {
+		t.Errorf("expected DeleteAllowed=false, got %v", state.DeleteAllowed)
+	}
+}
+
+func TestSetDeleteAllowedSavesState(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	a := newTestApp(t, stateFile, app.State{DeleteAllowed: false})
+	requestBody, err := json.Marshal(DeleteRequest{Allowed: true})
+	if err != nil {
+		t.Fatalf("Error encoding request body: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/allow_delete", bytes.NewReader(requestBody))
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(SetDeleteAllowed(a))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Result().StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d. Expected 200", rec.Result().StatusCode)
+	}
+
+	restored := app.NewStore(stateFile)
+	if err := restored.Restore(); err != nil {
+		t.Fatalf("Error restoring state: %v", err)
+	}
+	if state, _ := restored.Get(); state.DeleteAllowed != true {
+		t.Errorf("expected the saved DeleteAllowed=true, got %v", state.DeleteAllowed)
+	}
+}
+
+func TestSetDeleteAllowedSaveFailed(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "missing", "state.json")
+	a := newTestApp(t, stateFile, app.State{DeleteAllowed: false})
+	requestBody, err := json.Marshal(DeleteRequest{Allowed: true})
+	if err != nil {
+		t.Fatalf("Error encoding request body: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/allow_delete", bytes.NewReader(requestBody))
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(SetDeleteAllowed(a))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Result().StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected status 500 Internal Server Error, got %v", rec.Result().StatusCode)
+	}
+	if state, _ := a.State.Get(); state.DeleteAllowed != false {
+		t.Errorf("expected DeleteAllowed=false, got %v", state.DeleteAllowed)
 	}
 }
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type ReadyRequest struct {
+	Ready bool `json:"ready"`
+}
+
+type AllocatedRequest struct {
+	Allocated bool `json:"allocated"`
+}
+
+type PlayersRequest struct {
+	Players int64 `json:"players"`
+}
+
+type MetadataRequest struct {
+	Metadata map[string]string `json:"metadata"`
+}
+
+type CountersRequest struct {
+	Counters map[string]int64 `json:"counters"`
+}
+
+// IsReady is used to check if the gameserver is ready to accept players
+func IsReady(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return getState(a, func(state app.State) any {
+		return ReadyRequest{Ready: state.Ready}
+	})
+}
+
+// SetReady is used by the gameserver to tell it is ready to accept players
+func SetReady(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return setState(a, func(request ReadyRequest, state *app.State) {
+		state.Ready = request.Ready
+	})
+}
+
+// IsAllocated is used to check if the gameserver is in use
+func IsAllocated(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return getState(a, func(state app.State) any {
+		return AllocatedRequest{Allocated: state.Allocated}
+	})
+}
+
+// SetAllocated is used by the gameserver to tell it is in use, or free again
+func SetAllocated(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return setState(a, func(request AllocatedRequest, state *app.State) {
+		state.Allocated = request.Allocated
+	})
+}
+
+// GetPlayers is used to get the number of players reported by the gameserver
+func GetPlayers(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return getState(a, func(state app.State) any {
+		return PlayersRequest{Players: state.Players}
+	})
+}
+
+// SetPlayers is used by the gameserver to report its number of players
+func SetPlayers(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return setState(a, func(request PlayersRequest, state *app.State) {
+		state.Players = request.Players
+	})
+}
+
+// GetMetadata is used to get the metadata reported by the gameserver
+func GetMetadata(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return getState(a, func(state app.State) any {
+		return MetadataRequest{Metadata: state.Metadata}
+	})
+}
+
+// SetMetadata is used by the gameserver to report its metadata, merging the keys in the request into it
+func SetMetadata(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return setState(a, func(request MetadataRequest, state *app.State) {
+		state.MergeMetadata(request.Metadata)
+	})
+}
+
+// GetCounters is used to get the counters reported by the gameserver
+func GetCounters(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return getState(a, func(state app.State) any {
+		return CountersRequest{Counters: state.Counters}
+	})
+}
+
+// SetCounters is used by the gameserver to report its counters, setting the counters in the request
+func SetCounters(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return setState(a, func(request CountersRequest, state *app.State) {
+		state.MergeCounters(request.Counters)
+	})
+}
+
+// Heartbeat is used by the gameserver to tell it is still alive
+func Heartbeat(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.State.Heartbeat()
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// getState builds a GET handler returning part of the state, supporting the same long-poll as the other GET routes
+func getState(a *app.App, response func(app.State) any) func(http.ResponseWriter, *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, version, err := waitForState(a, w, r)
+		if err != nil {
+			log.Printf("Error reading request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(VersionHeader, strconv.FormatUint(version, 10))
+		err = json.NewEncoder(w).Encode(response(state))
+		if err != nil {
+			log.Printf("Error encoding response: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	})
+}
+
+// setState builds a POST handler applying the decoded request to the state, and echoing the request back
+func setState[T any](a *app.App, change func(T, *app.State)) func(http.ResponseWriter, *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request T
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			log.Printf("Error decoding request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, version, err := a.State.Update(func(state *app.State) {
+			change(request, state)
+		})
+		if err != nil {
+			log.Printf("Error saving state: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(VersionHeader, strconv.FormatUint(version, 10))
+		err = json.NewEncoder(w).Encode(request)
+		if err != nil {
+			log.Printf("Error encoding response: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetReady(t *testing.T) {
+	a := newTestApp(t, "", app.State{})
+	requestBody, err := json.Marshal(ReadyRequest{Ready: true})
+	if err != nil {
+		t.Fatalf("Error encoding request body: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/ready", bytes.NewReader(requestBody))
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(SetReady(a))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Result().StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d. Expected 200", rec.Result().StatusCode)
+	}
+	if state, _ := a.State.Get(); !state.Ready {
+		t.Errorf("expected Ready=true, got %v", state.Ready)
+	}
+}
+
+func TestSetAllocated(t *testing.T) {
+	a := newTestApp(t, "", app.State{})
+	requestBody, err := json.Marshal(AllocatedRequest{Allocated: true})
+	if err != nil {
+		t.Fatalf("Error encoding request body: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/allocated", bytes.NewReader(requestBody))
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(SetAllocated(a))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Result().StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d. Expected 200", rec.Result().StatusCode)
+	}
+	if state, _ := a.State.Get(); !state.Allocated {
+		t.Errorf("expected Allocated=true, got %v", state.Allocated)
+	}
+}
+
+func TestGetPlayers(t *testing.T) {
+	a := newTestApp(t, "", app.State{Players: 12})
+	req := httptest.NewRequest(http.MethodGet, "/players", nil)
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(GetPlayers(a))
+	handler.ServeHTTP(rec, req)
+
+	var response PlayersRequest
+	if err := json.NewDecoder(rec.Result().Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if response.Players != 12 {
+		t.Fatalf("expected 12 players, got %d", response.Players)
+	}
+}
+
+func TestSetMetadata(t *testing.T) {
+	a := newTestApp(t, "", app.State{Metadata: map[string]string{"map": "dust", "mode": "ranked"}})
+	requestBody, err := json.Marshal(MetadataRequest{Metadata: map[string]string{"map": "mirage", "mode": ""}})
+	if err != nil {
+		t.Fatalf("Error encoding request body: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/metadata", bytes.NewReader(requestBody))
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(SetMetadata(a))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Result().StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d. Expected 200", rec.Result().StatusCode)
+	}
+	state, _ := a.State.Get()
+	if len(state.Metadata) != 1 || state.Metadata["map"] != "mirage" {
+		t.Errorf("expected the metadata to be merged, got %v", state.Metadata)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	a := newTestApp(t, "", app.State{})
+	req := httptest.NewRequest(http.MethodPost, "/heartbeat", nil)
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Heartbeat(a))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Result().StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d. Expected 200", rec.Result().StatusCode)
+	}
+	if a.State.LastHeartbeat().IsZero() {
+		t.Errorf("expected the heartbeat to be recorded")
+	}
+}
+
+func TestSetCounters(t *testing.T) {
+	a := newTestApp(t, "", app.State{Counters: map[string]int64{"matches": 2, "rounds": 7}})
+	requestBody, err := json.Marshal(CountersRequest{Counters: map[string]int64{"matches": 3}})
+	if err != nil {
+		t.Fatalf("Error encoding request body: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/counters", bytes.NewReader(requestBody))
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(SetCounters(a))
+	handler.ServeHTTP(rec, req)
+
+	if rec.Result().StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d. Expected 200", rec.Result().StatusCode)
+	}
+	state, _ := a.State.Get()
+	if len(state.Counters) != 2 || state.Counters["matches"] != 3 || state.Counters["rounds"] != 7 {
+		t.Errorf("expected the counters to be merged, got %v", state.Counters)
+	}
+}
@@ -5,17 +5,25 @@ import (
 	"github.com/unfamousthomas/thesis-sidecar/internal/app"
 	"log"
 	"net/http"
+	"strconv"
 )
 
 type ShutdownRequest struct {
 	Shutdown bool `json:"shutdown"`
 }
 
-// IsShutdownRequested is used by the gameserver to check for shutdown requests
+// IsShutdownRequested is used by the gameserver to check for shutdown requests, optionally waiting for one to arrive
 func IsShutdownRequested(a *app.App) func(http.ResponseWriter, *http.Request) {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, version, err := waitForState(a, w, r)
+		if err != nil {
+			log.Printf("Error reading request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
-		err := json.NewEncoder(w).Encode(ShutdownRequest{Shutdown: a.ShutdownRequested})
+		w.Header().Set(VersionHeader, strconv.FormatUint(version, 10))
+		err = json.NewEncoder(w).Encode(ShutdownRequest{Shutdown: state.ShutdownRequested})
 		if err != nil {
 			log.Printf("Error encoding response: %v", err)
 			w.WriteHeader(http.StatusBadRequest)
@@ -35,8 +43,16 @@ func SetShutdownRequested(a *app.App) func(http.ResponseWriter, *http.Request) {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		a.ShutdownRequested = request.Shutdown
+		_, version, err := a.State.Update(func(state *app.State) {
+			state.ShutdownRequested = request.Shutdown
+		})
+		if err != nil {
+			log.Printf("Error saving state: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(VersionHeader, strconv.FormatUint(version, 10))
 		err = json.NewEncoder(w).Encode(request)
 		if err != nil {
 			log.Printf("Error encoding response: %v", err)
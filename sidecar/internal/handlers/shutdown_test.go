@@ -10,7 +10,7 @@ import (
 )
 
 func TestIsShutdownRequested(t *testing.T) {
-	a := &app.App{ShutdownRequested: true}
+	a := newTestApp(t, "", app.State{ShutdownRequested: true})
 	req := httptest.NewRequest(http.MethodGet, "/shutdown", nil)
 	rec := httptest.NewRecorder()
 
@@ -35,7 +35,7 @@ func TestIsShutdownRequested(t *testing.T) {
 }
 
 func TestSetShutdownRequested(t *testing.T) {
-	a := &app.App{ShutdownRequested: false}
+	a := newTestApp(t, "", app.State{ShutdownRequested: false})
 	requestBody, err := json.Marshal(ShutdownRequest{Shutdown: true})
 	if err != nil {
 		t.Fatalf("Error encoding request body: %v", err)
@@ -64,7 +64,7 @@ func TestSetShutdownRequested(t *testing.T) {
 }
 
 func TestSetShutdownRequestedInvalid(t *testing.T) {
-	a := &app.App{ShutdownRequested: false}
+	a := newTestApp(t, "", app.State{ShutdownRequested: false})
 
 	invalidBody := bytes.NewBufferString("{invalid_json}")
 
@@ -80,7 +80,7 @@ func TestSetShutdownRequestedInvalid(t *testing.T) {
 		t.Fatalf("expected status 400 Bad Request Error, got %v", resp.StatusCode)
 	}
 
-	if a.ShutdownRequested != false {
-		t.Errorf("expected ShutdownAllowed=false, got %v", a.ShutdownRequested)
+	if state, _ := a.State.Get(); state.ShutdownRequested != false {
+		t.Errorf("expected ShutdownAllowed=false, got %v", state.ShutdownRequested)
 	}
 }
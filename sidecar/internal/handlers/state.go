@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// VersionHeader is the header carrying the version of the returned state
+	VersionHeader = "X-State-Version"
+	// maxWait caps how long a long-poll request is held
+	maxWait = 5 * time.Minute
+	// keepAliveInterval is how often an idle event stream gets a comment, so proxies do not close it
+	keepAliveInterval = 15 * time.Second
+)
+
+type StateResponse struct {
+	app.State
+	Version uint64 `json:"version"`
+}
+
+// GetState is used by the gameserver to get the whole state of the sidecar, optionally waiting for it to change
+func GetState(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, version, err := waitForState(a, w, r)
+		if err != nil {
+			log.Printf("Error reading request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(VersionHeader, strconv.FormatUint(version, 10))
+		err = json.NewEncoder(w).Encode(StateResponse{State: state, Version: version})
+		if err != nil {
+			log.Printf("Error encoding response: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	})
+}
+
+// StateEvents is used by the gameserver to receive every change of the state as Server-Sent Events.
+// The current state is sent right away, unless the Last-Event-ID header says the client already has it.
+func StateEvents(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			log.Printf("Error streaming events: response does not support flushing")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		liftWriteDeadline(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		since, err := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+		if err != nil {
+			state, version := a.State.Get()
+			if err := writeStateEvent(w, state, version); err != nil {
+				log.Printf("Error writing event: %v", err)
+				return
+			}
+			flusher.Flush()
+			since = version
+		}
+		for {
+			ctx, cancel := context.WithTimeout(r.Context(), keepAliveInterval)
+			state, version := a.State.Wait(ctx, since)
+			cancel()
+			if r.Context().Err() != nil {
+				return
+			}
+			if version == since {
+				_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			} else {
+				err = writeStateEvent(w, state, version)
+				since = version
+			}
+			if err != nil {
+				log.Printf("Error writing event: %v", err)
+				return
+			}
+			flusher.Flush()
+		}
+	})
+}
+
+func writeStateEvent(w http.ResponseWriter, state app.State, version uint64) error {
+	data, err := json.Marshal(StateResponse{State: state, Version: version})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: state\ndata: %s\n\n", version, data)
+	return err
+}
+
+// waitForState returns the state for a GET request.
+// With the wait query parameter, the request is held until the version of the state differs from the since query parameter,
+// or the wait passes. Without since, the request waits for the next change.
+func waitForState(a *app.App, w http.ResponseWriter, r *http.Request) (app.State, uint64, error) {
+	state, version := a.State.Get()
+	query := r.URL.Query()
+	if !query.Has("wait") {
+		return state, version, nil
+	}
+	wait, err := time.ParseDuration(query.Get("wait"))
+	if err != nil {
+		return state, version, err
+	}
+	if wait < 0 {
+		return state, version, errors.New("wait cannot be negative")
+	}
+	since := version
+	if query.Has("since") {
+		since, err = strconv.ParseUint(query.Get("since"), 10, 64)
+		if err != nil {
+			return state, version, err
+		}
+	}
+	liftWriteDeadline(w)
+	ctx, cancel := context.WithTimeout(r.Context(), min(wait, maxWait))
+	defer cancel()
+	state, version = a.State.Wait(ctx, since)
+	return state, version, nil
+}
+
+// liftWriteDeadline removes the write timeout of the server from the response, as waiting for changes may take longer than it.
+// Long-polls are still capped by maxWait, and event streams end when the client disconnects or the sidecar shuts down.
+func liftWriteDeadline(w http.ResponseWriter) {
+	err := http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Error lifting write deadline: %v", err)
+	}
+}
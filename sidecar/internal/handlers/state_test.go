@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestApp(t *testing.T, stateFile string, state app.State) *app.App {
+	store := app.NewStore(stateFile)
+	if _, _, err := store.Update(func(s *app.State) { *s = state }); err != nil {
+		t.Fatalf("Error setting up state: %v", err)
+	}
+	return &app.App{Mux: http.NewServeMux(), State: store}
+}
+
+func TestGetState(t *testing.T) {
+	a := newTestApp(t, "", app.State{DeleteAllowed: true})
+	req := httptest.NewRequest(http.MethodGet, "/state", nil)
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(GetState(a))
+	handler.ServeHTTP(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d. Expected 200", resp.StatusCode)
+	}
+	if resp.Header.Get(VersionHeader) != "1" {
+		t.Fatalf("expected version header 1, got %q", resp.Header.Get(VersionHeader))
+	}
+
+	var response StateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if !response.DeleteAllowed || response.ShutdownRequested || response.Version != 1 {
+		t.Fatalf("unexpected state: %+v", response)
+	}
+}
+
+func TestLongPollShutdown(t *testing.T) {
+	a := newTestApp(t, "", app.State{})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		a.State.Update(func(state *app.State) { state.ShutdownRequested = true })
+	}()
+	req := httptest.NewRequest(http.MethodGet, "/shutdown?wait=5s&since=0", nil)
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(IsShutdownRequested(a))
+	handler.ServeHTTP(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d. Expected 200", resp.StatusCode)
+	}
+	var response ShutdownRequest
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if !response.Shutdown {
+		t.Fatalf("expected the shutdown to be returned once requested")
+	}
+	if resp.Header.Get(VersionHeader) != "1" {
+		t.Fatalf("expected version header 1, got %q", resp.Header.Get(VersionHeader))
+	}
+}
+
+func TestLongPollTimeout(t *testing.T) {
+	a := newTestApp(t, "", app.State{})
+	req := httptest.NewRequest(http.MethodGet, "/allow_delete?wait=10ms", nil)
+	rec := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(IsDeleteAllowed(a))
+	handler.ServeHTTP(rec, req)
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d. Expected 200", resp.StatusCode)
+	}
+	if resp.Header.Get(VersionHeader) != "0" {
+		t.Fatalf("expected the unchanged version 0, got %q", resp.Header.Get(VersionHeader))
+	}
+}
+
+func TestLongPollInvalid(t *testing.T) {
+	a := newTestApp(t, "", app.State{})
+	for _, query := range []string{"wait=soon", "wait=-1s", "wait=1s&since=latest"} {
+		req := httptest.NewRequest(http.MethodGet, "/state?"+query, nil)
+		rec := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(GetState(a))
+		handler.ServeHTTP(rec, req)
+
+		if rec.Result().StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected status 400 Bad Request Error for %q, got %v", query, rec.Result().StatusCode)
+		}
+	}
+}
+
+func TestStateEvents(t *testing.T) {
+	a := newTestApp(t, "", app.State{})
+	server := httptest.NewServer(http.HandlerFunc(StateEvents(a)))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Error connecting to events: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type: %q", resp.Header.Get("Content-Type"))
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() StateResponse {
+		var response StateResponse
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("Error reading event: %v", err)
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				if err := json.Unmarshal([]byte(data), &response); err != nil {
+					t.Fatalf("Error decoding event: %v", err)
+				}
+				return response
+			}
+		}
+	}
+
+	if event := readEvent(); event.ShutdownRequested || event.Version != 0 {
+		t.Fatalf("expected the current state first, got %+v", event)
+	}
+	a.State.Update(func(state *app.State) { state.ShutdownRequested = true })
+	if event := readEvent(); !event.ShutdownRequested || event.Version != 1 {
+		t.Fatalf("expected the shutdown event, got %+v", event)
+	}
+}
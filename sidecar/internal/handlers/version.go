@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"log"
+	"net/http"
+)
+
+// APIVersion is the version of the sidecar API, increased whenever routes are added or changed.
+// Version 0 is the original API with only the allow_delete and shutdown routes, version 2 added the counters,
+// version 3 serves the routes under the /v1 prefix, and version 4 added the allocated route.
+const APIVersion = 4
+
+type VersionResponse struct {
+	API int `json:"api"`
+}
+
+// Version is used by clients to negotiate the version of the API they use
+func Version(a *app.App) func(http.ResponseWriter, *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(VersionResponse{API: APIVersion})
+		if err != nil {
+			log.Printf("Error encoding response: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Operator stands in for the operator when the sidecar runs outside of a cluster.
+// It prints every change of the state, and performs the actions the operator would, such as requesting the shutdown or deleting the server.
+type Operator struct {
+	ctx   context.Context
+	state *app.Store
+	out   io.Writer
+
+	mu       sync.Mutex
+	deleting bool
+	// deleted is closed once the server is deleted, after which the local sidecar exits
+	deleted     chan struct{}
+	deletedOnce sync.Once
+}
+
+// NewOperator creates an Operator for the store, printing to out. Waiting for deletions stops when the context is done.
+func NewOperator(ctx context.Context, state *app.Store, out io.Writer) *Operator {
+	return &Operator{
+		ctx:     ctx,
+		state:   state,
+		out:     out,
+		deleted: make(chan struct{}),
+	}
+}
+
+// Watch prints the state, and then every change of it, until the context is done
+func (o *Operator) Watch(ctx context.Context) {
+	state, version := o.state.Get()
+	o.printf("state %d: %s", version, Describe(state))
+	for {
+		next, nextVersion := o.state.Wait(ctx, version)
+		if ctx.Err() != nil {
+			return
+		}
+		for _, change := range Diff(state, next) {
+			o.printf("state %d: %s", nextVersion, change)
+		}
+		state, version = next, nextVersion
+	}
+}
+
+// RequestShutdown sets or clears the shutdown request, as the operator does when the server is deleted
+func (o *Operator) RequestShutdown(shutdown bool) error {
+	_, _, err := o.state.Update(func(state *app.State) {
+		state.ShutdownRequested = shutdown
+	})
+	return err
+}
+
+// Delete deletes the server the way the operator does: the shutdown is requested,
+// and the server is deleted once the game server allows it
+func (o *Operator) Delete() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.deleting {
+		return nil
+	}
+	if err := o.RequestShutdown(true); err != nil {
+		return err
+	}
+	o.deleting = true
+	o.printf("operator: deleting the server, waiting for the game server to allow it")
+	go o.waitForDeleteAllowed()
+	return nil
+}
+
+// ForceDelete deletes the server without waiting for the game server, as with allowForceDelete or a passed timeout
+func (o *Operator) ForceDelete() {
+	o.markDeleted("forced")
+}
+
+// Deleted is closed once the server is deleted
+func (o *Operator) Deleted() <-chan struct{} {
+	return o.deleted
+}
+
+func (o *Operator) waitForDeleteAllowed() {
+	ctx, cancel := context.WithCancel(o.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-o.deleted:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	state, version := o.state.Get()
+	for !state.DeleteAllowed {
+		state, version = o.state.Wait(ctx, version)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+	o.markDeleted("allowed by the game server")
+}
+
+func (o *Operator) markDeleted(reason string) {
+	o.deletedOnce.Do(func() {
+		o.printf("operator: server deleted (%s)", reason)
+		close(o.deleted)
+	})
+}
+
+func (o *Operator) printf(format string, args ...any) {
+	fmt.Fprintf(o.out, time.Now().Format(time.TimeOnly)+" "+format+"\n", args...)
+}
+
+// Describe returns the whole state as a single line
+func Describe(state app.State) string {
+	return fmt.Sprintf("deleteAllowed=%t shutdownRequested=%t ready=%t allocated=%t players=%d metadata=%v counters=%v",
+		state.DeleteAllowed, state.ShutdownRequested, state.Ready, state.Allocated, state.Players, state.Metadata, state.Counters)
+}
+
+// Diff returns a line for every value that differs between the states, in a stable order
+func Diff(old app.State, next app.State) []string {
+	var changes []string
+	change := func(name string, from string, to string) {
+		if from != to {
+			changes = append(changes, name+": "+from+" -> "+to)
+		}
+	}
+	change("deleteAllowed", strconv.FormatBool(old.DeleteAllowed), strconv.FormatBool(next.DeleteAllowed))
+	change("shutdownRequested", strconv.FormatBool(old.ShutdownRequested), strconv.FormatBool(next.ShutdownRequested))
+	change("ready", strconv.FormatBool(old.Ready), strconv.FormatBool(next.Ready))
+	change("allocated", strconv.FormatBool(old.Allocated), strconv.FormatBool(next.Allocated))
+	change("players", strconv.FormatInt(old.Players, 10), strconv.FormatInt(next.Players, 10))
+	for _, key := range sortedKeys(old.Metadata, next.Metadata) {
+		change("metadata["+key+"]", strconv.Quote(old.Metadata[key]), strconv.Quote(next.Metadata[key]))
+	}
+	for _, key := range sortedKeys(old.Counters, next.Counters) {
+		change("counters["+key+"]", strconv.FormatInt(old.Counters[key], 10), strconv.FormatInt(next.Counters[key], 10))
+	}
+	return changes
+}
+
+func sortedKeys[V any](maps ...map[string]V) []string {
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,83 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := app.State{Players: 2, Metadata: map[string]string{"map": "dust"}}
+	next := app.State{ShutdownRequested: true, Players: 2, Metadata: map[string]string{"mode": "ranked"}, Counters: map[string]int64{"matches": 1}}
+
+	changes := Diff(old, next)
+	expected := []string{
+		"shutdownRequested: false -> true",
+		`metadata[map]: "dust" -> ""`,
+		`metadata[mode]: "" -> "ranked"`,
+		"counters[matches]: 0 -> 1",
+	}
+	if !slices.Equal(changes, expected) {
+		t.Fatalf("unexpected changes %q, expected %q", changes, expected)
+	}
+	if changes := Diff(next, next); len(changes) != 0 {
+		t.Fatalf("expected no changes for equal states, got %q", changes)
+	}
+}
+
+func TestDeleteWaitsForDeleteAllowed(t *testing.T) {
+	store := app.NewStore("")
+	var out bytes.Buffer
+	o := NewOperator(context.Background(), store, &out)
+	mux := http.NewServeMux()
+	o.SetupRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Prefix+"/delete", nil))
+	if rec.Result().StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status code: %d. Expected 204", rec.Result().StatusCode)
+	}
+	if state, _ := store.Get(); !state.ShutdownRequested {
+		t.Fatalf("expected the delete to request the shutdown")
+	}
+	select {
+	case <-o.Deleted():
+		t.Fatalf("expected the server to wait for the game server before being deleted")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, _, err := store.Update(func(state *app.State) { state.DeleteAllowed = true }); err != nil {
+		t.Fatalf("Error updating state: %v", err)
+	}
+	select {
+	case <-o.Deleted():
+	case <-time.After(time.Second):
+		t.Fatalf("expected the server to be deleted once the game server allows it")
+	}
+	if !strings.Contains(out.String(), "allowed by the game server") {
+		t.Fatalf("expected the deletion to be printed, got %q", out.String())
+	}
+}
+
+func TestForceDelete(t *testing.T) {
+	o := NewOperator(context.Background(), app.NewStore(""), &bytes.Buffer{})
+	mux := http.NewServeMux()
+	o.SetupRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Prefix+"/force_delete", nil))
+	if rec.Result().StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status code: %d. Expected 204", rec.Result().StatusCode)
+	}
+	select {
+	case <-o.Deleted():
+	default:
+		t.Fatalf("expected a forced delete to delete the server right away")
+	}
+}
@@ -0,0 +1,53 @@
+package local
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// Prefix is the prefix of the control routes, which are only served in local mode
+const Prefix = "/local"
+
+//go:embed page.html
+var page []byte
+
+// SetupRoutes adds the control page and the operator actions to the mux
+func (o *Operator) SetupRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+Prefix, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if _, err := w.Write(page); err != nil {
+			log.Printf("Error writing page: %v", err)
+		}
+	})
+	mux.HandleFunc("POST "+Prefix+"/shutdown", func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			Shutdown bool `json:"shutdown"`
+		}{Shutdown: true}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				log.Printf("Error decoding request: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		respond(w, o.RequestShutdown(request.Shutdown))
+	})
+	mux.HandleFunc("POST "+Prefix+"/delete", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, o.Delete())
+	})
+	mux.HandleFunc("POST "+Prefix+"/force_delete", func(w http.ResponseWriter, r *http.Request) {
+		o.ForceDelete()
+		respond(w, nil)
+	})
+}
+
+func respond(w http.ResponseWriter, err error) {
+	if err != nil {
+		log.Printf("Error saving state: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Metrics collects the metrics of the sidecar, exposed in the Prometheus text format
+type Metrics struct {
+	registry  *prometheus.Registry
+	requests  *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+}
+
+// LabelsFromEnv returns the server, fleet and game labels set on every metric, taken from the env vars injected by the operator.
+// Missing env vars result in empty labels.
+func LabelsFromEnv() prometheus.Labels {
+	return prometheus.Labels{
+		"server": os.Getenv("SERVER_NAME"),
+		"fleet":  os.Getenv("FLEET_NAME"),
+		"game":   os.Getenv("GAME_NAME"),
+	}
+}
+
+// New creates the metrics of the sidecar, reading the state metrics from the store on every scrape
+func New(store *app.Store, labels prometheus.Labels) *Metrics {
+	registry := prometheus.NewRegistry()
+	registerer := prometheus.WrapRegistererWith(labels, registry)
+	m := &Metrics{
+		registry: registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sidecar_http_requests_total",
+			Help: "Number of HTTP requests handled by the sidecar, by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "sidecar_http_request_duration_seconds",
+			Help:    "Latency of the HTTP requests handled by the sidecar, by route and method. Long-polls and event streams are included.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+	}
+	registerer.MustRegister(m.requests, m.durations, &stateCollector{store: store})
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return m
+}
+
+// Handler serves the metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Instrument counts the requests of the handler and measures their latency, labelled with the route
+func (m *Metrics) Instrument(route string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(recorder, r)
+		m.requests.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Inc()
+		m.durations.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// statusRecorder remembers the status code written to the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the underlying response
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Flush keeps the event stream working through the recorder
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+var (
+	deleteAllowedDesc = prometheus.NewDesc("sidecar_delete_allowed",
+		"Whether the game server allows its deletion.", nil, nil)
+	shutdownRequestedDesc = prometheus.NewDesc("sidecar_shutdown_requested",
+		"Whether the operator has requested the shutdown of the game server.", nil, nil)
+	shutdownAgeDesc = prometheus.NewDesc("sidecar_shutdown_requested_seconds",
+		"Seconds since the shutdown was requested. Missing while no shutdown is requested.", nil, nil)
+	readyDesc = prometheus.NewDesc("sidecar_ready",
+		"Whether the game server is ready to accept players.", nil, nil)
+	allocatedDesc = prometheus.NewDesc("sidecar_allocated",
+		"Whether the game server is in use.", nil, nil)
+	heartbeatAgeDesc = prometheus.NewDesc("sidecar_heartbeat_age_seconds",
+		"Seconds since the last heartbeat of the game server. Missing until the first heartbeat.", nil, nil)
+	playersDesc = prometheus.NewDesc("sidecar_players",
+		"Number of players reported by the game server.", nil, nil)
+	counterDesc = prometheus.NewDesc("sidecar_counter",
+		"Custom counters reported by the game server.", []string{"counter"}, nil)
+	versionDesc = prometheus.NewDesc("sidecar_state_version",
+		"Version of the state, increasing on every change.", nil, nil)
+)
+
+// stateCollector reads the state metrics from the store on every scrape
+type stateCollector struct {
+	store *app.Store
+}
+
+func (c *stateCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- deleteAllowedDesc
+	descs <- shutdownRequestedDesc
+	descs <- shutdownAgeDesc
+	descs <- readyDesc
+	descs <- allocatedDesc
+	descs <- heartbeatAgeDesc
+	descs <- playersDesc
+	descs <- counterDesc
+	descs <- versionDesc
+}
+
+func (c *stateCollector) Collect(metrics chan<- prometheus.Metric) {
+	state, version := c.store.Get()
+	metrics <- prometheus.MustNewConstMetric(deleteAllowedDesc, prometheus.GaugeValue, boolValue(state.DeleteAllowed))
+	metrics <- prometheus.MustNewConstMetric(shutdownRequestedDesc, prometheus.GaugeValue, boolValue(state.ShutdownRequested))
+	if requestedAt := c.store.ShutdownRequestedAt(); !requestedAt.IsZero() {
+		metrics <- prometheus.MustNewConstMetric(shutdownAgeDesc, prometheus.GaugeValue, time.Since(requestedAt).Seconds())
+	}
+	metrics <- prometheus.MustNewConstMetric(readyDesc, prometheus.GaugeValue, boolValue(state.Ready))
+	metrics <- prometheus.MustNewConstMetric(allocatedDesc, prometheus.GaugeValue, boolValue(state.Allocated))
+	if heartbeat := c.store.LastHeartbeat(); !heartbeat.IsZero() {
+		metrics <- prometheus.MustNewConstMetric(heartbeatAgeDesc, prometheus.GaugeValue, time.Since(heartbeat).Seconds())
+	}
+	metrics <- prometheus.MustNewConstMetric(playersDesc, prometheus.GaugeValue, float64(state.Players))
+	for name, value := range state.Counters {
+		metrics <- prometheus.MustNewConstMetric(counterDesc, prometheus.GaugeValue, float64(value), name)
+	}
+	metrics <- prometheus.MustNewConstMetric(versionDesc, prometheus.GaugeValue, float64(version))
+}
+
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testLabels = prometheus.Labels{"server": "server-1", "fleet": "fleet-1", "game": "game-1"}
+
+func scrape(t *testing.T, m *Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Result().StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d. Expected 200", rec.Result().StatusCode)
+	}
+	body, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatalf("Error reading metrics: %v", err)
+	}
+	return string(body)
+}
+
+func expectMetric(t *testing.T, metrics string, metric string) {
+	t.Helper()
+	if !strings.Contains(metrics, metric+"\n") {
+		t.Errorf("expected %s in the metrics:\n%s", metric, metrics)
+	}
+}
+
+func TestStateMetrics(t *testing.T) {
+	store := app.NewStore("")
+	store.Update(func(state *app.State) {
+		state.DeleteAllowed = true
+		state.Players = 12
+		state.Counters = map[string]int64{"matches": 3}
+	})
+	m := New(store, testLabels)
+
+	metrics := scrape(t, m)
+	expectMetric(t, metrics, `sidecar_delete_allowed{fleet="fleet-1",game="game-1",server="server-1"} 1`)
+	expectMetric(t, metrics, `sidecar_shutdown_requested{fleet="fleet-1",game="game-1",server="server-1"} 0`)
+	expectMetric(t, metrics, `sidecar_players{fleet="fleet-1",game="game-1",server="server-1"} 12`)
+	expectMetric(t, metrics, `sidecar_counter{counter="matches",fleet="fleet-1",game="game-1",server="server-1"} 3`)
+	if strings.Contains(metrics, "sidecar_shutdown_requested_seconds{") || strings.Contains(metrics, "sidecar_heartbeat_age_seconds{") {
+		t.Errorf("expected no shutdown or heartbeat age before either happened:\n%s", metrics)
+	}
+
+	store.Update(func(state *app.State) { state.ShutdownRequested = true })
+	store.Heartbeat()
+	metrics = scrape(t, m)
+	expectMetric(t, metrics, `sidecar_shutdown_requested{fleet="fleet-1",game="game-1",server="server-1"} 1`)
+	if !strings.Contains(metrics, "sidecar_shutdown_requested_seconds{") || !strings.Contains(metrics, "sidecar_heartbeat_age_seconds{") {
+		t.Errorf("expected the shutdown and heartbeat age once they happened:\n%s", metrics)
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	m := New(app.NewStore(""), testLabels)
+	handler := m.Instrument("/allow_delete", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/allow_delete", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/allow_delete", nil))
+
+	metrics := scrape(t, m)
+	expectMetric(t, metrics, `sidecar_http_requests_total{code="400",fleet="fleet-1",game="game-1",method="POST",route="/allow_delete",server="server-1"} 2`)
+	expectMetric(t, metrics, `sidecar_http_request_duration_seconds_count{fleet="fleet-1",game="game-1",method="POST",route="/allow_delete",server="server-1"} 2`)
+}
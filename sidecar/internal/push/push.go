@@ -0,0 +1,152 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	minBackoff        = time.Second
+	maxBackoff        = 30 * time.Second
+)
+
+// Pusher patches the state of the sidecar into the status of its Server, so the operator does not have to poll it
+type Pusher struct {
+	// BaseURL is the address of the Kubernetes API server
+	BaseURL string
+	// TokenFile is read on every push, as the token of the ServiceAccount is rotated
+	TokenFile  string
+	HTTPClient *http.Client
+	Namespace  string
+	Name       string
+}
+
+// NewInClusterPusher creates a Pusher for the Server, using the ServiceAccount of the pod
+func NewInClusterPusher(namespace string, name string) (*Pusher, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a cluster")
+	}
+	if namespace == "" || name == "" {
+		return nil, errors.New("the namespace and name of the server are required")
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no certificates found in the service account")
+	}
+	return &Pusher{
+		BaseURL:   "https://" + net.JoinHostPort(host, port),
+		TokenFile: serviceAccountDir + "/token",
+		HTTPClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+		Namespace: namespace,
+		Name:      name,
+	}, nil
+}
+
+type statusPatch struct {
+	Status serverStatus `json:"status"`
+}
+
+type serverStatus struct {
+	Players  int64            `json:"players"`
+	Counters map[string]int64 `json:"counters"`
+	Sidecar  sidecarStatus    `json:"sidecar"`
+}
+
+type sidecarStatus struct {
+	DeleteAllowed     bool      `json:"deleteAllowed"`
+	ShutdownRequested bool      `json:"shutdownRequested"`
+	Ready             bool      `json:"ready"`
+	Allocated         bool      `json:"allocated"`
+	Version           uint64    `json:"version"`
+	UpdateTime        time.Time `json:"updateTime"`
+}
+
+// Push patches the state into the status of the Server
+func (p *Pusher) Push(ctx context.Context, state app.State, version uint64) error {
+	body, err := json.Marshal(statusPatch{Status: serverStatus{
+		Players:  state.Players,
+		Counters: state.Counters,
+		Sidecar: sidecarStatus{
+			DeleteAllowed:     state.DeleteAllowed,
+			ShutdownRequested: state.ShutdownRequested,
+			Ready:             state.Ready,
+			Allocated:         state.Allocated,
+			Version:           version,
+			UpdateTime:        time.Now().UTC().Truncate(time.Second),
+		},
+	}})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/apis/network.unfamousthomas.me/v1alpha1/namespaces/%s/servers/%s/status", p.BaseURL, p.Namespace, p.Name)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/merge-patch+json")
+	if p.TokenFile != "" {
+		token, err := os.ReadFile(p.TokenFile)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, err := p.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("PATCH request returned: " + resp.Status)
+	}
+	return nil
+}
+
+// Run pushes the current state, and then every change of it, until the context is done.
+// Failed pushes are retried with a backoff, always pushing the latest state.
+func (p *Pusher) Run(ctx context.Context, store *app.Store) {
+	backoff := minBackoff
+	state, version := store.Get()
+	for {
+		if err := p.Push(ctx, state, version); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Error pushing state: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			state, version = store.Get()
+			continue
+		}
+		backoff = minBackoff
+		state, version = store.Wait(ctx, version)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type receivedPatch struct {
+	path          string
+	contentType   string
+	authorization string
+	patch         statusPatch
+}
+
+func newTestPusher(t *testing.T, status int) (*Pusher, chan receivedPatch) {
+	patches := make(chan receivedPatch, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received := receivedPatch{
+			path:          r.URL.Path,
+			contentType:   r.Header.Get("Content-Type"),
+			authorization: r.Header.Get("Authorization"),
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received.patch); err != nil {
+			t.Errorf("Error decoding patch: %v", err)
+		}
+		patches <- received
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("secret\n"), 0o600); err != nil {
+		t.Fatalf("Error writing token: %v", err)
+	}
+	return &Pusher{
+		BaseURL:    server.URL,
+		TokenFile:  tokenFile,
+		HTTPClient: server.Client(),
+		Namespace:  "games",
+		Name:       "server-1",
+	}, patches
+}
+
+func TestPush(t *testing.T) {
+	pusher, patches := newTestPusher(t, http.StatusOK)
+	err := pusher.Push(context.Background(), app.State{DeleteAllowed: true, Allocated: true, Players: 5}, 3)
+	if err != nil {
+		t.Fatalf("Error pushing state: %v", err)
+	}
+
+	received := <-patches
+	if received.path != "/apis/network.unfamousthomas.me/v1alpha1/namespaces/games/servers/server-1/status" {
+		t.Fatalf("unexpected path: %s", received.path)
+	}
+	if received.contentType != "application/merge-patch+json" {
+		t.Fatalf("unexpected content type: %s", received.contentType)
+	}
+	if received.authorization != "Bearer secret" {
+		t.Fatalf("unexpected authorization: %s", received.authorization)
+	}
+	status := received.patch.Status
+	if status.Players != 5 || !status.Sidecar.DeleteAllowed || !status.Sidecar.Allocated || status.Sidecar.Version != 3 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestPushFailed(t *testing.T) {
+	pusher, _ := newTestPusher(t, http.StatusForbidden)
+	if err := pusher.Push(context.Background(), app.State{}, 0); err == nil {
+		t.Fatalf("expected an error when the patch is forbidden")
+	}
+}
+
+func TestRun(t *testing.T) {
+	pusher, patches := newTestPusher(t, http.StatusOK)
+	store := app.NewStore("")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		pusher.Run(ctx, store)
+		close(done)
+	}()
+
+	first := <-patches
+	if first.patch.Status.Sidecar.Version != 0 {
+		t.Fatalf("expected the current state to be pushed first, got %+v", first.patch.Status)
+	}
+	store.Update(func(state *app.State) { state.ShutdownRequested = true })
+	select {
+	case next := <-patches:
+		if !next.patch.Status.Sidecar.ShutdownRequested || next.patch.Status.Sidecar.Version != 1 {
+			t.Fatalf("expected the change to be pushed, got %+v", next.patch.Status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the change to be pushed")
+	}
+
+	cancel()
+	<-done
+}
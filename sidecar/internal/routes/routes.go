@@ -3,20 +3,45 @@ package routes
 import (
 	"github.com/unfamousthomas/thesis-sidecar/internal/app"
 	"github.com/unfamousthomas/thesis-sidecar/internal/handlers"
-	"log"
+	"github.com/unfamousthomas/thesis-sidecar/internal/metrics"
 	"net/http"
+	"strings"
 )
 
-// SetupRoutes sets up the nessecary routes, their handlers and starts serving http.
-func SetupRoutes(a *app.App) {
+// VersionPrefix is the prefix the API is served under. The unversioned paths are kept as aliases for older clients.
+const VersionPrefix = "/v1"
 
-	a.Mux.HandleFunc("GET /allow_delete", handlers.IsDeleteAllowed(a))
-	a.Mux.HandleFunc("POST /allow_delete", handlers.SetDeleteAllowed(a))
-	a.Mux.HandleFunc("GET /shutdown", handlers.IsShutdownRequested(a))
-	a.Mux.HandleFunc("POST /shutdown", handlers.SetShutdownRequested(a))
-	a.Mux.HandleFunc("/health", handlers.Health(a))
-	err := http.ListenAndServe(":8080", a.Mux)
-	if err != nil {
-		log.Fatalf("Error starting server: %v", err)
+// SetupRoutes sets up the nessecary routes and their handlers.
+func SetupRoutes(a *app.App) {
+	m := metrics.New(a.State, metrics.LabelsFromEnv())
+	// handle registers the handler under the versioned and the unversioned path,
+	// counting its requests and measuring their latency per route
+	handle := func(pattern string, handler http.HandlerFunc) {
+		method, route, _ := strings.Cut(pattern, " ")
+		for _, path := range []string{VersionPrefix + route, route} {
+			a.Mux.Handle(method+" "+path, m.Instrument(path, handler))
+		}
 	}
+
+	handle("GET /allow_delete", handlers.IsDeleteAllowed(a))
+	handle("POST /allow_delete", handlers.SetDeleteAllowed(a))
+	handle("GET /shutdown", handlers.IsShutdownRequested(a))
+	handle("POST /shutdown", handlers.SetShutdownRequested(a))
+	handle("GET /ready", handlers.IsReady(a))
+	handle("POST /ready", handlers.SetReady(a))
+	handle("GET /allocated", handlers.IsAllocated(a))
+	handle("POST /allocated", handlers.SetAllocated(a))
+	handle("GET /players", handlers.GetPlayers(a))
+	handle("POST /players", handlers.SetPlayers(a))
+	handle("GET /metadata", handlers.GetMetadata(a))
+	handle("POST /metadata", handlers.SetMetadata(a))
+	handle("GET /counters", handlers.GetCounters(a))
+	handle("POST /counters", handlers.SetCounters(a))
+	handle("POST /heartbeat", handlers.Heartbeat(a))
+	handle("GET /version", handlers.Version(a))
+	handle("GET /state", handlers.GetState(a))
+	handle("GET /events", handlers.StateEvents(a))
+	// The health and metrics routes are for the kubelet and Prometheus, and stay outside of the versioned API
+	a.Mux.Handle("/health", m.Instrument("/health", http.HandlerFunc(handlers.Health(a))))
+	a.Mux.Handle("GET /metrics", m.Handler())
 }
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVersionedRoutes(t *testing.T) {
+	a := &app.App{Mux: http.NewServeMux(), State: app.NewStore("")}
+	SetupRoutes(a)
+
+	for _, path := range []string{"/v1/allow_delete", "/allow_delete"} {
+		rec := httptest.NewRecorder()
+		a.Mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"allowed": true}`)))
+		if rec.Result().StatusCode != http.StatusOK {
+			t.Fatalf("unexpected status code for %s: %d. Expected 200", path, rec.Result().StatusCode)
+		}
+	}
+	if state, version := a.State.Get(); !state.DeleteAllowed || version != 1 {
+		t.Fatalf("expected both paths to change the same state, got %+v with version %d", state, version)
+	}
+
+	for _, path := range []string{"/health", "/metrics"} {
+		rec := httptest.NewRecorder()
+		a.Mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Result().StatusCode != http.StatusOK {
+			t.Fatalf("unexpected status code for %s: %d. Expected 200", path, rec.Result().StatusCode)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package rpc
+
+import (
+	"context"
+	sidecarv1 "github.com/unfamousthomas/thesis-sidecar/api/proto/sidecar/v1"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"github.com/unfamousthomas/thesis-sidecar/internal/handlers"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthv1 "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"log"
+)
+
+// Server serves the gRPC version of the sidecar API, backed by the same state as the REST routes
+type Server struct {
+	sidecarv1.UnimplementedSidecarServer
+	app *app.App
+}
+
+// NewServer creates the gRPC server, with the sidecar API and the standard health service registered.
+// Open streams are ended once ctx is done, so a graceful stop does not wait for watching clients.
+func NewServer(ctx context.Context, a *app.App) *grpc.Server {
+	server := grpc.NewServer(grpc.StreamInterceptor(endStreams(ctx)))
+	sidecarv1.RegisterSidecarServer(server, &Server{app: a})
+	healthv1.RegisterHealthServer(server, health.NewServer())
+	return server
+}
+
+// endStreams cancels the context of every stream once ctx is done
+func endStreams(ctx context.Context) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		streamCtx, cancel := context.WithCancel(stream.Context())
+		defer cancel()
+		stop := context.AfterFunc(ctx, cancel)
+		defer stop()
+		return handler(srv, &serverStream{ServerStream: stream, ctx: streamCtx})
+	}
+}
+
+// serverStream replaces the context of a stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Server) GetState(ctx context.Context, request *sidecarv1.GetStateRequest) (*sidecarv1.State, error) {
+	state, version := s.app.State.Get()
+	return toProto(state, version), nil
+}
+
+func (s *Server) SetDeleteAllowed(ctx context.Context, request *sidecarv1.SetDeleteAllowedRequest) (*sidecarv1.State, error) {
+	return s.update(func(state *app.State) {
+		state.DeleteAllowed = request.Allowed
+	})
+}
+
+func (s *Server) SetShutdown(ctx context.Context, request *sidecarv1.SetShutdownRequest) (*sidecarv1.State, error) {
+	return s.update(func(state *app.State) {
+		state.ShutdownRequested = request.Shutdown
+	})
+}
+
+func (s *Server) SetReady(ctx context.Context, request *sidecarv1.SetReadyRequest) (*sidecarv1.State, error) {
+	return s.update(func(state *app.State) {
+		state.Ready = request.Ready
+	})
+}
+
+func (s *Server) SetAllocated(ctx context.Context, request *sidecarv1.SetAllocatedRequest) (*sidecarv1.State, error) {
+	return s.update(func(state *app.State) {
+		state.Allocated = request.Allocated
+	})
+}
+
+func (s *Server) SetPlayers(ctx context.Context, request *sidecarv1.SetPlayersRequest) (*sidecarv1.State, error) {
+	return s.update(func(state *app.State) {
+		state.Players = request.Players
+	})
+}
+
+func (s *Server) SetMetadata(ctx context.Context, request *sidecarv1.SetMetadataRequest) (*sidecarv1.State, error) {
+	return s.update(func(state *app.State) {
+		state.MergeMetadata(request.Metadata)
+	})
+}
+
+func (s *Server) SetCounters(ctx context.Context, request *sidecarv1.SetCountersRequest) (*sidecarv1.State, error) {
+	return s.update(func(state *app.State) {
+		state.MergeCounters(request.Counters)
+	})
+}
+
+func (s *Server) Heartbeat(ctx context.Context, request *sidecarv1.HeartbeatRequest) (*sidecarv1.HeartbeatResponse, error) {
+	s.app.State.Heartbeat()
+	return &sidecarv1.HeartbeatResponse{}, nil
+}
+
+func (s *Server) GetVersion(ctx context.Context, request *sidecarv1.GetVersionRequest) (*sidecarv1.GetVersionResponse, error) {
+	return &sidecarv1.GetVersionResponse{Api: handlers.APIVersion}, nil
+}
+
+func (s *Server) WatchState(request *sidecarv1.WatchStateRequest, stream sidecarv1.Sidecar_WatchStateServer) error {
+	state, since := s.app.State.Get()
+	if request.Since == nil || *request.Since != since {
+		if err := stream.Send(toProto(state, since)); err != nil {
+			return err
+		}
+	}
+	for {
+		state, version := s.app.State.Wait(stream.Context(), since)
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+		if err := stream.Send(toProto(state, version)); err != nil {
+			return err
+		}
+		since = version
+	}
+}
+
+func (s *Server) WatchShutdown(request *sidecarv1.WatchShutdownRequest, stream sidecarv1.Sidecar_WatchShutdownServer) error {
+	state, since := s.app.State.Get()
+	requested := state.ShutdownRequested
+	if err := stream.Send(&sidecarv1.ShutdownState{ShutdownRequested: requested, Version: since}); err != nil {
+		return err
+	}
+	for {
+		state, version := s.app.State.Wait(stream.Context(), since)
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+		since = version
+		if state.ShutdownRequested == requested {
+			continue
+		}
+		requested = state.ShutdownRequested
+		if err := stream.Send(&sidecarv1.ShutdownState{ShutdownRequested: requested, Version: version}); err != nil {
+			return err
+		}
+	}
+}
+
+// update applies the change to the state, and returns the new state
+func (s *Server) update(change func(*app.State)) (*sidecarv1.State, error) {
+	state, version, err := s.app.State.Update(change)
+	if err != nil {
+		log.Printf("Error saving state: %v", err)
+		return nil, status.Errorf(codes.Internal, "saving the state failed: %v", err)
+	}
+	return toProto(state, version), nil
+}
+
+func toProto(state app.State, version uint64) *sidecarv1.State {
+	return &sidecarv1.State{
+		DeleteAllowed:     state.DeleteAllowed,
+		ShutdownRequested: state.ShutdownRequested,
+		Ready:             state.Ready,
+		Allocated:         state.Allocated,
+		Players:           state.Players,
+		Metadata:          state.Metadata,
+		Counters:          state.Counters,
+		Version:           version,
+	}
+}
@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"context"
+	sidecarv1 "github.com/unfamousthomas/thesis-sidecar/api/proto/sidecar/v1"
+	"github.com/unfamousthomas/thesis-sidecar/internal/app"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, streams context.Context) (sidecarv1.SidecarClient, *app.App) {
+	a := &app.App{Mux: http.NewServeMux(), State: app.NewStore("")}
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(streams, a)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Error connecting to the server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return sidecarv1.NewSidecarClient(conn), a
+}
+
+func TestSetState(t *testing.T) {
+	client, a := newTestClient(t, context.Background())
+	ctx := context.Background()
+
+	if _, err := client.SetReady(ctx, &sidecarv1.SetReadyRequest{Ready: true}); err != nil {
+		t.Fatalf("Error setting ready: %v", err)
+	}
+	if _, err := client.SetAllocated(ctx, &sidecarv1.SetAllocatedRequest{Allocated: true}); err != nil {
+		t.Fatalf("Error setting allocated: %v", err)
+	}
+	if _, err := client.SetPlayers(ctx, &sidecarv1.SetPlayersRequest{Players: 4}); err != nil {
+		t.Fatalf("Error setting players: %v", err)
+	}
+	state, err := client.SetMetadata(ctx, &sidecarv1.SetMetadataRequest{Metadata: map[string]string{"map": "dust"}})
+	if err != nil {
+		t.Fatalf("Error setting metadata: %v", err)
+	}
+	if !state.Ready || !state.Allocated || state.Players != 4 || state.Metadata["map"] != "dust" || state.Version != 4 {
+		t.Fatalf("unexpected state: %v", state)
+	}
+
+	stored, version := a.State.Get()
+	if !stored.Ready || !stored.Allocated || stored.Players != 4 || version != 4 {
+		t.Fatalf("expected the state to be shared with the REST routes, got %+v with version %d", stored, version)
+	}
+}
+
+func TestWatchShutdown(t *testing.T) {
+	client, a := newTestClient(t, context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchShutdown(ctx, &sidecarv1.WatchShutdownRequest{})
+	if err != nil {
+		t.Fatalf("Error watching shutdown: %v", err)
+	}
+	first, err := stream.Recv()
+	if err != nil || first.ShutdownRequested {
+		t.Fatalf("expected no shutdown first, got %v (%v)", first, err)
+	}
+
+	a.State.Update(func(state *app.State) { state.Players = 3 })
+	a.State.Update(func(state *app.State) { state.ShutdownRequested = true })
+	next, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Error receiving shutdown: %v", err)
+	}
+	if !next.ShutdownRequested || next.Version != 2 {
+		t.Fatalf("expected only the shutdown to be streamed, got %v", next)
+	}
+}
+
+func TestWatchState(t *testing.T) {
+	client, a := newTestClient(t, context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	a.State.Update(func(state *app.State) { state.DeleteAllowed = true })
+
+	since := uint64(1)
+	stream, err := client.WatchState(ctx, &sidecarv1.WatchStateRequest{Since: &since})
+	if err != nil {
+		t.Fatalf("Error watching state: %v", err)
+	}
+	a.State.Update(func(state *app.State) { state.Players = 8 })
+	state, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Error receiving state: %v", err)
+	}
+	if state.Players != 8 || state.Version != 2 {
+		t.Fatalf("expected the resumed stream to skip the known state, got %v", state)
+	}
+}
+
+func TestStreamsEndOnShutdown(t *testing.T) {
+	streams, endStreams := context.WithCancel(context.Background())
+	client, _ := newTestClient(t, streams)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchShutdown(ctx, &sidecarv1.WatchShutdownRequest{})
+	if err != nil {
+		t.Fatalf("Error watching shutdown: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Error receiving the current state: %v", err)
+	}
+	endStreams()
+	if _, err := stream.Recv(); err == nil || ctx.Err() != nil {
+		t.Fatalf("expected the stream to be ended by the server, got %v", err)
+	}
+}